@@ -11,6 +11,7 @@ import (
 
 	"github.com/iflowkit/iflowkit-cli/internal/common/errorx"
 	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/output"
 	"github.com/iflowkit/iflowkit-cli/internal/paths"
 	"github.com/iflowkit/iflowkit-cli/internal/store"
 )
@@ -19,6 +20,7 @@ type GlobalFlags struct {
 	ProfileID string
 	LogLevel  string
 	LogFormat string
+	Output    output.Format
 }
 
 type Context struct {
@@ -30,6 +32,23 @@ type Context struct {
 	Logger *logging.Logger
 	Stores *store.Stores
 	Flags  GlobalFlags
+
+	// results receives rendered results. With --output json|yaml it is the real stdout while
+	// Stdout (logs, progress text, prompts) is redirected to stderr.
+	results  io.Writer
+	rendered bool
+}
+
+// Render writes the typed result of a command in the --output format.
+func (c *Context) Render(r output.Result) error {
+	w := c.results
+	if w == nil {
+		w = c.Stdout
+	}
+	if c.Flags.Output.Structured() {
+		c.rendered = true
+	}
+	return output.Write(w, c.Flags.Output, r)
 }
 
 func Run(argv []string) error {
@@ -40,12 +59,14 @@ func Run(argv []string) error {
 		LogLevel:  "info",
 		LogFormat: "text",
 	}
+	var outputFlag string
 
 	fs := flag.NewFlagSet("iflowkit", flag.ContinueOnError)
 	fs.SetOutput(io.Discard) // we control all output
 	fs.StringVar(&flags.ProfileID, "profile", "", "Profile id to use for the command (overrides active profile)")
 	fs.StringVar(&flags.LogLevel, "log-level", "info", "Log level: trace|debug|info|warn|error")
 	fs.StringVar(&flags.LogFormat, "log-format", "text", "Log format: text|json")
+	fs.StringVar(&outputFlag, "output", "table", "Result format: table|json|yaml")
 
 	if err := fs.Parse(argv); err != nil {
 		fmt.Fprintln(ctx.Stderr, err.Error())
@@ -53,7 +74,18 @@ func Run(argv []string) error {
 		return err
 	}
 	args := fs.Args()
+	format, err := output.ParseFormat(outputFlag)
+	if err != nil {
+		fmt.Fprintln(ctx.Stderr, err.Error())
+		return err
+	}
+	flags.Output = format
 	ctx.Flags = flags
+	ctx.results = ctx.Stdout
+	if format.Structured() {
+		// Keep stdout for the single result document.
+		ctx.Stdout = ctx.Stderr
+	}
 
 	p, err := paths.New()
 	if err != nil {
@@ -96,9 +128,15 @@ func Run(argv []string) error {
 		// Do not spam usage for all errors; only known cases.
 		fmt.Fprintln(ctx.Stderr, errorx.UserError(err))
 		ctx.Logger.Error("command failed", logging.F("error", err.Error()))
+		if format.Structured() && !ctx.rendered {
+			_ = ctx.Render(output.CommandResult{Command: strings.Join(cmdPath, " "), Status: "error", Error: errorx.UserError(err)})
+		}
 		return err
 	}
 	ctx.Logger.Info("command finished", logging.F("cmd", strings.Join(cmdPath, " ")))
+	if format.Structured() && !ctx.rendered {
+		return ctx.Render(output.CommandResult{Command: strings.Join(cmdPath, " "), Status: "ok"})
+	}
 
 	return nil
 }
@@ -163,7 +201,7 @@ func printRootHelp(ctx *Context) {
 	fmt.Fprintln(out, "iFlowKit CLI")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Usage:")
-	fmt.Fprintln(out, "  iflowkit [--profile <profileId>] [--log-level <trace|debug|info|warn|error>] [--log-format <text|json>] <command> [args]")
+	fmt.Fprintln(out, "  iflowkit [--profile <profileId>] [--log-level <trace|debug|info|warn|error>] [--log-format <text|json>] [--output <table|json|yaml>] <command> [args]")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Commands:")
 	fmt.Fprintln(out, "  help        Show help")
@@ -175,6 +213,10 @@ func printRootHelp(ctx *Context) {
 		fmt.Fprintf(out, "  %-10s %s\n", name, "Product module")
 	}
 	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Output:")
+	fmt.Fprintln(out, "  --output json|yaml prints one versioned document ({schemaVersion, kind, data}) on stdout;")
+	fmt.Fprintln(out, "  logs and progress messages go to stderr. Exit codes are the same as with table output.")
+	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Examples:")
 	fmt.Fprintln(out, "  iflowkit config init")
 	fmt.Fprintln(out, "  iflowkit profile init")
@@ -49,8 +49,12 @@ func configInit(ctx *Context, argv []string) error {
 		return err
 	}
 	current := ""
+	maxAgeDays := models.DefaultTenantKeyMaxAgeDays
 	if existing != nil {
 		current = existing.ProfileExportDir
+		if existing.TenantKeyMaxAgeDays != 0 {
+			maxAgeDays = existing.TenantKeyMaxAgeDays
+		}
 	}
 
 	exportDir, err := io.AskString("Profile export directory", &current, validate.RequiredNonEmpty("profileExportDir"))
@@ -74,9 +78,20 @@ func configInit(ctx *Context, argv []string) error {
 		}
 	}
 
+	maxAgeDays, err = io.AskInt("Warn when a tenant key is older than (days, -1 disables)", &maxAgeDays, func(v int) error {
+		if v == 0 || v < -1 {
+			return fmt.Errorf("tenantKeyMaxAgeDays must be a positive number of days or -1")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
 	cfg := models.Config{
-		SchemaVersion:    models.CurrentConfigSchemaVersion,
-		ProfileExportDir: exportDir,
+		SchemaVersion:       models.CurrentConfigSchemaVersion,
+		ProfileExportDir:    exportDir,
+		TenantKeyMaxAgeDays: maxAgeDays,
 	}
 	if err := ctx.Stores.Config.Write(cfg, true); err != nil {
 		return err
@@ -97,9 +112,7 @@ func configShow(ctx *Context, argv []string) error {
 	if err != nil {
 		return err
 	}
-	b, _ := cfg.PrettyJSON()
-	fmt.Fprintln(ctx.Stdout, string(b))
-	return nil
+	return ctx.Render(modelResult{kind: "Config", model: cfg})
 }
 
 func configExport(ctx *Context, argv []string) error {
@@ -3,8 +3,10 @@ package app
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/iflowkit/iflowkit-cli/internal/archive"
 	"github.com/iflowkit/iflowkit-cli/internal/logging"
@@ -14,6 +16,9 @@ import (
 	"github.com/iflowkit/iflowkit-cli/internal/validate"
 )
 
+// archivePassphraseEnv supplies the archive passphrase in non-interactive runs.
+const archivePassphraseEnv = "IFLOWKIT_ARCHIVE_PASSPHRASE"
+
 func runProfile(ctx *Context, args []string) error {
 	if len(args) == 0 {
 		printProfileHelp(ctx, nil)
@@ -116,16 +121,57 @@ func profileList(ctx *Context, argv []string) error {
 	if err != nil {
 		return err
 	}
-	if len(profiles) == 0 {
-		fmt.Fprintln(ctx.Stdout, "(no profiles found)")
+	res := ProfileListResult{Profiles: []ProfileListItem{}}
+	for _, p := range profiles {
+		res.Profiles = append(res.Profiles, ProfileListItem{ID: p.ID, Name: p.Name})
+	}
+	return ctx.Render(res)
+}
+
+// ProfileListResult is the result of `iflowkit profile list`.
+type ProfileListResult struct {
+	Profiles []ProfileListItem `json:"profiles"`
+}
+
+type ProfileListItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (r ProfileListResult) ResultKind() string { return "ProfileList" }
+
+func (r ProfileListResult) WriteTable(w io.Writer) error {
+	if len(r.Profiles) == 0 {
+		fmt.Fprintln(w, "(no profiles found)")
 		return nil
 	}
-	for _, p := range profiles {
-		fmt.Fprintf(ctx.Stdout, "- %s\t%s\n", p.ID, p.Name)
+	for _, p := range r.Profiles {
+		fmt.Fprintf(w, "- %s\t%s\n", p.ID, p.Name)
 	}
 	return nil
 }
 
+// ProfileCurrentResult is the result of `iflowkit profile current`.
+type ProfileCurrentResult struct {
+	Active          string `json:"active"`
+	Resolved        string `json:"resolved,omitempty"`
+	Source          string `json:"source,omitempty"`
+	ResolutionError string `json:"resolutionError,omitempty"`
+}
+
+func (r ProfileCurrentResult) ResultKind() string { return "ProfileCurrent" }
+
+func (r ProfileCurrentResult) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "Active:   %s\n", r.Active)
+	if r.ResolutionError != "" {
+		fmt.Fprintln(w, "Resolved: (none)")
+		fmt.Fprintf(w, "Error:    %s\n", r.ResolutionError)
+		return nil
+	}
+	fmt.Fprintf(w, "Resolved: %s (%s)\n", r.Resolved, r.Source)
+	return nil
+}
+
 func profileCurrent(ctx *Context, argv []string) error {
 	fs := flag.NewFlagSet("profile current", flag.ContinueOnError)
 	fs.SetOutput(ctx.Stderr)
@@ -136,15 +182,14 @@ func profileCurrent(ctx *Context, argv []string) error {
 	activeBytes, _ := os.ReadFile(ctx.Paths.ActiveProfileFile)
 	active := store.CleanSingleLine(string(activeBytes))
 
+	res := ProfileCurrentResult{Active: active}
 	resolved, src, err := ctx.Stores.ResolveProfileID(ctx.Flags.ProfileID)
-	fmt.Fprintf(ctx.Stdout, "Active:   %s\n", active)
 	if err != nil {
-		fmt.Fprintln(ctx.Stdout, "Resolved: (none)")
-		fmt.Fprintf(ctx.Stdout, "Error:    %v\n", err)
-		return nil
+		res.ResolutionError = err.Error()
+		return ctx.Render(res)
 	}
-	fmt.Fprintf(ctx.Stdout, "Resolved: %s (%s)\n", resolved, src)
-	return nil
+	res.Resolved, res.Source = resolved, src
+	return ctx.Render(res)
 }
 
 func profileShow(ctx *Context, argv []string) error {
@@ -162,9 +207,7 @@ func profileShow(ctx *Context, argv []string) error {
 	if err != nil {
 		return err
 	}
-	b, _ := p.PrettyJSON()
-	fmt.Fprintln(ctx.Stdout, string(b))
-	return nil
+	return ctx.Render(modelResult{kind: "Profile", model: p})
 }
 
 func profileUse(ctx *Context, argv []string) error {
@@ -218,6 +261,9 @@ func profileExport(ctx *Context, argv []string) error {
 	id := fs.String("id", "", "Profile id")
 	out := fs.String("out", "", "Output file path")
 	overwrite := fs.Bool("overwrite", false, "Overwrite without prompting")
+	noSecrets := fs.Bool("no-secrets", false, "Export profile.json and the env list only (no tenant service keys)")
+	encrypt := fs.Bool("encrypt", false, "Encrypt the archive (always on when tenant keys are included)")
+	passphraseFile := fs.String("passphrase-file", "", "Read the archive passphrase from a file")
 	if err := fs.Parse(argv); err != nil {
 		return wrapFlagError(err)
 	}
@@ -256,12 +302,29 @@ func profileExport(ctx *Context, argv []string) error {
 		}
 	}
 
+	envs, err := ctx.Stores.Tenants.List(*id)
+	if err != nil {
+		return err
+	}
+	opts := archive.ProfileExportOptions{SecretFree: *noSecrets, Envs: envs}
+	// Tenant service keys never leave the machine in clear text.
+	if *encrypt || (!*noSecrets && len(envs) > 0) {
+		pass, err := readArchivePassphrase(ctx, *passphraseFile, true)
+		if err != nil {
+			return err
+		}
+		opts.Passphrase = pass
+	}
+
 	srcDir := ctx.Stores.Profiles.ProfileDir(*id)
-	if err := archive.ExportProfile(srcDir, *id, finalOut); err != nil {
+	if err := archive.ExportProfile(srcDir, *id, finalOut, opts); err != nil {
 		return err
 	}
-	ctx.Logger.Info("profile exported", logging.F("profile_id", *id), logging.F("out", finalOut))
+	ctx.Logger.Info("profile exported", logging.F("profile_id", *id), logging.F("out", finalOut), logging.F("encrypted", opts.Passphrase != ""), logging.F("secret_free", opts.SecretFree))
 	fmt.Fprintf(ctx.Stdout, "Exported: %s\n", finalOut)
+	if opts.Passphrase != "" {
+		fmt.Fprintln(ctx.Stdout, "Archive is encrypted; share the passphrase through a separate channel.")
+	}
 	return nil
 }
 
@@ -270,6 +333,7 @@ func profileImport(ctx *Context, argv []string) error {
 	fs.SetOutput(ctx.Stderr)
 	file := fs.String("file", "", "Input .iflowkit archive")
 	overwrite := fs.Bool("overwrite", false, "Overwrite without prompting")
+	passphraseFile := fs.String("passphrase-file", "", "Read the archive passphrase from a file")
 	if err := fs.Parse(argv); err != nil {
 		return wrapFlagError(err)
 	}
@@ -304,8 +368,20 @@ func profileImport(ctx *Context, argv []string) error {
 		}
 	}
 
+	manifest, err := archive.ReadManifest(*file)
+	if err != nil {
+		return err
+	}
+	passphrase := ""
+	if manifest != nil && manifest.Encrypted() {
+		passphrase, err = readArchivePassphrase(ctx, *passphraseFile, false)
+		if err != nil {
+			return err
+		}
+	}
+
 	destDir := ctx.Stores.Profiles.ProfileDir(id)
-	if err := archive.ImportProfile(*file, destDir, true); err != nil {
+	if err := archive.ImportProfile(*file, destDir, true, passphrase); err != nil {
 		return err
 	}
 
@@ -318,9 +394,72 @@ func profileImport(ctx *Context, argv []string) error {
 
 	ctx.Logger.Info("profile imported", logging.F("profile_id", id), logging.F("file", *file))
 	fmt.Fprintf(ctx.Stdout, "Imported profile: %s\n", id)
+	if manifest != nil && manifest.SecretFree {
+		have, _ := ctx.Stores.Tenants.List(id)
+		for _, env := range manifest.Envs {
+			if containsString(have, env) {
+				continue
+			}
+			fmt.Fprintf(ctx.Stdout, "Tenant key not included for %s; import it with: iflowkit --profile %s tenant import --env %s --file <service-key.json>\n", env, id, env)
+		}
+	}
 	return nil
 }
 
+// readArchivePassphrase resolves the archive passphrase from --passphrase-file,
+// IFLOWKIT_ARCHIVE_PASSPHRASE or an interactive prompt. With confirm, the prompt asks twice.
+func readArchivePassphrase(ctx *Context, file string, confirm bool) (string, error) {
+	minLen := func(s string) error {
+		if len(s) < archive.MinPassphraseLength {
+			return fmt.Errorf("passphrase must be at least %d characters", archive.MinPassphraseLength)
+		}
+		return nil
+	}
+	if file != "" {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		pass := strings.TrimRight(string(b), "\r\n")
+		if err := minLen(pass); err != nil {
+			return "", err
+		}
+		return pass, nil
+	}
+	if pass := os.Getenv(archivePassphraseEnv); pass != "" {
+		if err := minLen(pass); err != nil {
+			return "", fmt.Errorf("%s: %w", archivePassphraseEnv, err)
+		}
+		return pass, nil
+	}
+
+	io := prompt.NewIO(ctx.Stdin, ctx.Stdout)
+	pass, err := io.AskSecret("Archive passphrase", minLen)
+	if err != nil {
+		return "", err
+	}
+	if !confirm {
+		return pass, nil
+	}
+	again, err := io.AskSecret("Repeat passphrase", nil)
+	if err != nil {
+		return "", err
+	}
+	if again != pass {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return pass, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, it := range list {
+		if it == s {
+			return true
+		}
+	}
+	return false
+}
+
 func printProfileHelp(ctx *Context, path []string) {
 	out := ctx.Stdout
 	if len(path) == 0 {
@@ -376,10 +515,23 @@ func printProfileDeleteHelp(ctx *Context) {
 
 func printProfileExportHelp(ctx *Context) {
 	fmt.Fprintln(ctx.Stdout, "Usage:")
-	fmt.Fprintln(ctx.Stdout, "  iflowkit profile export --id <profileId> [--out <file.iflowkit>] [--overwrite]")
+	fmt.Fprintln(ctx.Stdout, "  iflowkit profile export --id <profileId> [--out <file.iflowkit>] [--overwrite] [--no-secrets] [--encrypt] [--passphrase-file <file>]")
+	fmt.Fprintln(ctx.Stdout, "")
+	fmt.Fprintln(ctx.Stdout, "Notes:")
+	fmt.Fprintln(ctx.Stdout, "  - Archives that include tenant service keys are always encrypted (AES-256-GCM)")
+	fmt.Fprintln(ctx.Stdout, "  - --no-secrets exports profile.json and the env list only (for sharing with new team members)")
+	fmt.Fprintln(ctx.Stdout, "  - Passphrase source: --passphrase-file, IFLOWKIT_ARCHIVE_PASSPHRASE, or interactive prompt (not echoed)")
+	fmt.Fprintln(ctx.Stdout, "  - Keys replaced by `tenant rotate` (rollback slot) are never exported")
+	fmt.Fprintln(ctx.Stdout, "  - Machine-local files (credential-helpers.json, tenant-status.json, webhooks.json) are never exported")
 }
 
 func printProfileImportHelp(ctx *Context) {
 	fmt.Fprintln(ctx.Stdout, "Usage:")
-	fmt.Fprintln(ctx.Stdout, "  iflowkit profile import --file <*.iflowkit> [--overwrite]")
+	fmt.Fprintln(ctx.Stdout, "  iflowkit profile import --file <*.iflowkit> [--overwrite] [--passphrase-file <file>]")
+	fmt.Fprintln(ctx.Stdout, "")
+	fmt.Fprintln(ctx.Stdout, "Notes:")
+	fmt.Fprintln(ctx.Stdout, "  - File checksums are verified before anything is written")
+	fmt.Fprintln(ctx.Stdout, "  - Encrypted archives ask for the passphrase (or use --passphrase-file / IFLOWKIT_ARCHIVE_PASSPHRASE)")
+	fmt.Fprintln(ctx.Stdout, "  - Importing a secret-free archive over an existing profile keeps its tenant keys")
+	fmt.Fprintln(ctx.Stdout, "  - Archives carrying credential-helpers.json, tenant-status.json or webhooks.json are refused; the existing profile keeps its own")
 }
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// modelResult renders a stored JSON model (profile.json, config.json, a tenant key) as the
+// result data. The table form is the pretty-printed JSON, as the show commands always printed it.
+type modelResult struct {
+	kind  string
+	model interface{ PrettyJSON() ([]byte, error) }
+}
+
+func (r modelResult) ResultKind() string { return r.kind }
+
+func (r modelResult) MarshalJSON() ([]byte, error) { return json.Marshal(r.model) }
+
+func (r modelResult) WriteTable(w io.Writer) error {
+	b, err := r.model.PrettyJSON()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
@@ -1,14 +1,18 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
+	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
 	"github.com/iflowkit/iflowkit-cli/internal/logging"
 	"github.com/iflowkit/iflowkit-cli/internal/models"
+	"github.com/iflowkit/iflowkit-cli/internal/store"
 	"github.com/iflowkit/iflowkit-cli/internal/validate"
 )
 
@@ -29,6 +33,12 @@ func runTenant(ctx *Context, args []string) error {
 		return tenantSet(ctx, subArgs)
 	case "delete":
 		return tenantDelete(ctx, subArgs)
+	case "helper":
+		return tenantHelper(ctx, subArgs)
+	case "list":
+		return tenantList(ctx, subArgs)
+	case "rotate":
+		return tenantRotate(ctx, subArgs)
 	default:
 		return fmt.Errorf("unknown subcommand: tenant %s", sub)
 	}
@@ -82,6 +92,7 @@ func tenantShow(ctx *Context, argv []string) error {
 	fs := flag.NewFlagSet("tenant show", flag.ContinueOnError)
 	fs.SetOutput(ctx.Stderr)
 	env := fs.String("env", "dev", "Environment: dev|qas|prd")
+	reveal := fs.Bool("reveal", false, "Print the client secret in clear text")
 	if err := fs.Parse(argv); err != nil {
 		return wrapFlagError(err)
 	}
@@ -94,13 +105,17 @@ func tenantShow(ctx *Context, argv []string) error {
 		return err
 	}
 
-	t, err := ctx.Stores.Tenants.Read(profileID, *env)
+	t, source, err := ctx.Stores.Tenants.ReadWithSource(profileID, *env)
 	if err != nil {
 		return err
 	}
-	b, _ := t.PrettyJSON()
-	fmt.Fprintln(ctx.Stdout, string(b))
-	return nil
+	ctx.Logger.Info("tenant key source", logging.F("env", *env), logging.F("source", source))
+	if *reveal {
+		ctx.Logger.Warn("tenant secret revealed", logging.F("profile", profileID), logging.F("env", *env))
+	} else {
+		t.OAuth.ClientSecret = logging.MaskSecret(t.OAuth.ClientSecret)
+	}
+	return ctx.Render(modelResult{kind: "TenantKey", model: t})
 }
 
 func tenantSet(ctx *Context, argv []string) error {
@@ -195,6 +210,253 @@ func tenantDelete(ctx *Context, argv []string) error {
 	return nil
 }
 
+func tenantList(ctx *Context, argv []string) error {
+	fs := flag.NewFlagSet("tenant list", flag.ContinueOnError)
+	fs.SetOutput(ctx.Stderr)
+	if err := fs.Parse(argv); err != nil {
+		return wrapFlagError(err)
+	}
+
+	profileID, _, err := ctx.Stores.ResolveProfileID(ctx.Flags.ProfileID)
+	if err != nil {
+		return err
+	}
+	prof, err := ctx.Stores.Profiles.Read(profileID)
+	if err != nil {
+		return err
+	}
+	status, err := ctx.Stores.Tenants.ReadStatus(profileID)
+	if err != nil {
+		return err
+	}
+	maxAge := models.Config{}.TenantKeyMaxAge()
+	if cfg, err := ctx.Stores.Config.ReadOptional(); err == nil && cfg != nil {
+		maxAge = cfg.TenantKeyMaxAge()
+	}
+
+	envs := []string{"dev", "qas", "prd"}
+	if prof.CPITenantLevels == 2 {
+		envs = []string{"dev", "prd"}
+	}
+
+	res := TenantListResult{Profile: profileID, MaxAgeDays: int(maxAge.Hours() / 24), Tenants: []TenantListItem{}}
+	for _, env := range envs {
+		item := TenantListItem{Env: env}
+		t, source, err := ctx.Stores.Tenants.ReadWithSource(profileID, env)
+		if err != nil {
+			item.Error = "error: " + err.Error()
+			if os.IsNotExist(err) {
+				item.Error = "(not configured)"
+			}
+			res.Tenants = append(res.Tenants, item)
+			continue
+		}
+		item.Configured = true
+		item.Source = source
+		item.TokenHost = t.TokenHost()
+		if created, ok := t.CreatedAt(); ok {
+			d := time.Since(created)
+			days := int(d.Hours() / 24)
+			item.AgeDays = &days
+			item.Stale = maxAge > 0 && d > maxAge
+		}
+		item.LastAuthAt = status.Envs[env].LastAuthAt
+		item.Rollback = ctx.Stores.Tenants.HasRollback(profileID, env)
+		res.Tenants = append(res.Tenants, item)
+	}
+	return ctx.Render(res)
+}
+
+// TenantListResult is the result of `iflowkit tenant list`.
+type TenantListResult struct {
+	Profile    string           `json:"profile"`
+	MaxAgeDays int              `json:"maxAgeDays"`
+	Tenants    []TenantListItem `json:"tenants"`
+}
+
+type TenantListItem struct {
+	Env        string `json:"env"`
+	Configured bool   `json:"configured"`
+	Source     string `json:"source,omitempty"`
+	AgeDays    *int   `json:"ageDays,omitempty"`
+	Stale      bool   `json:"stale"`
+	TokenHost  string `json:"tokenHost,omitempty"`
+	LastAuthAt string `json:"lastAuthAt,omitempty"`
+	Rollback   bool   `json:"rollback"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (r TenantListResult) ResultKind() string { return "TenantList" }
+
+func (r TenantListResult) WriteTable(w io.Writer) error {
+	stale := false
+	fmt.Fprintf(w, "%-5s %-7s %-10s %-38s %-22s %s\n", "ENV", "SOURCE", "AGE", "TOKEN_HOST", "LAST_AUTH", "ROLLBACK")
+	for _, t := range r.Tenants {
+		if !t.Configured {
+			fmt.Fprintf(w, "%-5s %s\n", t.Env, t.Error)
+			continue
+		}
+		// Keys from env vars or credential helpers may carry no createdate.
+		age := "unknown"
+		if t.AgeDays != nil {
+			age = fmt.Sprintf("%dd", *t.AgeDays)
+			if t.Stale {
+				age += " (!)"
+				stale = true
+			}
+		}
+		lastAuth := t.LastAuthAt
+		if lastAuth == "" {
+			lastAuth = "-"
+		}
+		rollback := "-"
+		if t.Rollback {
+			rollback = "yes"
+		}
+		fmt.Fprintf(w, "%-5s %-7s %-10s %-38s %-22s %s\n", t.Env, t.Source, age, t.TokenHost, lastAuth, rollback)
+	}
+	if stale {
+		fmt.Fprintf(w, "\n(!) older than %d days (config: tenantKeyMaxAgeDays)\n", r.MaxAgeDays)
+	}
+	return nil
+}
+
+func tenantRotate(ctx *Context, argv []string) error {
+	fs := flag.NewFlagSet("tenant rotate", flag.ContinueOnError)
+	fs.SetOutput(ctx.Stderr)
+	env := fs.String("env", "", "Environment: dev|qas|prd")
+	file := fs.String("file", "", "New service key JSON file")
+	rollback := fs.Bool("rollback", false, "Swap back to the key kept by the previous rotation")
+	noVerify := fs.Bool("no-verify", false, "Skip the live token request")
+	if err := fs.Parse(argv); err != nil {
+		return wrapFlagError(err)
+	}
+	if *env == "" || (*file == "" && !*rollback) || (*file != "" && *rollback) {
+		printTenantRotateHelp(ctx)
+		return fmt.Errorf("required: --env and one of --file or --rollback")
+	}
+	if err := validate.Env(*env); err != nil {
+		return err
+	}
+
+	profileID, _, err := ctx.Stores.ResolveProfileID(ctx.Flags.ProfileID)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Stores.Profiles.RequireExists(profileID); err != nil {
+		return err
+	}
+
+	if *rollback {
+		if err := ctx.Stores.Tenants.Rollback(profileID, *env); err != nil {
+			return err
+		}
+		ctx.Logger.Info("tenant key rolled back", logging.F("profile_id", profileID), logging.F("env", *env))
+		fmt.Fprintf(ctx.Stdout, "Tenant key rolled back: %s/%s (the replaced key is now in the rollback slot)\n", profileID, *env)
+		return nil
+	}
+
+	b, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	var t models.TenantServiceKey
+	if err := json.Unmarshal(b, &t); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if t.OAuth.CreateDate == "" {
+		t.OAuth.CreateDate = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	if err := t.ValidateRequired(); err != nil {
+		return err
+	}
+
+	if !*noVerify {
+		c := cpix.NewClient(t, ctx.Logger)
+		if err := c.VerifyAuth(context.Background()); err != nil {
+			return fmt.Errorf("new key failed verification (stored key unchanged): %w", err)
+		}
+		ctx.Logger.Info("new tenant key verified", logging.F("env", *env), logging.F("token_host", t.TokenHost()))
+	}
+
+	if err := ctx.Stores.Tenants.Rotate(profileID, *env, t); err != nil {
+		return err
+	}
+	if !*noVerify {
+		_ = ctx.Stores.Tenants.RecordAuth(profileID, *env, time.Now())
+	}
+	ctx.Logger.Info("tenant key rotated", logging.F("profile_id", profileID), logging.F("env", *env))
+	fmt.Fprintf(ctx.Stdout, "Tenant key rotated: %s/%s (previous key kept; undo with --rollback)\n", profileID, *env)
+	if _, source, err := ctx.Stores.Tenants.ReadWithSource(profileID, *env); err == nil && source != store.TenantSourceFile {
+		ctx.Logger.Warn("the stored key is shadowed by another source", logging.F("env", *env), logging.F("source", source))
+	}
+	return nil
+}
+
+func tenantHelper(ctx *Context, argv []string) error {
+	if len(argv) == 0 {
+		printTenantHelperHelp(ctx)
+		return nil
+	}
+	action := argv[0]
+	fs := flag.NewFlagSet("tenant helper "+action, flag.ContinueOnError)
+	fs.SetOutput(ctx.Stderr)
+	env := fs.String("env", "", "Environment: dev|qas|prd or * for all envs")
+	if err := fs.Parse(argv[1:]); err != nil {
+		return wrapFlagError(err)
+	}
+
+	profileID, _, err := ctx.Stores.ResolveProfileID(ctx.Flags.ProfileID)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Stores.Profiles.RequireExists(profileID); err != nil {
+		return err
+	}
+
+	if action == "show" {
+		cfg, err := ctx.Stores.Tenants.ReadHelpers(profileID)
+		if err != nil {
+			return err
+		}
+		return ctx.Render(modelResult{kind: "TenantHelpers", model: cfg})
+	}
+
+	if *env == "" {
+		printTenantHelperHelp(ctx)
+		return fmt.Errorf("--env is required")
+	}
+	if *env != "*" {
+		if err := validate.Env(*env); err != nil {
+			return err
+		}
+	}
+
+	switch action {
+	case "set":
+		rest := fs.Args()
+		if len(rest) == 0 {
+			printTenantHelperHelp(ctx)
+			return fmt.Errorf("helper command is required after --")
+		}
+		h := models.CredentialHelper{Command: rest[0], Args: rest[1:]}
+		if err := ctx.Stores.Tenants.SetHelper(profileID, *env, &h); err != nil {
+			return err
+		}
+		ctx.Logger.Info("tenant credential helper set", logging.F("profile_id", profileID), logging.F("env", *env), logging.F("command", h.Command))
+		fmt.Fprintf(ctx.Stdout, "Credential helper set: %s/%s\n", profileID, *env)
+	case "unset":
+		if err := ctx.Stores.Tenants.SetHelper(profileID, *env, nil); err != nil {
+			return err
+		}
+		ctx.Logger.Info("tenant credential helper removed", logging.F("profile_id", profileID), logging.F("env", *env))
+		fmt.Fprintf(ctx.Stdout, "Credential helper removed: %s/%s\n", profileID, *env)
+	default:
+		return fmt.Errorf("unknown subcommand: tenant helper %s", action)
+	}
+	return nil
+}
+
 func printTenantHelp(ctx *Context, path []string) {
 	out := ctx.Stdout
 	if len(path) == 0 {
@@ -203,9 +465,17 @@ func printTenantHelp(ctx *Context, path []string) {
 		fmt.Fprintln(out, "")
 		fmt.Fprintln(out, "Commands:")
 		fmt.Fprintln(out, "  import   Import a CPI service key JSON")
+		fmt.Fprintln(out, "  list     List envs with key age, token host and last auth")
+		fmt.Fprintln(out, "  rotate   Replace a key after a live check (keeps a rollback slot)")
 		fmt.Fprintln(out, "  show     Show tenant service key")
 		fmt.Fprintln(out, "  set      Set tenant service key fields directly")
 		fmt.Fprintln(out, "  delete   Delete tenant service key")
+		fmt.Fprintln(out, "  helper   Configure a credential helper that prints the service key")
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, "Key sources (first match wins):")
+		fmt.Fprintln(out, "  1. IFLOWKIT_TENANT_<ENV>_JSON, or IFLOWKIT_TENANT_<ENV>_URL/_TOKEN_URL/_CLIENT_ID/_CLIENT_SECRET[/_CREATED_AT]")
+		fmt.Fprintln(out, "  2. Credential helper configured with `tenant helper set`")
+		fmt.Fprintln(out, "  3. tenants/<env>.json in the profile")
 		fmt.Fprintln(out, "")
 		fmt.Fprintln(out, "Try:")
 		fmt.Fprintln(out, "  iflowkit help tenant import")
@@ -214,10 +484,16 @@ func printTenantHelp(ctx *Context, path []string) {
 	switch path[0] {
 	case "import":
 		printTenantImportHelp(ctx)
+	case "show":
+		printTenantShowHelp(ctx)
 	case "set":
 		printTenantSetHelp(ctx)
 	case "delete":
 		printTenantDeleteHelp(ctx)
+	case "helper":
+		printTenantHelperHelp(ctx)
+	case "rotate":
+		printTenantRotateHelp(ctx)
 	default:
 		fmt.Fprintln(out, "Unknown tenant command.")
 	}
@@ -228,6 +504,13 @@ func printTenantImportHelp(ctx *Context) {
 	fmt.Fprintln(ctx.Stdout, "  iflowkit tenant import --file <service-key.json> [--env dev|qas|prd]")
 }
 
+func printTenantShowHelp(ctx *Context) {
+	fmt.Fprintln(ctx.Stdout, "Usage:")
+	fmt.Fprintln(ctx.Stdout, "  iflowkit tenant show [--env dev|qas|prd] [--reveal]")
+	fmt.Fprintln(ctx.Stdout, "")
+	fmt.Fprintln(ctx.Stdout, "The client secret is masked unless --reveal is given.")
+}
+
 func printTenantSetHelp(ctx *Context) {
 	fmt.Fprintln(ctx.Stdout, "Usage:")
 	fmt.Fprintln(ctx.Stdout, "  iflowkit tenant set --env dev|qas|prd --url <url> --token-url <tokenUrl> --client-id <id> --client-secret <secret> [--created-at <rfc3339>]")
@@ -237,3 +520,22 @@ func printTenantDeleteHelp(ctx *Context) {
 	fmt.Fprintln(ctx.Stdout, "Usage:")
 	fmt.Fprintln(ctx.Stdout, "  iflowkit tenant delete --env dev|qas|prd --yes")
 }
+
+func printTenantRotateHelp(ctx *Context) {
+	fmt.Fprintln(ctx.Stdout, "Usage:")
+	fmt.Fprintln(ctx.Stdout, "  iflowkit tenant rotate --env dev|qas|prd --file <service-key.json> [--no-verify]")
+	fmt.Fprintln(ctx.Stdout, "  iflowkit tenant rotate --env dev|qas|prd --rollback")
+	fmt.Fprintln(ctx.Stdout, "")
+	fmt.Fprintln(ctx.Stdout, "The new key is verified with a live token request before it replaces the stored key.")
+	fmt.Fprintln(ctx.Stdout, "The previous key is kept in a rollback slot.")
+}
+
+func printTenantHelperHelp(ctx *Context) {
+	fmt.Fprintln(ctx.Stdout, "Usage:")
+	fmt.Fprintln(ctx.Stdout, "  iflowkit tenant helper set --env dev|qas|prd|* -- <command> [args...]")
+	fmt.Fprintln(ctx.Stdout, "  iflowkit tenant helper unset --env dev|qas|prd|*")
+	fmt.Fprintln(ctx.Stdout, "  iflowkit tenant helper show")
+	fmt.Fprintln(ctx.Stdout, "")
+	fmt.Fprintln(ctx.Stdout, "The helper must print the service key JSON on stdout. It runs with")
+	fmt.Fprintln(ctx.Stdout, "IFLOWKIT_PROFILE and IFLOWKIT_ENV set. Environment variables take precedence.")
+}
@@ -2,38 +2,72 @@ package app
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/iflowkit/iflowkit-cli/internal/store"
 )
 
+// WhereResult is the result of `iflowkit where`.
+type WhereResult struct {
+	ConfigRoot        string `json:"configRoot"`
+	ProfilesDir       string `json:"profilesDir"`
+	ConfigFile        string `json:"configFile"`
+	ActiveProfileFile string `json:"activeProfileFile"`
+	LogsDir           string `json:"logsDir"`
+	ActiveProfileID   string `json:"activeProfileId"`
+	ResolvedProfile   string `json:"resolvedProfile,omitempty"`
+	ResolvedSource    string `json:"resolvedSource,omitempty"`
+	ResolvedPath      string `json:"resolvedPath,omitempty"`
+	ResolutionError   string `json:"resolutionError,omitempty"`
+}
+
+func (r WhereResult) ResultKind() string { return "Where" }
+
+func (r WhereResult) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "Config root:         %s\n", r.ConfigRoot)
+	fmt.Fprintf(w, "Profiles dir:        %s\n", r.ProfilesDir)
+	fmt.Fprintf(w, "Config file:         %s\n", r.ConfigFile)
+	fmt.Fprintf(w, "Active profile file: %s\n", r.ActiveProfileFile)
+	fmt.Fprintf(w, "Logs dir:            %s\n", r.LogsDir)
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "Active profile id:   %s\n", r.ActiveProfileID)
+	if r.ResolutionError != "" {
+		fmt.Fprintln(w, "Resolved profile:    (none)")
+		fmt.Fprintf(w, "Resolution error:    %s\n", r.ResolutionError)
+		return nil
+	}
+	fmt.Fprintf(w, "Resolved profile:    %s (%s)\n", r.ResolvedProfile, r.ResolvedSource)
+	fmt.Fprintf(w, "Resolved path:       %s\n", r.ResolvedPath)
+	return nil
+}
+
 func runWhere(ctx *Context, args []string) error {
 	if len(args) > 0 {
 		return fmt.Errorf("where does not accept subcommands")
 	}
 	p := ctx.Paths
-
-	fmt.Fprintf(ctx.Stdout, "Config root:         %s\n", p.ConfigRoot)
-	fmt.Fprintf(ctx.Stdout, "Profiles dir:        %s\n", p.ProfilesDir)
-	fmt.Fprintf(ctx.Stdout, "Config file:         %s\n", p.ConfigFile)
-	fmt.Fprintf(ctx.Stdout, "Active profile file: %s\n", p.ActiveProfileFile)
-	fmt.Fprintf(ctx.Stdout, "Logs dir:            %s\n", p.LogsDir)
-	fmt.Fprintln(ctx.Stdout, "")
+	res := WhereResult{
+		ConfigRoot:        p.ConfigRoot,
+		ProfilesDir:       p.ProfilesDir,
+		ConfigFile:        p.ConfigFile,
+		ActiveProfileFile: p.ActiveProfileFile,
+		LogsDir:           p.LogsDir,
+	}
 
 	active, _ := os.ReadFile(p.ActiveProfileFile)
-	activeStr := store.CleanSingleLine(string(active))
-	fmt.Fprintf(ctx.Stdout, "Active profile id:   %s\n", activeStr)
+	res.ActiveProfileID = store.CleanSingleLine(string(active))
 
 	resolved, src, err := ctx.Stores.ResolveProfileID(ctx.Flags.ProfileID)
 	if err != nil {
-		fmt.Fprintln(ctx.Stdout, "Resolved profile:    (none)")
-		fmt.Fprintf(ctx.Stdout, "Resolution error:    %v\n", err)
-		return nil
+		res.ResolutionError = err.Error()
+		return ctx.Render(res)
 	}
-	fmt.Fprintf(ctx.Stdout, "Resolved profile:    %s (%s)\n", resolved, src)
-	fmt.Fprintf(ctx.Stdout, "Resolved path:       %s\n", filepath.Join(p.ProfilesDir, resolved))
-	return nil
+	res.ResolvedProfile = resolved
+	res.ResolvedSource = src
+	res.ResolvedPath = filepath.Join(p.ProfilesDir, resolved)
+	return ctx.Render(res)
 }
 
 func printWhereHelp(ctx *Context) {
@@ -3,20 +3,54 @@ package archive
 import (
 	"archive/zip"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/iflowkit/iflowkit-cli/internal/common/filex"
 	"github.com/iflowkit/iflowkit-cli/internal/models"
 )
 
+// ProfileExportOptions controls what ExportProfile writes.
+type ProfileExportOptions struct {
+	// SecretFree leaves tenants/*.json out of the archive. Only profile.json and the
+	// list of configured envs (manifest) are exported.
+	SecretFree bool
+	// Passphrase encrypts the archive content when non-empty.
+	Passphrase string
+	// Envs are the tenant envs configured in the profile, recorded in the manifest.
+	Envs []string
+}
+
+// localProfileFiles are profile files that never leave the machine: credential-helpers.json
+// names commands the CLI runs to fetch tenant keys, tenant-status.json records local auth times
+// and webhooks.json holds incoming-webhook URLs, which are bearer secrets.
+// ExportProfile skips them and ImportProfile refuses archives carrying them.
+var localProfileFiles = []string{"credential-helpers.json", "tenant-status.json", "webhooks.json"}
+
+func isLocalProfileFile(rel string) bool {
+	for _, f := range localProfileFiles {
+		if rel == f {
+			return true
+		}
+	}
+	return false
+}
+
+type archiveEntry struct {
+	Name string
+	Data []byte
+}
+
 // ExportProfile zips the profile folder contents (profile.json + tenants/...) into outFile.
-func ExportProfile(profileDir, profileID, outFile string) error {
+func ExportProfile(profileDir, profileID, outFile string, opts ProfileExportOptions) error {
 	// Validate profile.json exists and is correct.
 	b, err := os.ReadFile(filepath.Join(profileDir, "profile.json"))
 	if err != nil {
@@ -30,22 +64,8 @@ func ExportProfile(profileDir, profileID, outFile string) error {
 		return err
 	}
 
-	_ = os.Remove(outFile)
-	f, err := os.OpenFile(outFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	zw := zip.NewWriter(f)
-	defer zw.Close()
-
-	mb, _ := json.MarshalIndent(NewManifest("profile", profileID), "", "  ")
-	if err := addBytes(zw, ManifestFileName, mb); err != nil {
-		return err
-	}
-
-	return filepath.WalkDir(profileDir, func(path string, d os.DirEntry, err error) error {
+	var entries []archiveEntry
+	err = filepath.WalkDir(profileDir, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -57,18 +77,33 @@ func ExportProfile(profileDir, profileID, outFile string) error {
 			return nil
 		}
 		if d.IsDir() {
+			if opts.SecretFree && filepath.ToSlash(rel) == "tenants" {
+				return filepath.SkipDir
+			}
+			// Keys superseded by a rotation stay on this machine.
+			if filepath.ToSlash(rel) == "tenants/.rollback" {
+				return filepath.SkipDir
+			}
 			return nil
 		}
-		if strings.HasPrefix(d.Name(), ".DS_Store") {
+		if strings.HasPrefix(d.Name(), ".DS_Store") || filepath.ToSlash(rel) == ManifestFileName || isLocalProfileFile(filepath.ToSlash(rel)) {
 			return nil
 		}
 		data, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		name := filepath.ToSlash(rel)
-		return addBytes(zw, name, data)
+		entries = append(entries, archiveEntry{Name: filepath.ToSlash(rel), Data: data})
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	m := NewManifest("profile", profileID)
+	m.SecretFree = opts.SecretFree
+	m.Envs = opts.Envs
+	return writeArchive(outFile, m, entries, opts.Passphrase)
 }
 
 // ExportConfig zips config.json into outFile.
@@ -85,24 +120,98 @@ func ExportConfig(configFile, outFile string) error {
 		return err
 	}
 
-	_ = os.Remove(outFile)
-	f, err := os.OpenFile(outFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	return writeArchive(outFile, NewManifest("config", ""), []archiveEntry{{Name: "config.json", Data: b}}, "")
+}
+
+// writeArchive writes entries plus a manifest listing their checksums.
+// With a passphrase, the same content is zipped in memory, encrypted and stored as PayloadFileName.
+func writeArchive(outFile string, m Manifest, entries []archiveEntry, passphrase string) error {
+	m.Files = checksumEntries(entries)
+	content, err := buildZip(m, entries)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	if passphrase != "" {
+		sealed, info, err := encryptPayload(content, passphrase)
+		if err != nil {
+			return err
+		}
+		payload := []archiveEntry{{Name: PayloadFileName, Data: sealed}}
+		outer := m
+		outer.Encryption = info
+		outer.Files = checksumEntries(payload)
+		content, err = buildZip(outer, payload)
+		if err != nil {
+			return err
+		}
+	}
 
-	zw := zip.NewWriter(f)
-	defer zw.Close()
+	_ = os.Remove(outFile)
+	return filex.AtomicWriteFile(outFile, content, 0o644)
+}
 
-	mb, _ := json.MarshalIndent(NewManifest("config", ""), "", "  ")
+func buildZip(m Manifest, entries []archiveEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	mb, _ := json.MarshalIndent(m, "", "  ")
 	if err := addBytes(zw, ManifestFileName, mb); err != nil {
-		return err
+		_ = zw.Close()
+		return nil, err
 	}
-	if err := addBytes(zw, "config.json", b); err != nil {
-		return err
+	for _, e := range entries {
+		if err := addBytes(zw, e.Name, e.Data); err != nil {
+			_ = zw.Close()
+			return nil, err
+		}
 	}
-	return nil
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func checksumEntries(entries []archiveEntry) []FileChecksum {
+	out := make([]FileChecksum, 0, len(entries))
+	for _, e := range entries {
+		sum := sha256.Sum256(e.Data)
+		out = append(out, FileChecksum{Path: e.Name, Size: int64(len(e.Data)), SHA256: hex.EncodeToString(sum[:])})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// ReadManifest returns the archive manifest, or nil for legacy archives without one.
+func ReadManifest(zipPath string) (*Manifest, error) {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readManifest(&r.Reader)
+}
+
+func readManifest(zr *zip.Reader) (*Manifest, error) {
+	for _, f := range zr.File {
+		if filepath.Base(f.Name) != ManifestFileName {
+			continue
+		}
+		b, err := readZipEntry(f)
+		if err != nil {
+			return nil, err
+		}
+		var m Manifest
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", ManifestFileName, err)
+		}
+		if m.Kind == "" {
+			return nil, fmt.Errorf("%s missing required field: kind", ManifestFileName)
+		}
+		if m.SchemaVersion < MinArchiveSchemaVersion || m.SchemaVersion > CurrentArchiveSchemaVersion {
+			return nil, fmt.Errorf("unsupported archive schema_version %d (current: %d)", m.SchemaVersion, CurrentArchiveSchemaVersion)
+		}
+		return &m, nil
+	}
+	return nil, nil
 }
 
 func PeekArchive(zipPath string) (profileID string, kind string, err error) {
@@ -112,30 +221,11 @@ func PeekArchive(zipPath string) (profileID string, kind string, err error) {
 	}
 	defer r.Close()
 
-	var manifest *Manifest
-	for _, f := range r.File {
-		if filepath.Base(f.Name) == ManifestFileName {
-			rc, err := f.Open()
-			if err != nil {
-				return "", "", err
-			}
-			b, _ := io.ReadAll(rc)
-			_ = rc.Close()
-			var m Manifest
-			if err := json.Unmarshal(b, &m); err != nil {
-				return "", "", fmt.Errorf("invalid %s: %w", ManifestFileName, err)
-			}
-			manifest = &m
-			break
-		}
+	manifest, err := readManifest(&r.Reader)
+	if err != nil {
+		return "", "", err
 	}
 	if manifest != nil {
-		if manifest.Kind == "" {
-			return "", "", fmt.Errorf("%s missing required field: kind", ManifestFileName)
-		}
-		if manifest.SchemaVersion != CurrentArchiveSchemaVersion {
-			return "", "", fmt.Errorf("unsupported archive schema_version %d (current: %d)", manifest.SchemaVersion, CurrentArchiveSchemaVersion)
-		}
 		if manifest.Kind == "profile" {
 			if manifest.ProfileID != "" {
 				return manifest.ProfileID, manifest.Kind, nil
@@ -159,12 +249,10 @@ func PeekArchive(zipPath string) (profileID string, kind string, err error) {
 		}
 	}
 	if profileJSON != nil {
-		rc, err := profileJSON.Open()
+		b, err := readZipEntry(profileJSON)
 		if err != nil {
 			return "", "", err
 		}
-		b, _ := io.ReadAll(rc)
-		_ = rc.Close()
 		var p models.Profile
 		if err := json.Unmarshal(b, &p); err != nil {
 			return "", "", fmt.Errorf("invalid profile.json: %w", err)
@@ -211,17 +299,21 @@ func findSingleFile(root, filename string) (fullPath string, dir string, err err
 	return best, filepath.Dir(best), nil
 }
 
-func ImportProfile(zipPath, destDir string, overwrite bool) error {
+// ImportProfile extracts a profile archive into destDir.
+//
+// Checksums listed in the manifest are verified before anything is written. Encrypted
+// archives require passphrase. When the archive is secret-free and destDir already exists,
+// the existing tenant keys are kept.
+func ImportProfile(zipPath, destDir string, overwrite bool, passphrase string) error {
 	if err := os.MkdirAll(filepath.Dir(destDir), 0o755); err != nil {
 		return err
 	}
+	destExists := false
 	if _, err := os.Stat(destDir); err == nil {
 		if !overwrite {
 			return fmt.Errorf("destination exists: %s", destDir)
 		}
-		if err := os.RemoveAll(destDir); err != nil {
-			return err
-		}
+		destExists = true
 	}
 
 	// Create temp dir in the same parent to keep rename as atomic as possible.
@@ -236,7 +328,8 @@ func ImportProfile(zipPath, destDir string, overwrite bool) error {
 	if err := os.MkdirAll(extractDir, 0o755); err != nil {
 		return err
 	}
-	if err := extractZipSecure(zipPath, extractDir); err != nil {
+	m, err := extractArchive(zipPath, extractDir, passphrase)
+	if err != nil {
 		return err
 	}
 
@@ -259,9 +352,39 @@ func ImportProfile(zipPath, destDir string, overwrite bool) error {
 	if p.SchemaVersion != models.CurrentProfileSchemaVersion {
 		return fmt.Errorf("unsupported profile schema_version %d (current: %d)", p.SchemaVersion, models.CurrentProfileSchemaVersion)
 	}
+	_ = os.Remove(filepath.Join(rootDir, ManifestFileName))
+	for _, f := range localProfileFiles {
+		if _, err := os.Stat(filepath.Join(rootDir, f)); err == nil {
+			return fmt.Errorf("archive contains %s, which is machine-local and never imported; remove it from the archive", f)
+		}
+	}
+
+	tenantsDir := filepath.Join(rootDir, "tenants")
+	if destExists {
+		if m != nil && m.SecretFree {
+			// Keep the local tenant keys; the archive does not carry any.
+			if _, err := os.Stat(filepath.Join(destDir, "tenants")); err == nil {
+				_ = os.RemoveAll(tenantsDir)
+				if err := os.Rename(filepath.Join(destDir, "tenants"), tenantsDir); err != nil {
+					return err
+				}
+			}
+		}
+		// Local files of the existing profile survive the import.
+		for _, f := range localProfileFiles {
+			if _, err := os.Stat(filepath.Join(destDir, f)); err == nil {
+				if err := os.Rename(filepath.Join(destDir, f), filepath.Join(rootDir, f)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := os.RemoveAll(destDir); err != nil {
+			return err
+		}
+	}
 
 	// Ensure tenants folder exists for consistency.
-	_ = os.MkdirAll(filepath.Join(rootDir, "tenants"), 0o755)
+	_ = os.MkdirAll(tenantsDir, 0o755)
 
 	// Final move.
 	return os.Rename(rootDir, destDir)
@@ -283,7 +406,7 @@ func ImportConfig(zipPath, destConfigFile string, overwrite bool) error {
 	if err := os.MkdirAll(extractDir, 0o755); err != nil {
 		return err
 	}
-	if err := extractZipSecure(zipPath, extractDir); err != nil {
+	if _, err := extractArchive(zipPath, extractDir, ""); err != nil {
 		return err
 	}
 
@@ -310,6 +433,113 @@ func ImportConfig(zipPath, destConfigFile string, overwrite bool) error {
 	return filex.AtomicWriteFile(destConfigFile, b, 0o644)
 }
 
+// extractArchive verifies and extracts an archive into destDir.
+//
+// For encrypted archives the payload is checked, decrypted and the inner archive is
+// verified and extracted instead. It returns the manifest describing the extracted
+// content (nil for legacy archives without a manifest).
+func extractArchive(zipPath, destDir, passphrase string) (*Manifest, error) {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	m, err := readManifest(&r.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyZipChecksums(&r.Reader, m); err != nil {
+		return nil, err
+	}
+	if m == nil || !m.Encrypted() {
+		return m, extractZipSecure(&r.Reader, destDir)
+	}
+
+	if passphrase == "" {
+		return nil, fmt.Errorf("archive is encrypted; a passphrase is required")
+	}
+	var payload *zip.File
+	for _, f := range r.File {
+		if f.Name == PayloadFileName {
+			payload = f
+			break
+		}
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("encrypted archive missing %s", PayloadFileName)
+	}
+	sealed, err := readZipEntry(payload)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := decryptPayload(sealed, passphrase, m.Encryption)
+	if err != nil {
+		return nil, err
+	}
+	inner, err := zip.NewReader(bytes.NewReader(plain), int64(len(plain)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted payload: %w", err)
+	}
+	im, err := readManifest(inner)
+	if err != nil {
+		return nil, err
+	}
+	if im == nil {
+		return nil, fmt.Errorf("encrypted payload missing %s", ManifestFileName)
+	}
+	if err := verifyZipChecksums(inner, im); err != nil {
+		return nil, err
+	}
+	return im, extractZipSecure(inner, destDir)
+}
+
+// verifyZipChecksums checks every entry listed in the manifest and rejects entries that are not listed.
+// Legacy manifests without checksums are accepted as-is.
+func verifyZipChecksums(zr *zip.Reader, m *Manifest) error {
+	if m == nil || len(m.Files) == 0 {
+		return nil
+	}
+	byName := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || f.Name == ManifestFileName {
+			continue
+		}
+		byName[f.Name] = f
+	}
+	listed := make(map[string]struct{}, len(m.Files))
+	for _, fc := range m.Files {
+		listed[fc.Path] = struct{}{}
+		f, ok := byName[fc.Path]
+		if !ok {
+			return fmt.Errorf("archive integrity check failed: missing entry %q", fc.Path)
+		}
+		b, err := readZipEntry(f)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(b)
+		if int64(len(b)) != fc.Size || hex.EncodeToString(sum[:]) != strings.ToLower(fc.SHA256) {
+			return fmt.Errorf("archive integrity check failed: checksum mismatch for %q", fc.Path)
+		}
+	}
+	for name := range byName {
+		if _, ok := listed[name]; !ok {
+			return fmt.Errorf("archive integrity check failed: unexpected entry %q", name)
+		}
+	}
+	return nil
+}
+
+func readZipEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
 func addBytes(zw *zip.Writer, name string, b []byte) error {
 	w, err := zw.Create(name)
 	if err != nil {
@@ -320,13 +550,7 @@ func addBytes(zw *zip.Writer, name string, b []byte) error {
 }
 
 // Zip Slip protection: prevents path traversal when extracting archives.
-func extractZipSecure(zipPath, destDir string) error {
-	r, err := zip.OpenReader(zipPath)
-	if err != nil {
-		return err
-	}
-	defer r.Close()
-
+func extractZipSecure(r *zip.Reader, destDir string) error {
 	if err := os.MkdirAll(destDir, 0o755); err != nil {
 		return err
 	}
@@ -360,12 +584,7 @@ func extractZipSecure(zipPath, destDir string) error {
 			return err
 		}
 
-		rc, err := f.Open()
-		if err != nil {
-			return err
-		}
-		data, err := io.ReadAll(rc)
-		_ = rc.Close()
+		data, err := readZipEntry(f)
 		if err != nil {
 			return err
 		}
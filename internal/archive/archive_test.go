@@ -0,0 +1,117 @@
+package archive
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for p, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExportProfileEntries(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"profile.json":               testProfileJSON,
+		"tenants/dev.json":           `{}`,
+		"tenants/qas.json":           `{}`,
+		"tenants/.rollback/qas.json": `{}`,
+		".DS_Store":                  "x",
+		"credential-helpers.json":    `{"helpers":{"dev":{"command":"sh","args":["-c","id"]}}}`,
+		"tenant-status.json":         `{}`,
+		"webhooks.json":              `{"webhooks":[{"url":"https://hooks.slack.com/services/T0/B0/secret"}]}`,
+	})
+
+	for _, tc := range []struct {
+		name       string
+		secretFree bool
+		want       []string
+	}{
+		{"with keys", false, []string{ManifestFileName, "profile.json", "tenants/dev.json", "tenants/qas.json"}},
+		{"secret free", true, []string{ManifestFileName, "profile.json"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "acme.iflowkit")
+			opts := ProfileExportOptions{SecretFree: tc.secretFree, Envs: []string{"dev", "qas"}}
+			if err := ExportProfile(dir, "acme", out, opts); err != nil {
+				t.Fatal(err)
+			}
+			zr, err := zip.OpenReader(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer zr.Close()
+			var got []string
+			for _, f := range zr.File {
+				got = append(got, f.Name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("entries = %v, want %v", got, tc.want)
+			}
+			m, err := ReadManifest(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.SecretFree != tc.secretFree || !reflect.DeepEqual(m.Envs, opts.Envs) {
+				t.Errorf("manifest = %+v", m)
+			}
+		})
+	}
+}
+
+const testProfileJSON = `{"schema_version":1,"id":"acme","name":"Acme","gitServerUrl":"https://git.example","cpiPath":"acme","cpiTenantLevels":2}`
+
+func TestImportProfileRefusesLocalFiles(t *testing.T) {
+	for _, name := range localProfileFiles {
+		t.Run(name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "acme.iflowkit")
+			entries := []archiveEntry{{Name: "profile.json", Data: []byte(testProfileJSON)}, {Name: name, Data: []byte(`{}`)}}
+			if err := writeArchive(out, NewManifest("profile", "acme"), entries, ""); err != nil {
+				t.Fatal(err)
+			}
+			dest := filepath.Join(t.TempDir(), "profiles", "acme")
+			err := ImportProfile(out, dest, false, "")
+			if err == nil || !strings.Contains(err.Error(), name) {
+				t.Fatalf("ImportProfile() = %v, want refusal of %s", err, name)
+			}
+			if _, err := os.Stat(dest); !os.IsNotExist(err) {
+				t.Errorf("profile was installed: %v", err)
+			}
+		})
+	}
+}
+
+func TestImportProfileKeepsLocalFiles(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{"profile.json": testProfileJSON})
+	out := filepath.Join(t.TempDir(), "acme.iflowkit")
+	if err := ExportProfile(src, "acme", out, ProfileExportOptions{SecretFree: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "acme")
+	writeFiles(t, dest, map[string]string{"profile.json": testProfileJSON, "credential-helpers.json": `{"local":true}`, "tenant-status.json": `{"local":true}`, "webhooks.json": `{"local":true}`})
+	if err := ImportProfile(out, dest, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range localProfileFiles {
+		if b, err := os.ReadFile(filepath.Join(dest, name)); err != nil || string(b) != `{"local":true}` {
+			t.Errorf("%s after import = %q, %v", name, b, err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package archive
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// PayloadFileName is the archive entry holding the encrypted inner zip.
+const PayloadFileName = "payload.enc"
+
+const (
+	cipherAES256GCM  = "aes-256-gcm"
+	kdfPBKDF2SHA256  = "pbkdf2-sha256"
+	defaultKDFRounds = 600000
+	saltSize         = 16
+	keySize          = 32
+)
+
+// MinPassphraseLength is the shortest passphrase accepted for encrypted exports.
+const MinPassphraseLength = 8
+
+// ErrDecrypt is returned when the payload cannot be decrypted (wrong passphrase or tampered data).
+var ErrDecrypt = errors.New("cannot decrypt archive: wrong passphrase or corrupted payload")
+
+// EncryptionInfo stores the parameters required to derive the key and open the payload.
+type EncryptionInfo struct {
+	Cipher     string `json:"cipher"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`  // base64
+	Nonce      string `json:"nonce"` // base64
+}
+
+func encryptPayload(plain []byte, passphrase string) ([]byte, *EncryptionInfo, error) {
+	if len(passphrase) < MinPassphraseLength {
+		return nil, nil, fmt.Errorf("passphrase must be at least %d characters", MinPassphraseLength)
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, err
+	}
+	gcm, err := newGCM(passphrase, salt, defaultKDFRounds)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	info := &EncryptionInfo{
+		Cipher:     cipherAES256GCM,
+		KDF:        kdfPBKDF2SHA256,
+		Iterations: defaultKDFRounds,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+	}
+	return gcm.Seal(nil, nonce, plain, []byte(cipherAES256GCM)), info, nil
+}
+
+func decryptPayload(sealed []byte, passphrase string, info *EncryptionInfo) ([]byte, error) {
+	if info == nil {
+		return nil, fmt.Errorf("archive is not encrypted")
+	}
+	if info.Cipher != cipherAES256GCM || info.KDF != kdfPBKDF2SHA256 {
+		return nil, fmt.Errorf("unsupported archive encryption %s/%s", info.Cipher, info.KDF)
+	}
+	if info.Iterations <= 0 {
+		return nil, fmt.Errorf("invalid archive encryption iterations: %d", info.Iterations)
+	}
+	salt, err := base64.StdEncoding.DecodeString(info.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive encryption salt: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(info.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive encryption nonce: %w", err)
+	}
+	gcm, err := newGCM(passphrase, salt, info.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid archive encryption nonce size: %d", len(nonce))
+	}
+	plain, err := gcm.Open(nil, nonce, sealed, []byte(info.Cipher))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key := pbkdf2SHA256([]byte(passphrase), salt, iterations, keySize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 implements PBKDF2 (RFC 8018) with HMAC-SHA256.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hLen := prf.Size()
+	blocks := (keyLen + hLen - 1) / hLen
+
+	out := make([]byte, 0, blocks*hLen)
+	var idx [4]byte
+	u := make([]byte, hLen)
+	t := make([]byte, hLen)
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(idx[:], uint32(block))
+		prf.Write(idx[:])
+		u = prf.Sum(u[:0])
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t...)
+	}
+	return out[:keyLen]
+}
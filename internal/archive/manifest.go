@@ -3,13 +3,38 @@ package archive
 import "time"
 
 const ManifestFileName = "iflowkit_archive.json"
-const CurrentArchiveSchemaVersion = 1
+
+// CurrentArchiveSchemaVersion is written by exports. Imports accept any version
+// between MinArchiveSchemaVersion and CurrentArchiveSchemaVersion.
+//
+// Version 2 adds per-file checksums, payload encryption and secret-free profile exports.
+const CurrentArchiveSchemaVersion = 2
+const MinArchiveSchemaVersion = 1
 
 type Manifest struct {
 	Kind          string `json:"kind"` // "profile" or "config"
 	SchemaVersion int    `json:"schema_version"`
 	CreatedAt     string `json:"created_at"`
 	ProfileID     string `json:"profile_id,omitempty"`
+
+	// SecretFree is true when tenant service keys were left out of a profile export.
+	SecretFree bool `json:"secret_free,omitempty"`
+	// Envs lists the tenant environments configured for the exported profile.
+	Envs []string `json:"envs,omitempty"`
+
+	// Encryption is set when the archive content is stored as an encrypted payload
+	// (see PayloadFileName) instead of plain zip entries.
+	Encryption *EncryptionInfo `json:"encryption,omitempty"`
+
+	// Files lists checksums for every other entry of the archive.
+	Files []FileChecksum `json:"files,omitempty"`
+}
+
+// FileChecksum describes one archive entry (slash-separated name).
+type FileChecksum struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
 }
 
 func NewManifest(kind string, profileID string) Manifest {
@@ -20,3 +45,8 @@ func NewManifest(kind string, profileID string) Manifest {
 		ProfileID:     profileID,
 	}
 }
+
+// Encrypted reports whether the archive content is an encrypted payload.
+func (m Manifest) Encrypted() bool {
+	return m.Encryption != nil
+}
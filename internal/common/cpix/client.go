@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/iflowkit/iflowkit-cli/internal/common/errorx"
 	"github.com/iflowkit/iflowkit-cli/internal/common/filex"
 	"github.com/iflowkit/iflowkit-cli/internal/common/logx"
 	"github.com/iflowkit/iflowkit-cli/internal/models"
@@ -30,9 +31,24 @@ type Client struct {
 	// token cache
 	token    string
 	tokenExp time.Time
+
+	onAuth func(time.Time)
+}
+
+// OnAuth registers a callback invoked after every successful token request.
+func (c *Client) OnAuth(fn func(time.Time)) {
+	c.onAuth = fn
+}
+
+// VerifyAuth performs a live token request (bypassing the cache) to check the credentials.
+func (c *Client) VerifyAuth(ctx context.Context) error {
+	c.token = ""
+	_, err := c.getToken(ctx)
+	return err
 }
 
 func NewClient(t models.TenantServiceKey, lg *logx.Logger) *Client {
+	logx.RegisterSecret(t.OAuth.ClientSecret)
 	return &Client{
 		baseURL:      strings.TrimRight(t.OAuth.URL, "/"),
 		tokenURL:     t.OAuth.TokenURL,
@@ -59,6 +75,9 @@ type ArtifactInfo struct {
 	URI       string
 	MediaSrc  string
 	EditMedia string
+	// ModifiedAt and ModifiedBy are set when the list endpoint returns them (OData date string as sent by CPI).
+	ModifiedAt string
+	ModifiedBy string
 }
 
 // ReadIntegrationPackage reads the main IntegrationPackages('<id>') payload.
@@ -173,13 +192,19 @@ func (c *Client) ListArtifacts(ctx context.Context, listEndpoint string) (map[st
 		if id == "" {
 			continue
 		}
+		modifiedAt := strings.TrimSpace(it.ModifiedAt)
+		if modifiedAt == "" {
+			modifiedAt = strings.TrimSpace(it.ModifiedDate)
+		}
 		m[id] = ArtifactInfo{
-			ID:        id,
-			Name:      strings.TrimSpace(it.Name),
-			Version:   strings.TrimSpace(it.Version),
-			URI:       strings.TrimSpace(it.Metadata.URI),
-			MediaSrc:  strings.TrimSpace(it.Metadata.MediaSrc),
-			EditMedia: strings.TrimSpace(it.Metadata.EditMedia),
+			ID:         id,
+			Name:       strings.TrimSpace(it.Name),
+			Version:    strings.TrimSpace(it.Version),
+			URI:        strings.TrimSpace(it.Metadata.URI),
+			MediaSrc:   strings.TrimSpace(it.Metadata.MediaSrc),
+			EditMedia:  strings.TrimSpace(it.Metadata.EditMedia),
+			ModifiedAt: modifiedAt,
+			ModifiedBy: strings.TrimSpace(it.ModifiedBy),
 		}
 	}
 	return m, nil
@@ -245,7 +270,7 @@ func (c *Client) doJSONWrite(ctx context.Context, method, urlStr string, body []
 	defer resp.Body.Close()
 	b, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("CPI upload failed (%s): %s", resp.Status, strings.TrimSpace(string(b)))
+		return errorx.Redacted(fmt.Errorf("CPI upload failed (%s): %s", resp.Status, strings.TrimSpace(string(b))))
 	}
 	return nil
 }
@@ -285,6 +310,12 @@ func (c *Client) FetchCSRFToken(ctx context.Context) (string, string, error) {
 		}
 	}
 	cookieHeader := strings.Join(cookies, "; ")
+	logx.RegisterSecret(csrf)
+	for _, c := range cookies {
+		if i := strings.Index(c, "="); i >= 0 {
+			logx.RegisterSecret(c[i+1:])
+		}
+	}
 
 	if csrf == "" {
 		return "", cookieHeader, fmt.Errorf("CSRF token missing in response")
@@ -328,7 +359,7 @@ func (c *Client) PutZip(ctx context.Context, pathOrURL string, zipBytes []byte,
 	defer resp.Body.Close()
 	b, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("CPI upload failed (%s): %s", resp.Status, strings.TrimSpace(string(b)))
+		return errorx.Redacted(fmt.Errorf("CPI upload failed (%s): %s", resp.Status, strings.TrimSpace(string(b))))
 	}
 	return nil
 }
@@ -386,7 +417,7 @@ func (c *Client) deployByEndpoint(ctx context.Context, endpointName, id, version
 	defer resp.Body.Close()
 	b, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("CPI deploy failed (%s): %s", resp.Status, strings.TrimSpace(string(b)))
+		return errorx.Redacted(fmt.Errorf("CPI deploy failed (%s): %s", resp.Status, strings.TrimSpace(string(b))))
 	}
 	return nil
 }
@@ -469,7 +500,7 @@ func (c *Client) getToken(ctx context.Context) (string, error) {
 	defer resp.Body.Close()
 	b, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", fmt.Errorf("token request failed: %s", strings.TrimSpace(string(b)))
+		return "", errorx.Redacted(fmt.Errorf("token request failed: %s", strings.TrimSpace(string(b))))
 	}
 	var tr tokenResponse
 	if err := json.Unmarshal(b, &tr); err != nil {
@@ -478,6 +509,7 @@ func (c *Client) getToken(ctx context.Context) (string, error) {
 	if tr.AccessToken == "" {
 		return "", fmt.Errorf("token response missing access_token")
 	}
+	logx.RegisterSecret(tr.AccessToken)
 	c.token = tr.AccessToken
 	// expires_in might be missing; default to 5 minutes.
 	exp := 300
@@ -485,6 +517,9 @@ func (c *Client) getToken(ctx context.Context) (string, error) {
 		exp = tr.ExpiresIn
 	}
 	c.tokenExp = time.Now().Add(time.Duration(exp-30) * time.Second)
+	if c.onAuth != nil {
+		c.onAuth(time.Now())
+	}
 	return c.token, nil
 }
 
@@ -513,7 +548,7 @@ func (c *Client) getRaw(ctx context.Context, pathOrURL string, accept string) ([
 	defer resp.Body.Close()
 	b, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("CPI request failed (%s): %s", resp.Status, strings.TrimSpace(string(b)))
+		return nil, errorx.Redacted(fmt.Errorf("CPI request failed (%s): %s", resp.Status, strings.TrimSpace(string(b))))
 	}
 	return b, nil
 }
@@ -550,10 +585,14 @@ type listResponse struct {
 }
 
 type artifactItem struct {
-	ID       string `json:"Id"`
-	Name     string `json:"Name"`
-	Version  string `json:"Version"`
-	Metadata struct {
+	ID      string `json:"Id"`
+	Name    string `json:"Name"`
+	Version string `json:"Version"`
+	// Modification fields differ per entity set; absent ones stay empty.
+	ModifiedAt   string `json:"ModifiedAt"`
+	ModifiedDate string `json:"ModifiedDate"`
+	ModifiedBy   string `json:"ModifiedBy"`
+	Metadata     struct {
 		URI       string `json:"uri"`
 		MediaSrc  string `json:"media_src"`
 		EditMedia string `json:"edit_media"`
@@ -0,0 +1,68 @@
+package cpix
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iflowkit/iflowkit-cli/internal/common/errorx"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+func TestClientErrorsRedactResponseBodies(t *testing.T) {
+	const secret, token = "client-secret-4b3a2c1d", "access-token-8e7f6a5b"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/token" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"` + token + `","expires_in":3600}`))
+			return
+		}
+		// A misbehaving gateway echoing the credentials it received.
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(`upstream rejected ` + r.Header.Get("Authorization") + ` for client_secret=` + secret))
+	}))
+	defer srv.Close()
+
+	c := NewClient(models.TenantServiceKey{OAuth: models.TenantOAuth{
+		URL: srv.URL, TokenURL: srv.URL + "/oauth/token", ClientID: "id", ClientSecret: secret,
+	}}, nil)
+	_, err := c.getRaw(context.Background(), "/api/v1/IntegrationPackages", "application/json")
+	if err == nil {
+		t.Fatal("want error")
+	}
+	msg := err.Error()
+	if strings.Contains(msg, secret) || strings.Contains(msg, token) || !strings.Contains(msg, "CPI request failed (502 Bad Gateway): upstream rejected") {
+		t.Errorf("error not redacted: %s", msg)
+	}
+	var re *errorx.RedactedError
+	if !errors.As(err, &re) || !strings.Contains(re.Err.Error(), secret) {
+		t.Errorf("error does not unwrap to the original: %v", err)
+	}
+}
+
+func TestTokenErrorRedactsResponseBody(t *testing.T) {
+	const secret = "client-secret-9c8d7e6f"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`invalid_client: ` + r.Header.Get("Authorization") + ` client_secret=` + secret))
+	}))
+	defer srv.Close()
+
+	c := NewClient(models.TenantServiceKey{OAuth: models.TenantOAuth{
+		URL: srv.URL, TokenURL: srv.URL + "/oauth/token", ClientID: "id", ClientSecret: secret,
+	}}, nil)
+	_, err := c.getToken(context.Background())
+	if err == nil {
+		t.Fatal("want error")
+	}
+	if msg := err.Error(); strings.Contains(msg, secret) || !strings.Contains(msg, "token request failed: invalid_client") {
+		t.Errorf("error not redacted: %s", msg)
+	}
+	var re *errorx.RedactedError
+	if !errors.As(err, &re) || !strings.Contains(re.Err.Error(), secret) {
+		t.Errorf("error does not unwrap to the original: %v", err)
+	}
+}
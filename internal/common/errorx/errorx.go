@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
 )
 
 // ContextError wraps an error with a short operation context.
@@ -48,6 +50,7 @@ func WrapIfErr(errp *error, op string) {
 }
 
 // UserError converts internal errors into a user-facing message.
+// Secrets (tokens, client secrets, cookies) are masked.
 func UserError(err error) string {
 	if err == nil {
 		return ""
@@ -56,7 +59,30 @@ func UserError(err error) string {
 		return "Aborted."
 	}
 	if errors.Is(err, os.ErrNotExist) {
-		return fmt.Sprintf("Not found: %v", err)
+		return logging.Redact(fmt.Sprintf("Not found: %v", err))
+	}
+	return logging.Redact(err.Error())
+}
+
+// RedactedError masks secrets in the message of the wrapped error while keeping it unwrappable.
+type RedactedError struct {
+	Err error
+}
+
+func (e *RedactedError) Error() string {
+	if e == nil || e.Err == nil {
+		return ""
+	}
+	return logging.Redact(e.Err.Error())
+}
+
+func (e *RedactedError) Unwrap() error { return e.Err }
+
+// Redacted wraps err so its message never exposes secrets. The CPI client uses it for
+// errors that embed HTTP response bodies.
+func Redacted(err error) error {
+	if err == nil {
+		return nil
 	}
-	return err.Error()
+	return &RedactedError{Err: err}
 }
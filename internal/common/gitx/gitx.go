@@ -1,6 +1,7 @@
 package gitx
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -51,3 +52,23 @@ func LookPath() error {
 	}
 	return nil
 }
+
+// OutputExit executes a git command and returns its combined output and exit code.
+// A non-zero exit code is not an error (e.g. `git diff --no-index` exits 1 when files differ);
+// err is only set when git could not be run.
+func OutputExit(lg *logx.Logger, dir string, args ...string) (string, int, error) {
+	if lg != nil {
+		lg.Debug("git", logx.F("args", strings.Join(args, " ")))
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return string(out), exitErr.ExitCode(), nil
+		}
+		return string(out), -1, fmt.Errorf("git %s failed: %w", strings.Join(args, " "), err)
+	}
+	return string(out), 0, nil
+}
@@ -12,4 +12,7 @@ var (
 	F          = logging.F
 	New        = logging.New
 	ParseLevel = logging.ParseLevel
+
+	Redact         = logging.Redact
+	RegisterSecret = logging.RegisterSecret
 )
@@ -4,6 +4,8 @@ const (
 	ProviderUnknown = "unknown"
 	ProviderGitHub  = "github"
 	ProviderGitLab  = "gitlab"
+	// ProviderGitea covers Gitea and Forgejo (same API).
+	ProviderGitea = "gitea"
 )
 
 type RemoteInfo struct {
@@ -13,7 +15,7 @@ type RemoteInfo struct {
 }
 
 // ParseRemoteBase attempts to infer git provider/host/namespace from profile.gitServerUrl and profile.cpiPath.
-// It is best-effort and primarily supports GitHub/GitLab (incl. SSH remotes like git@host:group/subgroup).
+// It is best-effort and primarily supports GitHub/GitLab/Gitea (incl. SSH remotes like git@host:group/subgroup).
 func ParseRemoteBase(gitServerURL, cpiPath string) (RemoteInfo, error) {
 	return parseRemoteBase(gitServerURL, cpiPath)
 }
@@ -24,6 +26,8 @@ func NewProvider(provider string) Provider {
 		return &githubProvider{}
 	case ProviderGitLab:
 		return &gitlabProvider{}
+	case ProviderGitea:
+		return &giteaProvider{}
 	default:
 		return nil
 	}
@@ -0,0 +1,212 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// giteaProvider serves Gitea and Forgejo, which share the /api/v1 REST API.
+type giteaProvider struct{}
+
+func (p *giteaProvider) Name() string { return ProviderGitea }
+
+func (p *giteaProvider) NormalizeRepoDisplayName(name string) string {
+	// Like GitHub, the repository name is the URL path (packageId); displayName becomes the description.
+	return normalizeCommon(name, 255)
+}
+
+func (p *giteaProvider) CreateRepo(ctx context.Context, token string, host string, namespace string, repoPath string, displayName string, private bool) error {
+	owner := firstSegment(namespace)
+	if owner == "" {
+		return fmt.Errorf("unable to determine Gitea owner from namespace: %q", namespace)
+	}
+	apiBase := giteaAPIBase(host)
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	// The token's own user creates under /user/repos; any other owner must be an organization.
+	var me struct {
+		Login string `json:"login"`
+	}
+	if err := apiJSON(ctx, client, http.MethodGet, apiBase+"/user", giteaHeaders(token), nil, &me); err != nil {
+		return fmt.Errorf("gitea user lookup failed: %w", err)
+	}
+	urlStr := fmt.Sprintf("%s/orgs/%s/repos", apiBase, url.PathEscape(owner))
+	if strings.EqualFold(me.Login, owner) {
+		urlStr = apiBase + "/user/repos"
+	}
+
+	payload := map[string]any{
+		"name":        repoPath,
+		"private":     private,
+		"description": displayName,
+		"auto_init":   false,
+	}
+	if err := apiJSON(ctx, client, http.MethodPost, urlStr, giteaHeaders(token), payload, nil); err != nil {
+		// Gitea answers 409 Conflict when the repository already exists.
+		if isAPIStatus(err, http.StatusConflict) {
+			return nil
+		}
+		return fmt.Errorf("gitea repo create failed: %w", err)
+	}
+	return nil
+}
+
+func giteaAPIBase(host string) string {
+	if v := apiBaseOverride(); v != "" {
+		return v
+	}
+	return fmt.Sprintf("https://%s/api/v1", strings.ToLower(strings.TrimSpace(host)))
+}
+
+func giteaHeaders(token string) map[string]string {
+	return map[string]string{"Accept": "application/json", "Authorization": "token " + token}
+}
+
+// ProbeGitea reports whether host serves the Gitea/Forgejo API. It is used for self-hosted
+// remotes whose host name does not reveal the provider.
+func ProbeGitea(ctx context.Context, host string) bool {
+	client := &http.Client{Timeout: 5 * time.Second}
+	var v struct {
+		Version string `json:"version"`
+	}
+	if err := apiJSON(ctx, client, http.MethodGet, giteaAPIBase(host)+"/version", nil, nil, &v); err != nil {
+		return false
+	}
+	return v.Version != ""
+}
+
+type giteaPull struct {
+	Number         int    `json:"number"`
+	HTMLURL        string `json:"html_url"`
+	Title          string `json:"title"`
+	State          string `json:"state"` // open|closed
+	Merged         bool   `json:"merged"`
+	MergeCommitSHA string `json:"merge_commit_sha"`
+	User           struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Head struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"base"`
+}
+
+func (g giteaPull) toPullRequest() PullRequest {
+	pr := PullRequest{
+		Number:  g.Number,
+		URL:     g.HTMLURL,
+		Title:   g.Title,
+		State:   PullRequestOpen,
+		Author:  g.User.Login,
+		Head:    g.Head.Ref,
+		Base:    g.Base.Ref,
+		HeadSHA: g.Head.SHA,
+		BaseSHA: g.Base.SHA,
+	}
+	switch {
+	case g.Merged:
+		pr.State = PullRequestMerged
+		pr.MergeCommitSHA = g.MergeCommitSHA
+	case g.State == "closed":
+		pr.State = PullRequestClosed
+	}
+	return pr
+}
+
+func giteaPullsURL(host, namespace, repoPath string) (string, error) {
+	owner := firstSegment(namespace)
+	if owner == "" {
+		return "", fmt.Errorf("unable to determine Gitea owner from namespace: %q", namespace)
+	}
+	return fmt.Sprintf("%s/repos/%s/%s/pulls", giteaAPIBase(host), url.PathEscape(owner), url.PathEscape(repoPath)), nil
+}
+
+func (p *giteaProvider) CreatePullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, in PullRequestInput) (PullRequest, error) {
+	base, err := giteaPullsURL(host, namespace, repoPath)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	payload := map[string]any{"title": in.Title, "body": in.Body, "head": in.Head, "base": in.Base}
+	var out giteaPull
+	if err := apiJSON(ctx, client, http.MethodPost, base, giteaHeaders(token), payload, &out); err != nil {
+		return PullRequest{}, fmt.Errorf("gitea pull request create failed: %w", err)
+	}
+	return out.toPullRequest(), nil
+}
+
+func (p *giteaProvider) GetPullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, number int) (PullRequest, error) {
+	base, err := giteaPullsURL(host, namespace, repoPath)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	var out giteaPull
+	if err := apiJSON(ctx, client, http.MethodGet, fmt.Sprintf("%s/%d", base, number), giteaHeaders(token), nil, &out); err != nil {
+		return PullRequest{}, err
+	}
+	return out.toPullRequest(), nil
+}
+
+func (p *giteaProvider) PullRequestApprovals(ctx context.Context, token string, host string, namespace string, repoPath string, number int, headSHA string) (PullRequestApprovals, error) {
+	base, err := giteaPullsURL(host, namespace, repoPath)
+	if err != nil {
+		return PullRequestApprovals{}, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	var reviews []struct {
+		State     string `json:"state"` // APPROVED|REQUEST_CHANGES|COMMENT|PENDING|REQUEST_REVIEW
+		Dismissed bool   `json:"dismissed"`
+		Stale     bool   `json:"stale"` // commits were pushed after the review
+		CommitID  string `json:"commit_id"`
+		User      struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+	if err := apiJSON(ctx, client, http.MethodGet, fmt.Sprintf("%s/%d/reviews", base, number), giteaHeaders(token), nil, &reviews); err != nil {
+		return PullRequestApprovals{}, err
+	}
+
+	// Same rule as GitHub: the latest non-dismissed verdict of each reviewer counts, and an
+	// approval that is stale or was given on another commit than the head does not.
+	latest := map[string]string{}
+	var order []string
+	for _, r := range reviews {
+		state := r.State
+		switch {
+		case r.Dismissed:
+			state = "DISMISSED"
+		case state == "APPROVED" && (r.Stale || r.CommitID != headSHA):
+			state = "OUTDATED"
+		}
+		switch r.State {
+		case "APPROVED", "REQUEST_CHANGES":
+			if _, seen := latest[r.User.Login]; !seen {
+				order = append(order, r.User.Login)
+			}
+			latest[r.User.Login] = state
+		}
+	}
+	return tallyReviews(order, latest, "REQUEST_CHANGES"), nil
+}
+
+func (p *giteaProvider) MergePullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, number int, headSHA string, message string) error {
+	base, err := giteaPullsURL(host, namespace, repoPath)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	payload := map[string]any{"Do": "merge", "MergeTitleField": message, "head_commit_id": headSHA}
+	if err := apiJSON(ctx, client, http.MethodPost, fmt.Sprintf("%s/%d/merge", base, number), giteaHeaders(token), payload, nil); err != nil {
+		return fmt.Errorf("gitea pull request merge failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// giteaFake is a minimal Gitea/Forgejo API: /version, /user, repo creation and pulls.
+type giteaFake struct {
+	t     *testing.T
+	login string
+
+	mu       sync.Mutex
+	existing map[string]bool // "<owner>/<repo>"
+	creates  []giteaCreate
+	merges   []map[string]any
+	reviews  []map[string]any
+	pull     map[string]any
+	head     string // when set, merges pinned to another head_commit_id are refused
+}
+
+type giteaCreate struct {
+	Path    string
+	Payload map[string]any
+}
+
+func newGiteaFake(t *testing.T, login string) *giteaFake {
+	f := &giteaFake{t: t, login: login, existing: map[string]bool{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	t.Setenv(APIBaseEnv, srv.URL+"/api/v1")
+	return f
+}
+
+func (f *giteaFake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.URL.Path == "/api/v1/version" {
+		writeJSON(w, http.StatusOK, map[string]any{"version": "1.22.0"})
+		return
+	}
+	if r.Header.Get("Authorization") != "token tok" {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"message": "token is required"})
+		return
+	}
+	var payload map[string]any
+	if b, _ := io.ReadAll(r.Body); len(b) > 0 {
+		if err := json.Unmarshal(b, &payload); err != nil {
+			f.t.Errorf("invalid JSON body: %v", err)
+		}
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v1/user":
+		writeJSON(w, http.StatusOK, map[string]any{"login": f.login})
+	case r.Method == http.MethodPost && (r.URL.Path == "/api/v1/user/repos" || r.URL.Path == "/api/v1/orgs/acme/repos"):
+		f.creates = append(f.creates, giteaCreate{Path: r.URL.Path, Payload: payload})
+		owner := "acme"
+		if r.URL.Path == "/api/v1/user/repos" {
+			owner = f.login
+		}
+		key := owner + "/" + payload["name"].(string)
+		if f.existing[key] {
+			writeJSON(w, http.StatusConflict, map[string]any{"message": "The repository with the same name already exists."})
+			return
+		}
+		f.existing[key] = true
+		writeJSON(w, http.StatusCreated, map[string]any{"name": payload["name"]})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/orgs/o409/repos":
+		// A real failure whose owner and body contain "409" must not pass as "already exists".
+		writeJSON(w, http.StatusForbidden, map[string]any{"message": "user 409 is not allowed"})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/repos/acme/pkg/pulls":
+		pull := map[string]any{
+			"number": 7, "html_url": "https://gitea.example/acme/pkg/pulls/7", "title": payload["title"], "state": "open",
+			"user": map[string]any{"login": f.login},
+			"head": map[string]any{"ref": payload["head"], "sha": "h1"},
+			"base": map[string]any{"ref": payload["base"], "sha": "b1"},
+		}
+		writeJSON(w, http.StatusCreated, pull)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v1/repos/acme/pkg/pulls/7":
+		writeJSON(w, http.StatusOK, f.pull)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v1/repos/acme/pkg/pulls/7/reviews":
+		writeJSON(w, http.StatusOK, f.reviews)
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/repos/acme/pkg/pulls/7/merge":
+		f.merges = append(f.merges, payload)
+		if f.head != "" && payload["head_commit_id"] != f.head {
+			writeJSON(w, http.StatusConflict, map[string]any{"message": "head out of date"})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]any{"message": "not found: " + r.Method + " " + r.URL.Path})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestGiteaCreateRepoUserVsOrg(t *testing.T) {
+	f := newGiteaFake(t, "jdoe")
+	p := NewProvider(ProviderGitea)
+	ctx := context.Background()
+
+	if err := p.CreateRepo(ctx, "tok", "git.example", "jdoe", "pkg", "My Pkg", true); err != nil {
+		t.Fatalf("user repo: %v", err)
+	}
+	if err := p.CreateRepo(ctx, "tok", "git.example", "acme/sub", "pkg", "Pkg", false); err != nil {
+		t.Fatalf("org repo: %v", err)
+	}
+	if len(f.creates) != 2 {
+		t.Fatalf("creates = %d, want 2", len(f.creates))
+	}
+	if got := f.creates[0].Path; got != "/api/v1/user/repos" {
+		t.Errorf("owner == token user: path = %s, want /api/v1/user/repos", got)
+	}
+	if got := f.creates[1].Path; got != "/api/v1/orgs/acme/repos" {
+		t.Errorf("other owner: path = %s, want /api/v1/orgs/acme/repos", got)
+	}
+	if got := f.creates[0].Payload["private"]; got != true {
+		t.Errorf("private = %v, want true", got)
+	}
+	if got := f.creates[1].Payload["private"]; got != false {
+		t.Errorf("private = %v, want false", got)
+	}
+	if got := f.creates[0].Payload["description"]; got != "My Pkg" {
+		t.Errorf("description = %v, want display name", got)
+	}
+	if got := f.creates[0].Payload["auto_init"]; got != false {
+		t.Errorf("auto_init = %v, want false", got)
+	}
+}
+
+func TestGiteaCreateRepoConflict(t *testing.T) {
+	newGiteaFake(t, "jdoe")
+	p := NewProvider(ProviderGitea)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := p.CreateRepo(ctx, "tok", "git.example", "acme", "pkg", "Pkg", true); err != nil {
+			t.Fatalf("create #%d: %v (409 must count as success)", i+1, err)
+		}
+	}
+	if err := p.CreateRepo(ctx, "tok", "git.example", "o409", "pkg", "Pkg", true); err == nil {
+		t.Fatal("403 with \"409\" in URL and body: want error")
+	}
+	if err := p.CreateRepo(ctx, "bad", "git.example", "acme", "pkg2", "Pkg", true); !isAPIStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("bad token: err = %v, want 401 APIError", err)
+	}
+}
+
+func TestProbeGitea(t *testing.T) {
+	newGiteaFake(t, "jdoe")
+	if !ProbeGitea(context.Background(), "git.example") {
+		t.Error("ProbeGitea = false on a Gitea API")
+	}
+
+	other := httptest.NewServer(http.NotFoundHandler())
+	defer other.Close()
+	t.Setenv(APIBaseEnv, other.URL)
+	if ProbeGitea(context.Background(), "git.example") {
+		t.Error("ProbeGitea = true on a non-Gitea server")
+	}
+}
+
+func TestGiteaPullRequests(t *testing.T) {
+	f := newGiteaFake(t, "jdoe")
+	p := NewProvider(ProviderGitea)
+	ctx := context.Background()
+
+	pr, err := p.CreatePullRequest(ctx, "tok", "git.example", "acme", "pkg", PullRequestInput{Title: "t", Body: "b", Head: "dev", Base: "qas"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	want := PullRequest{Number: 7, URL: "https://gitea.example/acme/pkg/pulls/7", Title: "t", State: PullRequestOpen, Author: "jdoe", Head: "dev", Base: "qas", HeadSHA: "h1", BaseSHA: "b1"}
+	if pr != want {
+		t.Errorf("create = %+v, want %+v", pr, want)
+	}
+
+	f.pull = map[string]any{"number": 7, "state": "closed", "merged": true, "merge_commit_sha": "m1", "base": map[string]any{"ref": "qas"}}
+	if pr, err = p.GetPullRequest(ctx, "tok", "git.example", "acme", "pkg", 7); err != nil || pr.State != PullRequestMerged || pr.MergeCommitSHA != "m1" {
+		t.Errorf("get merged = %+v, %v", pr, err)
+	}
+	f.pull = map[string]any{"number": 7, "state": "closed", "merged": false}
+	if pr, err = p.GetPullRequest(ctx, "tok", "git.example", "acme", "pkg", 7); err != nil || pr.State != PullRequestClosed {
+		t.Errorf("get closed = %+v, %v", pr, err)
+	}
+
+	if err := p.MergePullRequest(ctx, "tok", "git.example", "acme", "pkg", 7, "h1", "msg"); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if len(f.merges) != 1 || f.merges[0]["Do"] != "merge" || f.merges[0]["MergeTitleField"] != "msg" || f.merges[0]["head_commit_id"] != "h1" {
+		t.Errorf("merge payload = %v", f.merges)
+	}
+}
+
+func TestGiteaPullRequestApprovals(t *testing.T) {
+	f := newGiteaFake(t, "jdoe")
+	p := NewProvider(ProviderGitea)
+	reviewOn := func(login, state, commit string, stale bool) map[string]any {
+		return map[string]any{"state": state, "stale": stale, "commit_id": commit, "user": map[string]any{"login": login}}
+	}
+	review := func(login, state string, dismissed bool) map[string]any {
+		r := reviewOn(login, state, "h1", false)
+		r["dismissed"] = dismissed
+		return r
+	}
+	cases := []struct {
+		name    string
+		reviews []map[string]any
+		want    PullRequestApprovals
+	}{
+		{"none", nil, PullRequestApprovals{}},
+		{"approved", []map[string]any{review("a", "APPROVED", false), review("b", "COMMENT", false)}, PullRequestApprovals{Approved: true, ApprovedBy: []string{"a"}}},
+		{"changes requested blocks", []map[string]any{review("a", "APPROVED", false), review("b", "REQUEST_CHANGES", false)}, PullRequestApprovals{ApprovedBy: []string{"a"}}},
+		{"latest review wins", []map[string]any{review("a", "REQUEST_CHANGES", false), review("a", "APPROVED", false)}, PullRequestApprovals{Approved: true, ApprovedBy: []string{"a"}}},
+		{"dismissed clears", []map[string]any{review("a", "APPROVED", true), review("b", "REQUEST_CHANGES", true)}, PullRequestApprovals{}},
+		{"stale approval", []map[string]any{reviewOn("a", "APPROVED", "h1", true)}, PullRequestApprovals{Outdated: []string{"a"}}},
+		{"approval of an earlier head", []map[string]any{reviewOn("a", "APPROVED", "h0", false)}, PullRequestApprovals{Outdated: []string{"a"}}},
+		{"approval renewed on head", []map[string]any{reviewOn("a", "APPROVED", "h0", true), review("a", "APPROVED", false)}, PullRequestApprovals{Approved: true, ApprovedBy: []string{"a"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f.reviews = tc.reviews
+			got, err := p.PullRequestApprovals(context.Background(), "tok", "git.example", "acme", "pkg", 7, "h1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGiteaHeadMovedAfterApproval(t *testing.T) {
+	f := newGiteaFake(t, "jdoe")
+	p := NewProvider(ProviderGitea)
+	ctx := context.Background()
+	f.head = "h2"
+	f.reviews = []map[string]any{{"state": "APPROVED", "stale": true, "commit_id": "h1", "user": map[string]any{"login": "lead"}}}
+
+	a, err := p.PullRequestApprovals(ctx, "tok", "git.example", "acme", "pkg", 7, "h2")
+	if err != nil || a.Approved || !reflect.DeepEqual(a.Outdated, []string{"lead"}) {
+		t.Errorf("approvals = %+v, %v", a, err)
+	}
+	if err := p.MergePullRequest(ctx, "tok", "git.example", "acme", "pkg", 7, "h1", "msg"); !isAPIStatus(err, http.StatusConflict) {
+		t.Errorf("merge of approved head h1 = %v, want 409", err)
+	}
+}
+
+func TestResolveTokenGitea(t *testing.T) {
+	for _, k := range []string{"IFLOWKIT_GIT_TOKEN", "GITEA_TOKEN", "FORGEJO_TOKEN", "GITHUB_TOKEN", "GH_TOKEN"} {
+		t.Setenv(k, "")
+	}
+	if _, err := ResolveToken(ProviderGitea); err == nil {
+		t.Fatal("no token set: want error")
+	}
+
+	t.Setenv("FORGEJO_TOKEN", "fj")
+	if tok, _ := ResolveToken(ProviderGitea); tok != "fj" {
+		t.Errorf("FORGEJO_TOKEN: got %q", tok)
+	}
+	t.Setenv("GITEA_TOKEN", "gt")
+	if tok, _ := ResolveToken(ProviderGitea); tok != "gt" {
+		t.Errorf("GITEA_TOKEN before FORGEJO_TOKEN: got %q", tok)
+	}
+	t.Setenv("IFLOWKIT_GIT_TOKEN", "ifk")
+	if tok, _ := ResolveToken(ProviderGitea); tok != "ifk" {
+		t.Errorf("IFLOWKIT_GIT_TOKEN first: got %q", tok)
+	}
+
+	t.Setenv("IFLOWKIT_GIT_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "gh")
+	if tok, _ := ResolveToken(ProviderGitHub); tok != "gh" {
+		t.Errorf("GitHub must not use Gitea vars: got %q", tok)
+	}
+}
+
+func TestDetectProviderGitea(t *testing.T) {
+	for host, want := range map[string]string{
+		"gitea.example.com": ProviderGitea,
+		"forgejo.corp":      ProviderGitea,
+		"codeberg.org":      ProviderGitea,
+		"git.corp.example":  ProviderUnknown,
+	} {
+		if got := detectProvider(host); got != want {
+			t.Errorf("detectProvider(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
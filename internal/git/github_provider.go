@@ -61,6 +61,9 @@ func (p *githubProvider) CreateRepo(ctx context.Context, token string, host stri
 }
 
 func githubAPIBase(host string) string {
+	if v := apiBaseOverride(); v != "" {
+		return v
+	}
 	h := strings.ToLower(strings.TrimSpace(host))
 	if h == "" {
 		return "https://api.github.com"
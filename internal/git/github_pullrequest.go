@@ -0,0 +1,140 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type githubPull struct {
+	Number         int     `json:"number"`
+	HTMLURL        string  `json:"html_url"`
+	Title          string  `json:"title"`
+	State          string  `json:"state"` // open|closed
+	MergedAt       *string `json:"merged_at"`
+	MergeCommitSHA string  `json:"merge_commit_sha"`
+	User           struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Head struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"base"`
+}
+
+func (g githubPull) toPullRequest() PullRequest {
+	pr := PullRequest{
+		Number:  g.Number,
+		URL:     g.HTMLURL,
+		Title:   g.Title,
+		State:   PullRequestOpen,
+		Author:  g.User.Login,
+		Head:    g.Head.Ref,
+		Base:    g.Base.Ref,
+		HeadSHA: g.Head.SHA,
+		BaseSHA: g.Base.SHA,
+	}
+	switch {
+	case g.MergedAt != nil && *g.MergedAt != "":
+		pr.State = PullRequestMerged
+		pr.MergeCommitSHA = g.MergeCommitSHA
+	case g.State == "closed":
+		pr.State = PullRequestClosed
+	}
+	return pr
+}
+
+func githubPullsURL(host, namespace, repoPath string) (string, error) {
+	owner := firstSegment(namespace)
+	if owner == "" {
+		return "", fmt.Errorf("unable to determine GitHub owner from namespace: %q", namespace)
+	}
+	return fmt.Sprintf("%s/repos/%s/%s/pulls", githubAPIBase(host), url.PathEscape(owner), url.PathEscape(repoPath)), nil
+}
+
+func githubHeaders(token string) map[string]string {
+	return map[string]string{"Accept": "application/vnd.github+json", "Authorization": "Bearer " + token}
+}
+
+func (p *githubProvider) CreatePullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, in PullRequestInput) (PullRequest, error) {
+	base, err := githubPullsURL(host, namespace, repoPath)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	payload := map[string]any{"title": in.Title, "body": in.Body, "head": in.Head, "base": in.Base}
+	var out githubPull
+	if err := apiJSON(ctx, client, http.MethodPost, base, githubHeaders(token), payload, &out); err != nil {
+		return PullRequest{}, fmt.Errorf("github pull request create failed: %w", err)
+	}
+	return out.toPullRequest(), nil
+}
+
+func (p *githubProvider) GetPullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, number int) (PullRequest, error) {
+	base, err := githubPullsURL(host, namespace, repoPath)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	var out githubPull
+	if err := apiJSON(ctx, client, http.MethodGet, fmt.Sprintf("%s/%d", base, number), githubHeaders(token), nil, &out); err != nil {
+		return PullRequest{}, err
+	}
+	return out.toPullRequest(), nil
+}
+
+func (p *githubProvider) PullRequestApprovals(ctx context.Context, token string, host string, namespace string, repoPath string, number int, headSHA string) (PullRequestApprovals, error) {
+	base, err := githubPullsURL(host, namespace, repoPath)
+	if err != nil {
+		return PullRequestApprovals{}, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	var reviews []struct {
+		State    string `json:"state"` // APPROVED|CHANGES_REQUESTED|COMMENTED|DISMISSED|PENDING
+		CommitID string `json:"commit_id"`
+		User     struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+	if err := apiJSON(ctx, client, http.MethodGet, fmt.Sprintf("%s/%d/reviews?per_page=100", base, number), githubHeaders(token), nil, &reviews); err != nil {
+		return PullRequestApprovals{}, err
+	}
+
+	// The latest approving or change-requesting review of each reviewer counts; comments do not
+	// change it and a dismissal clears it. An approval only counts for the commit it was given on.
+	latest := map[string]string{}
+	var order []string
+	for _, r := range reviews {
+		state := r.State
+		if state == "APPROVED" && r.CommitID != headSHA {
+			state = "OUTDATED"
+		}
+		switch r.State {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			if _, seen := latest[r.User.Login]; !seen {
+				order = append(order, r.User.Login)
+			}
+			latest[r.User.Login] = state
+		}
+	}
+	return tallyReviews(order, latest, "CHANGES_REQUESTED"), nil
+}
+
+func (p *githubProvider) MergePullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, number int, headSHA string, message string) error {
+	base, err := githubPullsURL(host, namespace, repoPath)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	payload := map[string]any{"merge_method": "merge", "commit_title": message, "sha": headSHA}
+	if err := apiJSON(ctx, client, http.MethodPut, fmt.Sprintf("%s/%d/merge", base, number), githubHeaders(token), payload, nil); err != nil {
+		return fmt.Errorf("github pull request merge failed: %w", err)
+	}
+	return nil
+}
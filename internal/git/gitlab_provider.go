@@ -70,6 +70,9 @@ func (p *gitlabProvider) CreateRepo(ctx context.Context, token string, host stri
 }
 
 func gitlabAPIBase(host string) string {
+	if v := apiBaseOverride(); v != "" {
+		return v
+	}
 	h := strings.ToLower(strings.TrimSpace(host))
 	if h == "" {
 		return "https://gitlab.com/api/v4"
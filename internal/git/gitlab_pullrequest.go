@@ -0,0 +1,114 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type gitlabMergeRequest struct {
+	IID             int    `json:"iid"`
+	WebURL          string `json:"web_url"`
+	Title           string `json:"title"`
+	State           string `json:"state"` // opened|closed|locked|merged
+	SourceBranch    string `json:"source_branch"`
+	TargetBranch    string `json:"target_branch"`
+	SHA             string `json:"sha"`
+	MergeCommitSHA  string `json:"merge_commit_sha"`
+	SquashCommitSHA string `json:"squash_commit_sha"`
+	Author          struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	DiffRefs struct {
+		BaseSHA string `json:"base_sha"`
+	} `json:"diff_refs"`
+}
+
+func (m gitlabMergeRequest) toPullRequest() PullRequest {
+	pr := PullRequest{
+		Number:  m.IID,
+		URL:     m.WebURL,
+		Title:   m.Title,
+		State:   PullRequestOpen,
+		Author:  m.Author.Username,
+		Head:    m.SourceBranch,
+		Base:    m.TargetBranch,
+		HeadSHA: m.SHA,
+		BaseSHA: m.DiffRefs.BaseSHA,
+	}
+	switch m.State {
+	case "merged":
+		pr.State = PullRequestMerged
+		pr.MergeCommitSHA = m.MergeCommitSHA
+		if pr.MergeCommitSHA == "" {
+			// Squash merges without a merge commit (fast-forward projects).
+			pr.MergeCommitSHA = m.SquashCommitSHA
+		}
+	case "closed", "locked":
+		pr.State = PullRequestClosed
+	}
+	return pr
+}
+
+// gitlabMergeRequestsURL addresses the project by its URL-encoded full path.
+func gitlabMergeRequestsURL(host, namespace, repoPath string) string {
+	project := strings.Trim(namespace, "/") + "/" + repoPath
+	return fmt.Sprintf("%s/projects/%s/merge_requests", gitlabAPIBase(host), url.PathEscape(project))
+}
+
+func gitlabHeaders(token string) map[string]string {
+	return map[string]string{"Accept": "application/json", "PRIVATE-TOKEN": token}
+}
+
+func (p *gitlabProvider) CreatePullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, in PullRequestInput) (PullRequest, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	payload := map[string]any{"title": in.Title, "description": in.Body, "source_branch": in.Head, "target_branch": in.Base}
+	var out gitlabMergeRequest
+	if err := apiJSON(ctx, client, http.MethodPost, gitlabMergeRequestsURL(host, namespace, repoPath), gitlabHeaders(token), payload, &out); err != nil {
+		return PullRequest{}, fmt.Errorf("gitlab merge request create failed: %w", err)
+	}
+	return out.toPullRequest(), nil
+}
+
+func (p *gitlabProvider) GetPullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, number int) (PullRequest, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	var out gitlabMergeRequest
+	if err := apiJSON(ctx, client, http.MethodGet, fmt.Sprintf("%s/%d", gitlabMergeRequestsURL(host, namespace, repoPath), number), gitlabHeaders(token), nil, &out); err != nil {
+		return PullRequest{}, err
+	}
+	return out.toPullRequest(), nil
+}
+
+// PullRequestApprovals reports GitLab's verdict; whether a push resets approvals is a project
+// setting, so MergePullRequest pins headSHA as well.
+func (p *gitlabProvider) PullRequestApprovals(ctx context.Context, token string, host string, namespace string, repoPath string, number int, headSHA string) (PullRequestApprovals, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	var out struct {
+		Approved   bool `json:"approved"`
+		ApprovedBy []struct {
+			User struct {
+				Username string `json:"username"`
+			} `json:"user"`
+		} `json:"approved_by"`
+	}
+	if err := apiJSON(ctx, client, http.MethodGet, fmt.Sprintf("%s/%d/approvals", gitlabMergeRequestsURL(host, namespace, repoPath), number), gitlabHeaders(token), nil, &out); err != nil {
+		return PullRequestApprovals{}, err
+	}
+	a := PullRequestApprovals{Approved: out.Approved}
+	for _, u := range out.ApprovedBy {
+		a.ApprovedBy = append(a.ApprovedBy, u.User.Username)
+	}
+	return a, nil
+}
+
+func (p *gitlabProvider) MergePullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, number int, headSHA string, message string) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	payload := map[string]any{"merge_commit_message": message, "squash": false, "sha": headSHA}
+	if err := apiJSON(ctx, client, http.MethodPut, fmt.Sprintf("%s/%d/merge", gitlabMergeRequestsURL(host, namespace, repoPath), number), gitlabHeaders(token), payload, nil); err != nil {
+		return fmt.Errorf("gitlab merge request merge failed: %w", err)
+	}
+	return nil
+}
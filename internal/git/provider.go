@@ -2,15 +2,24 @@ package git
 
 import "context"
 
-// Provider performs provider-specific operations such as repository creation
-// and display-name normalization.
+// Provider performs provider-specific operations such as repository creation,
+// display-name normalization and pull/merge requests.
 //
 // host is the remote host (e.g. github.com).
 // repoPath is the URL/path segment used in remote URLs (e.g. repo name).
 // displayName is a human-friendly name derived from CPI package Name.
 // namespace is the owner or group path extracted from the remote URL.
+// number is the pull request number (GitHub) or merge request iid (GitLab).
 type Provider interface {
 	Name() string
 	NormalizeRepoDisplayName(name string) string
 	CreateRepo(ctx context.Context, token string, host string, namespace string, repoPath string, displayName string, private bool) error
+
+	CreatePullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, in PullRequestInput) (PullRequest, error)
+	GetPullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, number int) (PullRequest, error)
+	// PullRequestApprovals only counts approvals given on headSHA.
+	PullRequestApprovals(ctx context.Context, token string, host string, namespace string, repoPath string, number int, headSHA string) (PullRequestApprovals, error)
+	// MergePullRequest merges with a merge commit using message as its commit message.
+	// The provider refuses the merge when the head is no longer headSHA.
+	MergePullRequest(ctx context.Context, token string, host string, namespace string, repoPath string, number int, headSHA string, message string) error
 }
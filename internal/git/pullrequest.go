@@ -0,0 +1,144 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// APIBaseEnv overrides the REST API base URL of the provider (e.g. a local fake server in tests
+// or a self-hosted instance behind a non-standard path).
+const APIBaseEnv = "IFLOWKIT_GIT_API_URL"
+
+// Pull request states, normalized across providers.
+const (
+	PullRequestOpen   = "open"
+	PullRequestMerged = "merged"
+	PullRequestClosed = "closed"
+)
+
+// PullRequestInput describes a pull/merge request from Head into Base.
+type PullRequestInput struct {
+	Title string
+	Body  string
+	Head  string
+	Base  string
+}
+
+// PullRequest is a GitHub pull request or GitLab merge request.
+type PullRequest struct {
+	Number int
+	URL    string
+	Title  string
+	State  string // open|merged|closed
+	Author string
+	Head   string
+	Base   string
+	// HeadSHA is the tip of Head; BaseSHA is the Base commit the request is compared against.
+	HeadSHA string
+	BaseSHA string
+	// MergeCommitSHA is set once the request is merged.
+	MergeCommitSHA string
+}
+
+// PullRequestApprovals summarizes the reviews of a pull request.
+type PullRequestApprovals struct {
+	// Approved is the provider's verdict: GitHub needs an approval and no outstanding change
+	// request; GitLab applies the project's approval rules.
+	Approved   bool
+	ApprovedBy []string
+	// Outdated lists reviewers whose approval was given on an earlier head commit.
+	Outdated []string
+}
+
+// tallyReviews turns the latest verdict of each reviewer (in order of first review) into
+// approvals: APPROVED approves, OUTDATED is an approval of an earlier head, changesState blocks.
+func tallyReviews(order []string, latest map[string]string, changesState string) PullRequestApprovals {
+	a := PullRequestApprovals{}
+	changesRequested := false
+	for _, login := range order {
+		switch latest[login] {
+		case "APPROVED":
+			a.ApprovedBy = append(a.ApprovedBy, login)
+		case "OUTDATED":
+			a.Outdated = append(a.Outdated, login)
+		case changesState:
+			changesRequested = true
+		}
+	}
+	a.Approved = len(a.ApprovedBy) > 0 && !changesRequested
+	return a
+}
+
+// APIError is a non-2xx response of a provider REST API.
+type APIError struct {
+	Method     string
+	URL        string
+	Status     string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s failed (%s): %s", e.Method, e.URL, e.Status, e.Message)
+}
+
+// isAPIStatus reports whether err is (or wraps) an APIError with the given status code.
+func isAPIStatus(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == code
+}
+
+// apiBaseOverride returns the APIBaseEnv value without a trailing slash, or "".
+func apiBaseOverride() string {
+	return strings.TrimRight(strings.TrimSpace(os.Getenv(APIBaseEnv)), "/")
+}
+
+// apiJSON sends payload (if any) as JSON and decodes a 2xx response into out (if any).
+func apiJSON(ctx context.Context, client *http.Client, method, urlStr string, headers map[string]string, payload any, out any) error {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "iflowkit-cli")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(string(b))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return &APIError{Method: method, URL: urlStr, Status: resp.Status, StatusCode: resp.StatusCode, Message: msg}
+	}
+	if out == nil || len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("%s %s: invalid response: %w", method, urlStr, err)
+	}
+	return nil
+}
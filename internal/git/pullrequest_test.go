@@ -0,0 +1,251 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// apiCall is one request received by a fake provider API.
+type apiCall struct {
+	Method  string
+	Path    string // escaped path, e.g. /projects/acme%2Fpkg/merge_requests
+	Header  http.Header
+	Payload map[string]any
+}
+
+// fakeAPI serves routes ("METHOD escaped-path") with canned JSON and records every request.
+// A route may also be a func answering from the decoded request payload.
+func fakeAPI(t *testing.T, routes map[string]any) *[]apiCall {
+	t.Helper()
+	calls := &[]apiCall{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := apiCall{Method: r.Method, Path: r.URL.EscapedPath(), Header: r.Header}
+		if b, _ := io.ReadAll(r.Body); len(b) > 0 {
+			if err := json.Unmarshal(b, &c.Payload); err != nil {
+				t.Errorf("invalid JSON body: %v", err)
+			}
+		}
+		*calls = append(*calls, c)
+		resp, ok := routes[r.Method+" "+c.Path]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]any{"message": "Not Found"})
+			return
+		}
+		if h, ok := resp.(func(payload map[string]any) (int, any)); ok {
+			code, body := h(c.Payload)
+			writeJSON(w, code, body)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv(APIBaseEnv, srv.URL)
+	return calls
+}
+
+func TestGitHubPullRequests(t *testing.T) {
+	merged := "2024-05-01T10:00:00Z"
+	calls := fakeAPI(t, map[string]any{
+		"POST /repos/acme/pkg/pulls": map[string]any{
+			"number": 12, "html_url": "https://github.com/acme/pkg/pull/12", "title": "t", "state": "open",
+			"user": map[string]any{"login": "dev1"},
+			"head": map[string]any{"ref": "dev", "sha": "h1"},
+			"base": map[string]any{"ref": "qas", "sha": "b1"},
+		},
+		"GET /repos/acme/pkg/pulls/12": map[string]any{
+			"number": 12, "state": "closed", "merged_at": merged, "merge_commit_sha": "m1",
+			"base": map[string]any{"ref": "qas", "sha": "b1"},
+		},
+		"GET /repos/acme/pkg/pulls/13":       map[string]any{"number": 13, "state": "closed", "merged_at": nil},
+		"PUT /repos/acme/pkg/pulls/12/merge": map[string]any{"merged": true},
+	})
+	p := NewProvider(ProviderGitHub)
+	ctx := context.Background()
+
+	pr, err := p.CreatePullRequest(ctx, "tok", "github.com", "acme/sub", "pkg", PullRequestInput{Title: "t", Body: "b", Head: "dev", Base: "qas"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	want := PullRequest{Number: 12, URL: "https://github.com/acme/pkg/pull/12", Title: "t", State: PullRequestOpen, Author: "dev1", Head: "dev", Base: "qas", HeadSHA: "h1", BaseSHA: "b1"}
+	if pr != want {
+		t.Errorf("create = %+v, want %+v", pr, want)
+	}
+	c := (*calls)[0]
+	if c.Header.Get("Authorization") != "Bearer tok" || c.Payload["head"] != "dev" || c.Payload["base"] != "qas" || c.Payload["body"] != "b" {
+		t.Errorf("create request = %+v", c)
+	}
+
+	if pr, err = p.GetPullRequest(ctx, "tok", "github.com", "acme", "pkg", 12); err != nil || pr.State != PullRequestMerged || pr.MergeCommitSHA != "m1" {
+		t.Errorf("get merged = %+v, %v", pr, err)
+	}
+	if pr, err = p.GetPullRequest(ctx, "tok", "github.com", "acme", "pkg", 13); err != nil || pr.State != PullRequestClosed || pr.MergeCommitSHA != "" {
+		t.Errorf("get closed = %+v, %v", pr, err)
+	}
+	if _, err = p.GetPullRequest(ctx, "tok", "github.com", "acme", "pkg", 99); !isAPIStatus(err, http.StatusNotFound) {
+		t.Errorf("get missing: err = %v, want 404 APIError", err)
+	}
+
+	if err := p.MergePullRequest(ctx, "tok", "github.com", "acme", "pkg", 12, "h1", "msg"); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	c = (*calls)[len(*calls)-1]
+	if c.Payload["merge_method"] != "merge" || c.Payload["commit_title"] != "msg" || c.Payload["sha"] != "h1" {
+		t.Errorf("merge payload = %v", c.Payload)
+	}
+}
+
+func TestGitHubPullRequestApprovals(t *testing.T) {
+	reviewOn := func(login, state, commit string) map[string]any {
+		return map[string]any{"state": state, "commit_id": commit, "user": map[string]any{"login": login}}
+	}
+	review := func(login, state string) map[string]any { return reviewOn(login, state, "h2") }
+	cases := []struct {
+		name    string
+		reviews []map[string]any
+		want    PullRequestApprovals
+	}{
+		{"none", []map[string]any{}, PullRequestApprovals{}},
+		{"comment only", []map[string]any{review("a", "COMMENTED")}, PullRequestApprovals{}},
+		{"approved", []map[string]any{review("a", "APPROVED"), review("b", "COMMENTED")}, PullRequestApprovals{Approved: true, ApprovedBy: []string{"a"}}},
+		{"changes requested blocks", []map[string]any{review("a", "APPROVED"), review("b", "CHANGES_REQUESTED")}, PullRequestApprovals{ApprovedBy: []string{"a"}}},
+		{"latest review per reviewer", []map[string]any{review("a", "CHANGES_REQUESTED"), review("a", "APPROVED"), review("b", "APPROVED"), review("b", "COMMENTED")}, PullRequestApprovals{Approved: true, ApprovedBy: []string{"a", "b"}}},
+		{"approval then change request", []map[string]any{review("a", "APPROVED"), review("a", "CHANGES_REQUESTED")}, PullRequestApprovals{}},
+		{"dismissed clears approval", []map[string]any{review("a", "APPROVED"), review("a", "DISMISSED")}, PullRequestApprovals{}},
+		{"dismissed clears change request", []map[string]any{review("a", "APPROVED"), review("b", "CHANGES_REQUESTED"), review("b", "DISMISSED")}, PullRequestApprovals{Approved: true, ApprovedBy: []string{"a"}}},
+		{"approval of an earlier head", []map[string]any{reviewOn("a", "APPROVED", "h1")}, PullRequestApprovals{Outdated: []string{"a"}}},
+		{"approval renewed on head", []map[string]any{reviewOn("a", "APPROVED", "h1"), review("a", "APPROVED")}, PullRequestApprovals{Approved: true, ApprovedBy: []string{"a"}}},
+		{"change request on an earlier head still blocks", []map[string]any{review("a", "APPROVED"), reviewOn("b", "CHANGES_REQUESTED", "h1")}, PullRequestApprovals{ApprovedBy: []string{"a"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := fakeAPI(t, map[string]any{"GET /repos/acme/pkg/pulls/12/reviews": tc.reviews})
+			got, err := NewProvider(ProviderGitHub).PullRequestApprovals(context.Background(), "tok", "github.com", "acme", "pkg", 12, "h2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+			if len(*calls) != 1 {
+				t.Errorf("calls = %d, want 1", len(*calls))
+			}
+		})
+	}
+}
+
+func TestGitLabMergeRequests(t *testing.T) {
+	const mrs = "/projects/acme%2Fsub%2Fpkg/merge_requests"
+	calls := fakeAPI(t, map[string]any{
+		"POST " + mrs: map[string]any{
+			"iid": 4, "web_url": "https://gitlab.example/acme/sub/pkg/-/merge_requests/4", "title": "t", "state": "opened",
+			"source_branch": "dev", "target_branch": "qas", "sha": "h1",
+			"author":    map[string]any{"username": "dev1"},
+			"diff_refs": map[string]any{"base_sha": "b1"},
+		},
+		"GET " + mrs + "/4":       map[string]any{"iid": 4, "state": "merged", "merge_commit_sha": "m1", "target_branch": "qas"},
+		"GET " + mrs + "/5":       map[string]any{"iid": 5, "state": "merged", "merge_commit_sha": nil, "squash_commit_sha": "s1"},
+		"GET " + mrs + "/6":       map[string]any{"iid": 6, "state": "locked"},
+		"PUT " + mrs + "/4/merge": map[string]any{"state": "merged"},
+		"GET " + mrs + "/4/approvals": map[string]any{
+			"approved":    true,
+			"approved_by": []map[string]any{{"user": map[string]any{"username": "lead"}}, {"user": map[string]any{"username": "qa"}}},
+		},
+		"GET " + mrs + "/6/approvals": map[string]any{"approved": false, "approved_by": []map[string]any{}},
+	})
+	p := NewProvider(ProviderGitLab)
+	ctx := context.Background()
+
+	pr, err := p.CreatePullRequest(ctx, "tok", "gitlab.example", "acme/sub", "pkg", PullRequestInput{Title: "t", Body: "b", Head: "dev", Base: "qas"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	want := PullRequest{Number: 4, URL: "https://gitlab.example/acme/sub/pkg/-/merge_requests/4", Title: "t", State: PullRequestOpen, Author: "dev1", Head: "dev", Base: "qas", HeadSHA: "h1", BaseSHA: "b1"}
+	if pr != want {
+		t.Errorf("create = %+v, want %+v", pr, want)
+	}
+	c := (*calls)[0]
+	if c.Header.Get("PRIVATE-TOKEN") != "tok" || c.Payload["source_branch"] != "dev" || c.Payload["target_branch"] != "qas" || c.Payload["description"] != "b" {
+		t.Errorf("create request = %+v", c)
+	}
+
+	for _, tc := range []struct {
+		number      int
+		state, sha  string
+		description string
+	}{
+		{4, PullRequestMerged, "m1", "merge commit"},
+		{5, PullRequestMerged, "s1", "squash without merge commit"},
+		{6, PullRequestClosed, "", "locked"},
+	} {
+		if pr, err = p.GetPullRequest(ctx, "tok", "gitlab.example", "acme/sub", "pkg", tc.number); err != nil || pr.State != tc.state || pr.MergeCommitSHA != tc.sha {
+			t.Errorf("get %s = %+v, %v", tc.description, pr, err)
+		}
+	}
+
+	a, err := p.PullRequestApprovals(ctx, "tok", "gitlab.example", "acme/sub", "pkg", 4, "h1")
+	if err != nil || !reflect.DeepEqual(a, PullRequestApprovals{Approved: true, ApprovedBy: []string{"lead", "qa"}}) {
+		t.Errorf("approvals = %+v, %v", a, err)
+	}
+	if a, err = p.PullRequestApprovals(ctx, "tok", "gitlab.example", "acme/sub", "pkg", 6, "h6"); err != nil || a.Approved || len(a.ApprovedBy) != 0 {
+		t.Errorf("approvals (none) = %+v, %v", a, err)
+	}
+
+	if err := p.MergePullRequest(ctx, "tok", "gitlab.example", "acme/sub", "pkg", 4, "h1", "msg"); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	c = (*calls)[len(*calls)-1]
+	if c.Payload["merge_commit_message"] != "msg" || c.Payload["squash"] != false || c.Payload["sha"] != "h1" {
+		t.Errorf("merge payload = %v", c.Payload)
+	}
+}
+
+// TestPullRequestHeadMovedAfterApproval pushes a commit after the approval: the approval no
+// longer counts and a merge pinned to the approved head is refused by the provider.
+func TestPullRequestHeadMovedAfterApproval(t *testing.T) {
+	// mergeAt answers like the providers do when the pinned sha is not the head.
+	mergeAt := func(head string, conflict int) func(map[string]any) (int, any) {
+		return func(payload map[string]any) (int, any) {
+			if payload["sha"] != head {
+				return conflict, map[string]any{"message": "Head branch was modified. Review and try the merge again."}
+			}
+			return http.StatusOK, map[string]any{"merged": true}
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("github", func(t *testing.T) {
+		fakeAPI(t, map[string]any{
+			"GET /repos/acme/pkg/pulls/12": map[string]any{"number": 12, "state": "open", "head": map[string]any{"ref": "dev", "sha": "h2"}},
+			"GET /repos/acme/pkg/pulls/12/reviews": []map[string]any{
+				{"state": "APPROVED", "commit_id": "h1", "user": map[string]any{"login": "lead"}},
+			},
+			"PUT /repos/acme/pkg/pulls/12/merge": mergeAt("h2", http.StatusConflict),
+		})
+		p := NewProvider(ProviderGitHub)
+		pr, err := p.GetPullRequest(ctx, "tok", "github.com", "acme", "pkg", 12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := p.PullRequestApprovals(ctx, "tok", "github.com", "acme", "pkg", 12, pr.HeadSHA)
+		if err != nil || a.Approved || len(a.ApprovedBy) != 0 || !reflect.DeepEqual(a.Outdated, []string{"lead"}) {
+			t.Errorf("approvals = %+v, %v", a, err)
+		}
+		if err := p.MergePullRequest(ctx, "tok", "github.com", "acme", "pkg", 12, "h1", "msg"); !isAPIStatus(err, http.StatusConflict) {
+			t.Errorf("merge of approved head h1 = %v, want 409", err)
+		}
+	})
+
+	t.Run("gitlab", func(t *testing.T) {
+		const mrs = "/projects/acme%2Fpkg/merge_requests"
+		fakeAPI(t, map[string]any{"PUT " + mrs + "/4/merge": mergeAt("h2", http.StatusConflict)})
+		err := NewProvider(ProviderGitLab).MergePullRequest(ctx, "tok", "gitlab.example", "acme", "pkg", 4, "h1", "msg")
+		if !isAPIStatus(err, http.StatusConflict) {
+			t.Errorf("merge of approved head h1 = %v, want 409", err)
+		}
+	})
+}
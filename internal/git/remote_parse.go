@@ -64,6 +64,8 @@ func detectProvider(host string) string {
 		return ProviderGitHub
 	case strings.Contains(h, "gitlab"):
 		return ProviderGitLab
+	case strings.Contains(h, "gitea"), strings.Contains(h, "forgejo"), strings.Contains(h, "codeberg"):
+		return ProviderGitea
 	default:
 		return ProviderUnknown
 	}
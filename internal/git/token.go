@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
 )
 
 // ResolveToken reads an auth token from environment variables.
@@ -14,6 +16,7 @@ import (
 //	Provider-specific fallbacks:
 //	  GitHub:   GITHUB_TOKEN, GH_TOKEN
 //	  GitLab:   GITLAB_TOKEN, GITLAB_PRIVATE_TOKEN
+//	  Gitea:    GITEA_TOKEN, FORGEJO_TOKEN
 func ResolveToken(provider string) (string, error) {
 	keys := []string{"IFLOWKIT_GIT_TOKEN"}
 	switch provider {
@@ -21,10 +24,13 @@ func ResolveToken(provider string) (string, error) {
 		keys = append(keys, "GITHUB_TOKEN", "GH_TOKEN")
 	case ProviderGitLab:
 		keys = append(keys, "GITLAB_TOKEN", "GITLAB_PRIVATE_TOKEN")
+	case ProviderGitea:
+		keys = append(keys, "GITEA_TOKEN", "FORGEJO_TOKEN")
 	}
 	for _, k := range keys {
 		v := strings.TrimSpace(os.Getenv(k))
 		if v != "" {
+			logging.RegisterSecret(v)
 			return v, nil
 		}
 	}
@@ -156,13 +156,13 @@ func (l *Logger) log(level Level, msg string, fields ...Field) {
 	rec := map[string]any{
 		"ts":    time.Now().Format(time.RFC3339Nano),
 		"level": level.String(),
-		"msg":   msg,
+		"msg":   Redact(msg),
 	}
 	for _, f := range fields {
 		if f.Key == "" {
 			continue
 		}
-		rec[f.Key] = f.Val
+		rec[f.Key] = redactValue(f.Val)
 	}
 
 	l.mu.Lock()
@@ -192,6 +192,7 @@ func (l *Logger) log(level Level, msg string, fields ...Field) {
 	for _, k := range keys {
 		pairs = append(pairs, fmt.Sprintf("%s=%v", k, rec[k]))
 	}
+	msg = rec["msg"].(string)
 	plain := fmt.Sprintf("%s %s %s", ts, padLevel(lvl), msg)
 	if len(pairs) > 0 {
 		plain = plain + " " + strings.Join(pairs, " ")
@@ -3,13 +3,32 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 const CurrentConfigSchemaVersion = 1
 
+// DefaultTenantKeyMaxAgeDays is used when config.json does not set tenantKeyMaxAgeDays.
+const DefaultTenantKeyMaxAgeDays = 90
+
 type Config struct {
 	SchemaVersion    int    `json:"schema_version"`
 	ProfileExportDir string `json:"profileExportDir"`
+	// TenantKeyMaxAgeDays triggers a warning on sync commands when a service key is older.
+	// 0 means DefaultTenantKeyMaxAgeDays, a negative value disables the warning.
+	TenantKeyMaxAgeDays int `json:"tenantKeyMaxAgeDays,omitempty"`
+}
+
+// TenantKeyMaxAge returns the configured key age limit (0 = disabled).
+func (c Config) TenantKeyMaxAge() time.Duration {
+	days := c.TenantKeyMaxAgeDays
+	if days == 0 {
+		days = DefaultTenantKeyMaxAgeDays
+	}
+	if days < 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
 }
 
 func (c Config) PrettyJSON() ([]byte, error) {
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const CurrentCredentialHelpersSchemaVersion = 1
+
+// CredentialHelpers maps tenant envs to external commands that print a service key JSON on stdout.
+// The "*" entry applies to every env without an explicit helper.
+type CredentialHelpers struct {
+	SchemaVersion int                         `json:"schema_version"`
+	Helpers       map[string]CredentialHelper `json:"helpers"`
+}
+
+func (c CredentialHelpers) PrettyJSON() ([]byte, error) {
+	return json.MarshalIndent(c, "", "  ")
+}
+
+type CredentialHelper struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+func (h CredentialHelper) ValidateRequired() error {
+	if h.Command == "" {
+		return fmt.Errorf("credential helper missing required field: command")
+	}
+	return nil
+}
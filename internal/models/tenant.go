@@ -3,6 +3,8 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"time"
 )
 
 type TenantServiceKey struct {
@@ -17,11 +19,42 @@ type TenantOAuth struct {
 	URL          string `json:"url"`
 }
 
+// CreatedAt parses oauth.createdate (RFC3339 or RFC3339Nano).
+func (t TenantServiceKey) CreatedAt() (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, time.RFC3339} {
+		if ts, err := time.Parse(layout, t.OAuth.CreateDate); err == nil {
+			return ts, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// TokenHost returns the host of oauth.tokenurl (empty when unparsable).
+func (t TenantServiceKey) TokenHost() string {
+	u, err := url.Parse(t.OAuth.TokenURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
 func (t TenantServiceKey) PrettyJSON() ([]byte, error) {
 	return json.MarshalIndent(t, "", "  ")
 }
 
 func (t TenantServiceKey) ValidateRequired() error {
+	if err := t.ValidateCredentials(); err != nil {
+		return err
+	}
+	if t.OAuth.CreateDate == "" {
+		return fmt.Errorf("tenant service key missing required field: oauth.createdate")
+	}
+	return nil
+}
+
+// ValidateCredentials checks the fields needed to authenticate. Keys from env vars and
+// credential helpers are only checked this way; they often carry no createdate.
+func (t TenantServiceKey) ValidateCredentials() error {
 	if t.OAuth.URL == "" {
 		return fmt.Errorf("tenant service key missing required field: oauth.url")
 	}
@@ -34,8 +67,5 @@ func (t TenantServiceKey) ValidateRequired() error {
 	if t.OAuth.ClientSecret == "" {
 		return fmt.Errorf("tenant service key missing required field: oauth.clientsecret")
 	}
-	if t.OAuth.CreateDate == "" {
-		return fmt.Errorf("tenant service key missing required field: oauth.createdate")
-	}
 	return nil
 }
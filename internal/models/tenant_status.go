@@ -0,0 +1,16 @@
+package models
+
+const CurrentTenantStatusSchemaVersion = 1
+
+// TenantStatus holds non-secret runtime facts about the tenant keys of a profile.
+type TenantStatus struct {
+	SchemaVersion int                        `json:"schema_version"`
+	Envs          map[string]TenantEnvStatus `json:"envs"`
+}
+
+type TenantEnvStatus struct {
+	// LastAuthAt is the last successful OAuth token request (RFC3339).
+	LastAuthAt string `json:"lastAuthAt,omitempty"`
+	// RotatedAt is set by `tenant rotate` (RFC3339).
+	RotatedAt string `json:"rotatedAt,omitempty"`
+}
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SchemaVersion is the version of the JSON/YAML documents written by Write.
+// Bump it when a field is removed or changes meaning; adding fields is backwards compatible.
+const SchemaVersion = 1
+
+// Format is the value of the global --output flag.
+type Format string
+
+const (
+	Table Format = "table"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+)
+
+// ParseFormat validates an --output value. An empty value means table.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", Table:
+		return Table, nil
+	case JSON:
+		return JSON, nil
+	case YAML:
+		return YAML, nil
+	default:
+		return "", fmt.Errorf("invalid --output %q (allowed: table|json|yaml)", s)
+	}
+}
+
+// Structured reports whether f is a machine-readable format.
+func (f Format) Structured() bool { return f == JSON || f == YAML }
+
+// Result is the typed result of a command.
+// The struct itself (with its json tags) is the `data` of the JSON/YAML document;
+// WriteTable renders the human-readable form.
+type Result interface {
+	// ResultKind is the stable document kind, e.g. "ProfileList" or "SyncCompare".
+	ResultKind() string
+	WriteTable(w io.Writer) error
+}
+
+// Document is the envelope of every JSON/YAML result.
+type Document struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Kind          string `json:"kind"`
+	Data          any    `json:"data"`
+}
+
+// Write renders r in the given format.
+func Write(w io.Writer, f Format, r Result) error {
+	if !f.Structured() {
+		return r.WriteTable(w)
+	}
+	b, err := json.MarshalIndent(Document{SchemaVersion: SchemaVersion, Kind: r.ResultKind(), Data: r}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if f == YAML {
+		if b, err = jsonToYAML(b); err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+// CommandResult is written for commands that have no typed result of their own,
+// so structured output always produces exactly one document.
+type CommandResult struct {
+	Command string `json:"command"`
+	Status  string `json:"status"` // ok|error
+	Error   string `json:"error,omitempty"`
+}
+
+func (r CommandResult) ResultKind() string {
+	if r.Status == "error" {
+		return "Error"
+	}
+	return "CommandResult"
+}
+
+func (r CommandResult) WriteTable(w io.Writer) error {
+	if r.Error != "" {
+		_, err := fmt.Fprintln(w, r.Error)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type sampleItem struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// sample covers the shapes results use: scalars that need quoting, nested
+// sequences, sequences of objects and empty containers.
+type sample struct {
+	ID      string            `json:"id"`
+	Count   int               `json:"count"`
+	Items   []sampleItem      `json:"items"`
+	Matrix  [][]string        `json:"matrix"`
+	Empty   []string          `json:"empty"`
+	Labels  map[string]string `json:"labels"`
+	Missing *sampleItem       `json:"missing"`
+}
+
+func (sample) ResultKind() string { return "Sample" }
+
+func (sample) WriteTable(w io.Writer) error {
+	_, err := fmt.Fprintln(w, "sample table")
+	return err
+}
+
+var testSample = sample{
+	ID:    "007",
+	Count: 2,
+	Items: []sampleItem{
+		{Name: "Order: Create", Tags: []string{"true", "-x"}},
+		{Name: "plain", Tags: []string{}},
+	},
+	Matrix: [][]string{{"a", "b"}, {"c"}},
+	Empty:  []string{},
+	Labels: map[string]string{},
+}
+
+func TestWriteJSONEnvelope(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, JSON, testSample); err != nil {
+		t.Fatal(err)
+	}
+	// The envelope fields come first and in this order; scripts rely on it.
+	if !strings.HasPrefix(b.String(), "{\n  \"schemaVersion\": 1,\n  \"kind\": \"Sample\",\n  \"data\": {\n") {
+		t.Fatalf("envelope:\n%s", b.String())
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc) != 3 {
+		t.Errorf("document keys = %d, want schemaVersion, kind and data only", len(doc))
+	}
+	var data sample
+	if err := json.Unmarshal(doc["data"], &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.ID != "007" || len(data.Matrix) != 2 || data.Empty == nil || data.Labels == nil {
+		t.Errorf("data = %+v", data)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	for _, f := range []Format{Table, ""} {
+		var b bytes.Buffer
+		if err := Write(&b, f, testSample); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != "sample table\n" {
+			t.Errorf("format %q wrote %q, want the table form", f, b.String())
+		}
+	}
+}
+
+func TestCommandResultKind(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, JSON, CommandResult{Command: "sync push", Status: "error", Error: "boom"}); err != nil {
+		t.Fatal(err)
+	}
+	var doc Document
+	if err := json.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SchemaVersion != SchemaVersion || doc.Kind != "Error" {
+		t.Errorf("document = %+v", doc)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": Table, "table": Table, " JSON ": JSON, "yaml": YAML} {
+		got, err := ParseFormat(in)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) succeeded")
+	}
+}
@@ -0,0 +1,151 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonNode is a decoded JSON value that keeps the key order of objects,
+// so YAML documents list fields in the same order as their JSON form.
+type jsonNode struct {
+	object bool
+	array  bool
+	keys   []string
+	values []*jsonNode
+	scalar string // YAML-ready scalar for non-containers
+}
+
+// jsonToYAML converts a JSON document into block-style YAML.
+func jsonToYAML(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	n, err := decodeNode(dec)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	writeYAML(&out, n, 0)
+	return out.Bytes(), nil
+}
+
+func decodeNode(dec *json.Decoder) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		n := &jsonNode{object: t == '{', array: t == '['}
+		for dec.More() {
+			if n.object {
+				kt, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, kt.(string))
+			}
+			v, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.values = append(n.values, v)
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return &jsonNode{scalar: yamlString(t)}, nil
+	case json.Number:
+		return &jsonNode{scalar: t.String()}, nil
+	case bool:
+		return &jsonNode{scalar: fmt.Sprint(t)}, nil
+	case nil:
+		return &jsonNode{scalar: "null"}, nil
+	default:
+		return nil, fmt.Errorf("unexpected JSON token %v", tok)
+	}
+}
+
+// inline returns the YAML form of scalars and empty containers.
+func (n *jsonNode) inline() (string, bool) {
+	switch {
+	case n.object && len(n.values) == 0:
+		return "{}", true
+	case n.array && len(n.values) == 0:
+		return "[]", true
+	case n.object || n.array:
+		return "", false
+	default:
+		return n.scalar, true
+	}
+}
+
+func writeYAML(out *bytes.Buffer, n *jsonNode, depth int) {
+	pad := strings.Repeat("  ", depth)
+	if s, ok := n.inline(); ok {
+		out.WriteString(pad + s + "\n")
+		return
+	}
+	for i, v := range n.values {
+		prefix := "- "
+		if n.object {
+			prefix = yamlString(n.keys[i]) + ":"
+		}
+		if s, ok := v.inline(); ok {
+			if n.object {
+				prefix += " "
+			}
+			out.WriteString(pad + prefix + s + "\n")
+			continue
+		}
+		if n.object {
+			out.WriteString(pad + prefix + "\n")
+			// Sequences under a key are not indented (the common YAML style).
+			if v.array {
+				writeYAML(out, v, depth)
+			} else {
+				writeYAML(out, v, depth+1)
+			}
+			continue
+		}
+		// Sequence item holding a container: the first line shares the "- ".
+		var item bytes.Buffer
+		writeYAML(&item, v, depth+1)
+		out.WriteString(pad + prefix + strings.TrimPrefix(item.String(), pad+"  "))
+	}
+}
+
+// yamlString returns s as a plain scalar when that is unambiguous, or double-quoted
+// (JSON string syntax, which is valid YAML) otherwise.
+func yamlString(s string) string {
+	if needsQuotes(s) {
+		b, _ := json.Marshal(s)
+		return string(b)
+	}
+	return s
+}
+
+func needsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~":
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`0123456789.+") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteYAML(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, YAML, testSample); err != nil {
+		t.Fatal(err)
+	}
+	want := `schemaVersion: 1
+kind: Sample
+data:
+  id: "007"
+  count: 2
+  items:
+  - name: "Order: Create"
+    tags:
+    - "true"
+    - "-x"
+  - name: plain
+    tags: []
+  matrix:
+  - - a
+    - b
+  - - c
+  empty: []
+  labels: {}
+  missing: null
+`
+	if b.String() != want {
+		t.Errorf("yaml:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestYAMLString(t *testing.T) {
+	for in, want := range map[string]string{
+		"plain":          "plain",
+		"iFlows/Order_1": "iFlows/Order_1",
+		"a:b":            "a:b",
+		"":               `""`,
+		" padded":        `" padded"`,
+		"yes":            `"yes"`,
+		"Null":           `"Null"`,
+		"~":              `"~"`,
+		"123":            `"123"`,
+		"1.0.2":          `"1.0.2"`,
+		"-dash":          `"-dash"`,
+		"#comment":       `"#comment"`,
+		"*alias":         `"*alias"`,
+		"key: value":     `"key: value"`,
+		"trailing:":      `"trailing:"`,
+		"a #b":           `"a #b"`,
+		"line\nbreak":    `"line\nbreak"`,
+		"tab\there":      `"tab\there"`,
+		`say "hi"`:       `say "hi"`,
+		`"quoted"`:       `"\"quoted\""`,
+	} {
+		if got := yamlString(in); got != want {
+			t.Errorf("yamlString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestJSONToYAMLTopLevel(t *testing.T) {
+	for in, want := range map[string]string{
+		`[]`:                   "[]\n",
+		`{}`:                   "{}\n",
+		`"x"`:                  "x\n",
+		`[[],{},[1]]`:          "- []\n- {}\n- - 1\n",
+		`[{"a":{"b":[true]}}]`: "- a:\n    b:\n    - true\n",
+	} {
+		got, err := jsonToYAML([]byte(in))
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if string(got) != want {
+			t.Errorf("jsonToYAML(%s) = %q, want %q", in, got, want)
+		}
+	}
+}
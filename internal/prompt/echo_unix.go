@@ -0,0 +1,22 @@
+//go:build !windows
+
+package prompt
+
+import (
+	"os"
+	"os/exec"
+)
+
+// disableEcho turns off echo on the terminal f and returns a func restoring it.
+func disableEcho(f *os.File) (func(), error) {
+	if err := stty(f, "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { _ = stty(f, "echo") }, nil
+}
+
+func stty(f *os.File, arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = f
+	return cmd.Run()
+}
@@ -4,17 +4,23 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"os"
 	"strconv"
 	"strings"
 )
 
 type IO struct {
-	in  *bufio.Reader
-	out io.Writer
+	in       *bufio.Reader
+	out      io.Writer
+	terminal *os.File // in, when it is an interactive terminal
 }
 
 func NewIO(in io.Reader, out io.Writer) *IO {
-	return &IO{in: bufio.NewReader(in), out: out}
+	p := &IO{in: bufio.NewReader(in), out: out}
+	if IsTerminal(in) {
+		p.terminal = in.(*os.File)
+	}
+	return p
 }
 
 func (p *IO) AskString(label string, current *string, validate func(string) error) (string, error) {
@@ -42,6 +48,39 @@ func (p *IO) AskString(label string, current *string, validate func(string) erro
 	}
 }
 
+// AskSecret reads a line without echoing it when the input is a terminal.
+func (p *IO) AskSecret(label string, validate func(string) error) (string, error) {
+	for {
+		fmt.Fprintf(p.out, "%s: ", label)
+		line, err := p.readSecretLine()
+		if err != nil {
+			return "", err
+		}
+		if validate != nil {
+			if err := validate(line); err != nil {
+				fmt.Fprintf(p.out, "  Error: %v\n", err)
+				continue
+			}
+		}
+		return line, nil
+	}
+}
+
+func (p *IO) readSecretLine() (string, error) {
+	if p.terminal == nil {
+		return p.readLine()
+	}
+	restore, err := disableEcho(p.terminal)
+	if err != nil {
+		return "", fmt.Errorf("cannot turn off terminal echo: %w", err)
+	}
+	line, err := p.readLine()
+	restore()
+	// The newline typed by the user was not echoed either.
+	fmt.Fprintln(p.out)
+	return line, err
+}
+
 func (p *IO) AskInt(label string, current *int, validate func(int) error) (int, error) {
 	for {
 		if current != nil {
@@ -115,3 +154,19 @@ func (p *IO) readLine() (string, error) {
 	}
 	return strings.TrimRight(line, "\r\n"), nil
 }
+
+// IsTerminal reports whether r is an interactive terminal (a character device other than the null device).
+func IsTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(fi, null) {
+		return false
+	}
+	return true
+}
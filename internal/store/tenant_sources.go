@@ -0,0 +1,184 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/common/filex"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// Tenant key sources, in resolution order.
+const (
+	TenantSourceEnv    = "env"
+	TenantSourceHelper = "helper"
+	TenantSourceFile   = "file"
+)
+
+// credentialHelperTimeout bounds how long an external helper may take to print a key.
+const credentialHelperTimeout = 60 * time.Second
+
+// TenantEnvPrefix returns the environment variable prefix for an env, e.g. IFLOWKIT_TENANT_DEV_.
+func TenantEnvPrefix(env string) string {
+	return "IFLOWKIT_TENANT_" + strings.ToUpper(env) + "_"
+}
+
+// readFromEnvVars resolves a key from IFLOWKIT_TENANT_<ENV>_JSON or the individual field variables.
+// ok is false when none of the variables are set.
+func readFromEnvVars(env string) (t models.TenantServiceKey, ok bool, err error) {
+	prefix := TenantEnvPrefix(env)
+	if raw := strings.TrimSpace(os.Getenv(prefix + "JSON")); raw != "" {
+		t, err := parseTenantKeyJSON([]byte(raw))
+		if err != nil {
+			return models.TenantServiceKey{}, true, fmt.Errorf("%sJSON: %w", prefix, err)
+		}
+		return t, true, nil
+	}
+
+	fields := []struct {
+		name string
+		dst  *string
+	}{
+		{"URL", &t.OAuth.URL},
+		{"TOKEN_URL", &t.OAuth.TokenURL},
+		{"CLIENT_ID", &t.OAuth.ClientID},
+		{"CLIENT_SECRET", &t.OAuth.ClientSecret},
+		{"CREATED_AT", &t.OAuth.CreateDate},
+	}
+	missing := []string{}
+	for _, f := range fields {
+		v := strings.TrimSpace(os.Getenv(prefix + f.name))
+		if v != "" {
+			ok = true
+		} else if f.name != "CREATED_AT" {
+			missing = append(missing, prefix+f.name)
+		}
+		*f.dst = v
+	}
+	if !ok {
+		return models.TenantServiceKey{}, false, nil
+	}
+	if len(missing) > 0 {
+		return models.TenantServiceKey{}, true, fmt.Errorf("incomplete tenant key in environment; missing: %s", strings.Join(missing, ", "))
+	}
+	return t, true, t.ValidateCredentials()
+}
+
+func parseTenantKeyJSON(b []byte) (models.TenantServiceKey, error) {
+	var t models.TenantServiceKey
+	if err := json.Unmarshal(b, &t); err != nil {
+		return models.TenantServiceKey{}, fmt.Errorf("invalid tenant JSON: %w", err)
+	}
+	if err := t.ValidateCredentials(); err != nil {
+		return models.TenantServiceKey{}, err
+	}
+	return t, nil
+}
+
+func (s *TenantStore) helpersFile(profileID string) string {
+	return filepath.Join(s.profilesDir, profileID, "credential-helpers.json")
+}
+
+// ReadHelpers returns the credential helper configuration of a profile (empty when not configured).
+func (s *TenantStore) ReadHelpers(profileID string) (models.CredentialHelpers, error) {
+	cfg := models.CredentialHelpers{SchemaVersion: models.CurrentCredentialHelpersSchemaVersion, Helpers: map[string]models.CredentialHelper{}}
+	b, err := os.ReadFile(s.helpersFile(profileID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid credential-helpers.json: %w", err)
+	}
+	if cfg.SchemaVersion != models.CurrentCredentialHelpersSchemaVersion {
+		return cfg, fmt.Errorf("unsupported credential-helpers.json schema_version %d (current: %d)", cfg.SchemaVersion, models.CurrentCredentialHelpersSchemaVersion)
+	}
+	if cfg.Helpers == nil {
+		cfg.Helpers = map[string]models.CredentialHelper{}
+	}
+	return cfg, nil
+}
+
+// SetHelper configures (or, with a nil helper, removes) the credential helper for env ("*" = all envs).
+func (s *TenantStore) SetHelper(profileID, env string, h *models.CredentialHelper) error {
+	cfg, err := s.ReadHelpers(profileID)
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		delete(cfg.Helpers, env)
+	} else {
+		if err := h.ValidateRequired(); err != nil {
+			return err
+		}
+		cfg.Helpers[env] = *h
+	}
+	file := s.helpersFile(profileID)
+	if len(cfg.Helpers) == 0 {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return filex.AtomicWriteFile(file, b, 0o644)
+}
+
+// helperFor returns the helper configured for env, falling back to the "*" entry.
+func (s *TenantStore) helperFor(profileID, env string) (*models.CredentialHelper, error) {
+	cfg, err := s.ReadHelpers(profileID)
+	if err != nil {
+		return nil, err
+	}
+	if h, ok := cfg.Helpers[env]; ok {
+		return &h, nil
+	}
+	if h, ok := cfg.Helpers["*"]; ok {
+		return &h, nil
+	}
+	return nil, nil
+}
+
+// runHelper executes a credential helper and parses the key JSON it prints on stdout.
+// The helper receives IFLOWKIT_PROFILE and IFLOWKIT_ENV in its environment.
+func (s *TenantStore) runHelper(profileID, env string, h models.CredentialHelper) (models.TenantServiceKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), credentialHelperTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.Command, h.Args...)
+	cmd.Env = append(os.Environ(), "IFLOWKIT_PROFILE="+profileID, "IFLOWKIT_ENV="+env)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return models.TenantServiceKey{}, fmt.Errorf("credential helper %q timed out after %s", h.Command, credentialHelperTimeout)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return models.TenantServiceKey{}, fmt.Errorf("credential helper %q failed: %s", h.Command, logging.Redact(msg))
+	}
+	t, err := parseTenantKeyJSON(stdout.Bytes())
+	if err != nil {
+		return models.TenantServiceKey{}, fmt.Errorf("credential helper %q: %w", h.Command, err)
+	}
+	return t, nil
+}
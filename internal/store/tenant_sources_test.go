@@ -0,0 +1,31 @@
+package store
+
+import (
+	"testing"
+)
+
+func TestReadFromEnvVarsCreateDate(t *testing.T) {
+	prefix := TenantEnvPrefix("dev")
+	for k, v := range map[string]string{"URL": "https://tenant.example", "TOKEN_URL": "https://auth.example/oauth/token", "CLIENT_ID": "id", "CLIENT_SECRET": "secret-123456"} {
+		t.Setenv(prefix+k, v)
+	}
+
+	// Without _CREATED_AT the age stays unknown instead of starting at zero.
+	key, ok, err := readFromEnvVars("dev")
+	if err != nil || !ok {
+		t.Fatalf("readFromEnvVars() = %v, %v", ok, err)
+	}
+	if _, known := key.CreatedAt(); known || key.OAuth.CreateDate != "" {
+		t.Errorf("createdate = %q, want empty", key.OAuth.CreateDate)
+	}
+
+	t.Setenv(prefix+"CREATED_AT", "2024-05-01T10:00:00Z")
+	if key, _, _ = readFromEnvVars("dev"); key.OAuth.CreateDate != "2024-05-01T10:00:00Z" {
+		t.Errorf("createdate = %q", key.OAuth.CreateDate)
+	}
+
+	t.Setenv(prefix+"JSON", `{"oauth":{"url":"https://tenant.example","tokenurl":"https://auth.example/oauth/token","clientid":"id","clientsecret":"secret-123456"}}`)
+	if key, _, err = readFromEnvVars("dev"); err != nil || key.OAuth.CreateDate != "" {
+		t.Errorf("JSON key = %+v, %v", key.OAuth, err)
+	}
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/common/filex"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+func (s *TenantStore) statusFile(profileID string) string {
+	return filepath.Join(s.profilesDir, profileID, "tenant-status.json")
+}
+
+// rollbackFile is the slot holding the key replaced by the last rotation.
+// It lives in a sub directory so List does not report it as an env.
+func (s *TenantStore) rollbackFile(profileID, env string) string {
+	return filepath.Join(s.profilesDir, profileID, "tenants", ".rollback", fmt.Sprintf("%s.json", env))
+}
+
+// ReadStatus returns the stored tenant status of a profile (empty when missing).
+func (s *TenantStore) ReadStatus(profileID string) (models.TenantStatus, error) {
+	st := models.TenantStatus{SchemaVersion: models.CurrentTenantStatusSchemaVersion, Envs: map[string]models.TenantEnvStatus{}}
+	b, err := os.ReadFile(s.statusFile(profileID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st, nil
+		}
+		return st, err
+	}
+	if err := json.Unmarshal(b, &st); err != nil {
+		return st, fmt.Errorf("invalid tenant-status.json: %w", err)
+	}
+	if st.Envs == nil {
+		st.Envs = map[string]models.TenantEnvStatus{}
+	}
+	return st, nil
+}
+
+func (s *TenantStore) updateStatus(profileID, env string, fn func(*models.TenantEnvStatus)) error {
+	st, err := s.ReadStatus(profileID)
+	if err != nil {
+		return err
+	}
+	es := st.Envs[env]
+	fn(&es)
+	st.Envs[env] = es
+	st.SchemaVersion = models.CurrentTenantStatusSchemaVersion
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := s.statusFile(profileID)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return filex.AtomicWriteFile(file, b, 0o644)
+}
+
+// RecordAuth stores the time of the last successful token request for profileID/env.
+func (s *TenantStore) RecordAuth(profileID, env string, at time.Time) error {
+	return s.updateStatus(profileID, env, func(es *models.TenantEnvStatus) {
+		es.LastAuthAt = at.UTC().Format(time.RFC3339)
+	})
+}
+
+// HasRollback reports whether a previous key is kept for env.
+func (s *TenantStore) HasRollback(profileID, env string) bool {
+	_, err := os.Stat(s.rollbackFile(profileID, env))
+	return err == nil
+}
+
+// Rotate replaces the stored key of env with t. The current key (if any) is kept in the
+// rollback slot first; the new key is then swapped in with an atomic rename.
+func (s *TenantStore) Rotate(profileID, env string, t models.TenantServiceKey) error {
+	if err := t.ValidateRequired(); err != nil {
+		return err
+	}
+	newBytes, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := s.tenantFile(profileID, env)
+	if cur, err := os.ReadFile(file); err == nil {
+		slot := s.rollbackFile(profileID, env)
+		if err := os.MkdirAll(filepath.Dir(slot), 0o700); err != nil {
+			return err
+		}
+		if err := filex.AtomicWriteFile(slot, cur, 0o600); err != nil {
+			return fmt.Errorf("save rollback slot: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	if err := filex.AtomicWriteFile(file, newBytes, 0o644); err != nil {
+		return err
+	}
+	return s.updateStatus(profileID, env, func(es *models.TenantEnvStatus) {
+		es.RotatedAt = time.Now().UTC().Format(time.RFC3339)
+		es.LastAuthAt = ""
+	})
+}
+
+// Rollback swaps the current key of env with the one in the rollback slot.
+func (s *TenantStore) Rollback(profileID, env string) error {
+	slot := s.rollbackFile(profileID, env)
+	prev, err := os.ReadFile(slot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no rollback key stored for %s/%s", profileID, env)
+		}
+		return err
+	}
+	var t models.TenantServiceKey
+	if err := json.Unmarshal(prev, &t); err != nil {
+		return fmt.Errorf("invalid rollback key: %w", err)
+	}
+	return s.Rotate(profileID, env, t)
+}
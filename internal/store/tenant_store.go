@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/iflowkit/iflowkit-cli/internal/common/filex"
 	"github.com/iflowkit/iflowkit-cli/internal/logging"
@@ -41,21 +43,75 @@ func (s *TenantStore) Write(profileID, env string, t models.TenantServiceKey) er
 	return filex.AtomicWriteFile(file, b, 0o644)
 }
 
+// Read resolves the service key for profileID/env.
+// Sources are tried in order: environment variables (IFLOWKIT_TENANT_<ENV>_*),
+// the profile's credential helper, then the tenants/<env>.json file.
 func (s *TenantStore) Read(profileID, env string) (models.TenantServiceKey, error) {
+	t, _, err := s.ReadWithSource(profileID, env)
+	return t, err
+}
+
+// ReadWithSource is Read and also reports which source provided the key (see TenantSource*).
+func (s *TenantStore) ReadWithSource(profileID, env string) (models.TenantServiceKey, string, error) {
+	t, source, err := s.resolve(profileID, env)
+	if err != nil {
+		return models.TenantServiceKey{}, source, err
+	}
+	logging.RegisterSecret(t.OAuth.ClientSecret)
+	if s.lg != nil {
+		s.lg.Debug("tenant key resolved", logging.F("profile_id", profileID), logging.F("env", env), logging.F("source", source))
+	}
+	return t, source, nil
+}
+
+func (s *TenantStore) resolve(profileID, env string) (models.TenantServiceKey, string, error) {
+	if t, ok, err := readFromEnvVars(env); ok {
+		return t, TenantSourceEnv, err
+	}
+	h, err := s.helperFor(profileID, env)
+	if err != nil {
+		return models.TenantServiceKey{}, TenantSourceHelper, err
+	}
+	if h != nil {
+		t, err := s.runHelper(profileID, env, *h)
+		return t, TenantSourceHelper, err
+	}
+
 	b, err := os.ReadFile(s.tenantFile(profileID, env))
 	if err != nil {
-		return models.TenantServiceKey{}, err
+		return models.TenantServiceKey{}, TenantSourceFile, err
 	}
 	var t models.TenantServiceKey
 	if err := json.Unmarshal(b, &t); err != nil {
-		return models.TenantServiceKey{}, fmt.Errorf("invalid tenant JSON: %w", err)
+		return models.TenantServiceKey{}, TenantSourceFile, fmt.Errorf("invalid tenant JSON: %w", err)
 	}
 	if err := t.ValidateRequired(); err != nil {
-		return models.TenantServiceKey{}, err
+		return models.TenantServiceKey{}, TenantSourceFile, err
 	}
-	return t, nil
+	return t, TenantSourceFile, nil
 }
 
 func (s *TenantStore) Delete(profileID, env string) error {
 	return os.Remove(s.tenantFile(profileID, env))
 }
+
+// List returns the envs that have a stored service key for the profile (sorted).
+func (s *TenantStore) List(profileID string) ([]string, error) {
+	ents, err := os.ReadDir(filepath.Join(s.profilesDir, profileID, "tenants"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	envs := []string{}
+	for _, e := range ents {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		envs = append(envs, strings.TrimSuffix(name, ".json"))
+	}
+	sort.Strings(envs)
+	return envs, nil
+}
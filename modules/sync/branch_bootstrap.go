@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/iflowkit/iflowkit-cli/internal/app"
-	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
 	"github.com/iflowkit/iflowkit-cli/internal/common/filex"
 	"github.com/iflowkit/iflowkit-cli/internal/logging"
 	"github.com/iflowkit/iflowkit-cli/internal/models"
@@ -69,7 +68,7 @@ func bootstrapEnvBranchFromTenant(ctx *app.Context, repoRoot string, meta models
 	}
 
 	// Export tenant state.
-	c := cpix.NewClient(tenantKey, ctx.Logger)
+	c := newTenantClient(ctx, profileID, env, tenantKey)
 	_, raw, err := c.ReadIntegrationPackage(meta.PackageID)
 	if err != nil {
 		return "", err
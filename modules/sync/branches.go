@@ -21,3 +21,9 @@ func isAllowedPushBranch(branch string) bool {
 	}
 	return false
 }
+
+// isWorkBranch reports whether branch is a developer branch (feature/* or bugfix/*) mapped to DEV.
+func isWorkBranch(branch string) bool {
+	branch = strings.TrimSpace(branch)
+	return strings.HasPrefix(branch, "feature/") || strings.HasPrefix(branch, "bugfix/")
+}
@@ -0,0 +1,327 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/common/gitx"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// contentSource returns the content of a repo-relative file on one side of a comparison.
+// ok is false when the file does not exist on that side.
+type contentSource func(repoRel string) (content []byte, ok bool)
+
+// gitRefSource reads files from a git ref (`git show <ref>:<path>`).
+func gitRefSource(repoRoot, ref string) contentSource {
+	return func(repoRel string) ([]byte, bool) {
+		out, code, err := gitx.OutputExit(nil, repoRoot, "show", ref+":"+repoRel)
+		if err != nil || code != 0 {
+			return nil, false
+		}
+		return []byte(out), true
+	}
+}
+
+// folderSource reads files from a directory that mirrors the repo layout.
+func folderSource(root string) contentSource {
+	return func(repoRel string) ([]byte, bool) {
+		b, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(repoRel)))
+		if err != nil {
+			return nil, false
+		}
+		return b, true
+	}
+}
+
+// compareFilter limits compare output to artifact kinds and id globs (--kind/--id).
+type compareFilter struct {
+	kinds []string
+	ids   []string
+}
+
+func newCompareFilter(kinds, ids []string) (*compareFilter, error) {
+	for _, k := range kinds {
+		if _, ok := knownArtifactKinds[k]; !ok {
+			return nil, fmt.Errorf("invalid --kind %q: expected iFlows, Scripts, ValueMappings, MessageMappings or CustomTags", k)
+		}
+	}
+	for _, id := range ids {
+		if _, err := path.Match(id, ""); err != nil {
+			return nil, fmt.Errorf("invalid --id %q: %w", id, err)
+		}
+	}
+	return &compareFilter{kinds: kinds, ids: ids}, nil
+}
+
+func (f *compareFilter) match(k artifactKey) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.kinds) > 0 {
+		found := false
+		for _, kind := range f.kinds {
+			found = found || kind == k.Kind
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.ids) > 0 {
+		for _, p := range f.ids {
+			if ok, _ := path.Match(p, k.ID); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// filterPaths keeps the artifact files matched by f; package-level files are kept only without a filter.
+func (f *compareFilter) filterPaths(meta models.SyncMetadata, paths []string) []string {
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		keys := mapKeysToSortedSlice(detectChangedArtifacts(meta, []string{p}))
+		if len(keys) == 0 {
+			if f == nil || (len(f.kinds) == 0 && len(f.ids) == 0) {
+				out = append(out, p)
+			}
+			continue
+		}
+		if f.match(keys[0]) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// fileChange is the diff of one file between the left and the right side.
+type fileChange struct {
+	Path    string `json:"path"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+	Binary  bool   `json:"binary,omitempty"`
+	Patch   string `json:"patch,omitempty"`
+}
+
+// diffFiles produces per-file unified diffs (with XML/JSON pretty-printed) using `git diff --no-index`.
+func diffFiles(ctx *app.Context, paths []string, left, right contentSource, withPatch bool) ([]fileChange, error) {
+	tmp, err := os.MkdirTemp("", "iflowkit-diff-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	out := make([]fileChange, 0, len(paths))
+	for _, p := range paths {
+		lb, lok := left(p)
+		rb, rok := right(p)
+		if !lok && !rok {
+			continue
+		}
+		la, ra := "/dev/null", "/dev/null"
+		if lok {
+			la = "a/" + p
+			if err := writeDiffSide(tmp, la, prettyContent(p, lb)); err != nil {
+				return nil, err
+			}
+		}
+		if rok {
+			ra = "b/" + p
+			if err := writeDiffSide(tmp, ra, prettyContent(p, rb)); err != nil {
+				return nil, err
+			}
+		}
+
+		fc := fileChange{Path: p}
+		stat, _, err := gitx.OutputExit(ctx.Logger, tmp, "diff", "--no-index", "--numstat", "--", la, ra)
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(stat)
+		if len(fields) < 2 {
+			continue // identical after pretty-printing
+		}
+		if fields[0] == "-" {
+			fc.Binary = true
+		} else {
+			fc.Added, _ = strconv.Atoi(fields[0])
+			fc.Removed, _ = strconv.Atoi(fields[1])
+		}
+		if withPatch {
+			patch, _, err := gitx.OutputExit(ctx.Logger, tmp, "-c", "core.quotepath=off", "diff", "--no-index", "--no-color", "--src-prefix=", "--dst-prefix=", "--", la, ra)
+			if err != nil {
+				return nil, err
+			}
+			fc.Patch = strings.TrimRight(patch, "\n")
+		}
+		out = append(out, fc)
+	}
+	return out, nil
+}
+
+func writeDiffSide(tmp, rel string, b []byte) error {
+	p := filepath.Join(tmp, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, b, 0o644)
+}
+
+// printFileChanges prints --stat and/or --diff output grouped by artifact.
+func printFileChanges(out io.Writer, meta models.SyncMetadata, changes []fileChange, stat, patch bool) {
+	groups := map[string][]fileChange{}
+	for _, c := range changes {
+		g := "(package)"
+		if keys := mapKeysToSortedSlice(detectChangedArtifacts(meta, []string{c.Path})); len(keys) > 0 {
+			g = keys[0].Kind + " - " + keys[0].ID
+		}
+		groups[g] = append(groups[g], c)
+	}
+	names := make([]string, 0, len(groups))
+	for g := range groups {
+		names = append(names, g)
+	}
+	sort.Strings(names)
+
+	totalAdded, totalRemoved := 0, 0
+	for _, g := range names {
+		fmt.Fprintf(out, "\n%s\n", g)
+		for _, c := range groups[g] {
+			totalAdded += c.Added
+			totalRemoved += c.Removed
+			if stat {
+				if c.Binary {
+					fmt.Fprintf(out, "  %-8s %s\n", "binary", c.Path)
+				} else {
+					fmt.Fprintf(out, "  %-8s %s\n", fmt.Sprintf("+%d -%d", c.Added, c.Removed), c.Path)
+				}
+			}
+			if patch && c.Patch != "" {
+				fmt.Fprintln(out, c.Patch)
+			}
+		}
+	}
+	if stat {
+		fmt.Fprintf(out, "\n%d file(s) changed, %d insertion(s), %d deletion(s)\n", len(changes), totalAdded, totalRemoved)
+	}
+}
+
+// prettyContent re-indents JSON and XML files so diffs show structural changes line by line.
+// Content that cannot be parsed is returned unchanged.
+func prettyContent(repoRel string, b []byte) []byte {
+	switch strings.ToLower(path.Ext(repoRel)) {
+	case ".json":
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, b, "", "  "); err == nil {
+			buf.WriteByte('\n')
+			return buf.Bytes()
+		}
+	case ".xml", ".iflw", ".mmap", ".xsd", ".wsdl", ".edmx", ".xsl", ".xslt", ".opmap", ".bpmn":
+		if f, err := formatXML(b); err == nil {
+			return f
+		}
+	}
+	return b
+}
+
+// formatXML writes one element per line with two-space indentation, keeping prefixes and attribute order.
+func formatXML(b []byte) ([]byte, error) {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	dec.Strict = false
+	var out bytes.Buffer
+	depth := 0
+	openTag := false // last token was a start element whose '>' is not written yet
+	text := ""
+	indent := func() {
+		if out.Len() > 0 {
+			out.WriteByte('\n')
+		}
+		out.WriteString(strings.Repeat("  ", depth))
+	}
+	// closeOpen finishes a pending start tag and writes its text inline (<a>text</a>).
+	closeOpen := func() {
+		if openTag {
+			out.WriteByte('>')
+			_ = xml.EscapeText(&out, []byte(text))
+			openTag = false
+		}
+		text = ""
+	}
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			closeOpen()
+			indent()
+			out.WriteString("<" + qualifiedName(t.Name))
+			for _, a := range t.Attr {
+				out.WriteString(" " + qualifiedName(a.Name) + `="`)
+				_ = xml.EscapeText(&out, []byte(a.Value))
+				out.WriteByte('"')
+			}
+			openTag = true
+			depth++
+		case xml.EndElement:
+			depth--
+			switch {
+			case openTag && text == "":
+				out.WriteString("/>")
+				openTag = false
+			case openTag:
+				closeOpen()
+				out.WriteString("</" + qualifiedName(t.Name) + ">")
+			default:
+				indent()
+				out.WriteString("</" + qualifiedName(t.Name) + ">")
+			}
+		case xml.CharData:
+			if s := strings.TrimSpace(string(t)); s != "" {
+				if !openTag {
+					indent()
+					_ = xml.EscapeText(&out, []byte(s))
+				} else {
+					text += s
+				}
+			}
+		case xml.Comment:
+			closeOpen()
+			indent()
+			out.WriteString("<!--" + string(t) + "-->")
+		case xml.ProcInst:
+			closeOpen()
+			indent()
+			out.WriteString("<?" + t.Target + " " + string(t.Inst) + "?>")
+		case xml.Directive:
+			closeOpen()
+			indent()
+			out.WriteString("<!" + string(t) + ">")
+		}
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+func qualifiedName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
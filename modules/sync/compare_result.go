@@ -0,0 +1,68 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// Compare modes.
+const (
+	compareModeBranch = "branch"
+	compareModeTenant = "tenant"
+)
+
+// CompareResult is the result of `sync compare`. Left is the a/ side of --diff
+// (origin/<to> or --tenant), Right the b/ side (the current branch or --against-tenant).
+type CompareResult struct {
+	Mode      string            `json:"mode"`
+	Left      string            `json:"left"`
+	Right     string            `json:"right"`
+	Paths     []string          `json:"paths"`
+	Artifacts []CompareArtifact `json:"artifacts"`
+	Files     []fileChange      `json:"files,omitempty"`
+
+	meta               models.SyncMetadata
+	showStat, showDiff bool
+}
+
+// CompareArtifact is one differing artifact. Difference and the versions are set in tenant mode only.
+type CompareArtifact struct {
+	Kind         string `json:"kind"`
+	ID           string `json:"id"`
+	Difference   string `json:"difference,omitempty"` // only-<env>|content|version
+	LeftVersion  string `json:"leftVersion,omitempty"`
+	RightVersion string `json:"rightVersion,omitempty"`
+}
+
+func (r CompareResult) ResultKind() string { return "SyncCompare" }
+
+func (r CompareResult) WriteTable(out io.Writer) error {
+	if r.Mode == compareModeTenant {
+		if len(r.Artifacts) == 0 {
+			fmt.Fprintf(out, "No artifact differences between CPI %s and CPI %s (after applying .iflowkit/ignore).\n", tenantDisplay(r.Left), tenantDisplay(r.Right))
+			return nil
+		}
+		fmt.Fprintf(out, "Artifact differences (after applying .iflowkit/ignore): CPI %s vs CPI %s\n", tenantDisplay(r.Left), tenantDisplay(r.Right))
+		fmt.Fprintf(out, "%-16s %-40s %-12s %-12s %s\n", "KIND", "ID", "DIFFERENCE", tenantDisplay(r.Left), tenantDisplay(r.Right))
+		for _, a := range r.Artifacts {
+			fmt.Fprintf(out, "%-16s %-40s %-12s %-12s %s\n", a.Kind, a.ID, a.Difference, orDash(a.LeftVersion), orDash(a.RightVersion))
+		}
+	} else {
+		if len(r.Paths) == 0 {
+			fmt.Fprintf(out, "No IntegrationPackage differences between %s and %s (after applying .iflowkit/ignore).\n", r.Right, r.Left)
+			return nil
+		}
+		fmt.Fprintf(out, "IntegrationPackage differences (after applying .iflowkit/ignore): %s vs %s\n", r.Right, r.Left)
+		if !r.showStat && !r.showDiff {
+			for _, a := range r.Artifacts {
+				fmt.Fprintf(out, "%s - %s\n", a.Kind, a.ID)
+			}
+		}
+	}
+	if r.showStat || r.showDiff {
+		printFileChanges(out, r.meta, r.Files, r.showStat, r.showDiff)
+	}
+	return nil
+}
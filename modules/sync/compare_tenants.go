@@ -0,0 +1,110 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// compareTenants exports both tenants to temp folders and reports artifact-level differences
+// and design-time Version mismatches. Package-level files (list JSONs, IntegrationPackage.json)
+// carry tenant URLs and are not compared.
+func compareTenants(ctx *app.Context, meta models.SyncMetadata, ign *RepoIgnore, filter *compareFilter, envA, envB string, showStat, showDiff bool) error {
+	ctx.Logger.Info("sync compare started", logging.F("tenant", envA), logging.F("againstTenant", envB))
+	tmp, err := os.MkdirTemp("", "iflowkit-compare-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	baseFolder := resolveContentFolder(meta)
+	rootA, rootB := filepath.Join(tmp, envA), filepath.Join(tmp, envB)
+	versions := map[string]map[artifactKey]string{}
+	for _, e := range []struct{ env, root string }{{envA, rootA}, {envB, rootB}} {
+		if err := exportTenantPackage(ctx, meta, e.env, filepath.Join(e.root, baseFolder)); err != nil {
+			return err
+		}
+		client, err := tenantClientForEnv(ctx, e.env)
+		if err != nil {
+			return err
+		}
+		if versions[e.env], err = tenantArtifactVersions(client, meta); err != nil {
+			return err
+		}
+	}
+
+	diffs, err := CompareFolderTrees(baseFolder, filepath.Join(rootA, baseFolder), filepath.Join(rootB, baseFolder), ign)
+	if err != nil {
+		return err
+	}
+	var paths []string
+	for _, p := range filter.filterPaths(meta, diffs) {
+		if len(detectChangedArtifacts(meta, []string{p})) > 0 {
+			paths = append(paths, p)
+		}
+	}
+
+	rows := map[artifactKey]string{}
+	for k := range detectChangedArtifacts(meta, paths) {
+		inA := dirExists(filepath.Join(rootA, baseFolder, k.Kind, k.ID))
+		inB := dirExists(filepath.Join(rootB, baseFolder, k.Kind, k.ID))
+		switch {
+		case inA && !inB:
+			rows[k] = "only-" + envA
+		case inB && !inA:
+			rows[k] = "only-" + envB
+		default:
+			rows[k] = "content"
+		}
+	}
+	for k, va := range versions[envA] {
+		if vb, ok := versions[envB][k]; ok && va != vb && filter.match(k) {
+			if _, listed := rows[k]; !listed {
+				rows[k] = "version"
+			}
+		}
+	}
+
+	res := CompareResult{Mode: compareModeTenant, Left: envA, Right: envB, Paths: paths, Artifacts: []CompareArtifact{}, meta: meta, showStat: showStat, showDiff: showDiff}
+	if res.Paths == nil {
+		res.Paths = []string{}
+	}
+	keys := make(map[artifactKey]struct{}, len(rows))
+	for k := range rows {
+		keys[k] = struct{}{}
+	}
+	for _, k := range mapKeysToSortedSlice(keys) {
+		res.Artifacts = append(res.Artifacts, CompareArtifact{Kind: k.Kind, ID: k.ID, Difference: rows[k], LeftVersion: versions[envA][k], RightVersion: versions[envB][k]})
+	}
+	if len(rows) > 0 && (showStat || showDiff) {
+		// a/ is --tenant, b/ is --against-tenant.
+		if res.Files, err = diffFiles(ctx, paths, folderSource(rootA), folderSource(rootB), showDiff); err != nil {
+			return err
+		}
+	}
+	return ctx.Render(res)
+}
+
+// tenantArtifactVersions returns the design-time Version of every versioned artifact of the package.
+func tenantArtifactVersions(client *cpix.Client, meta models.SyncMetadata) (map[artifactKey]string, error) {
+	out := map[artifactKey]string{}
+	for _, kind := range watchedKinds {
+		endpoint, ok := listEndpointForKind(meta.PackageID, kind)
+		if !ok {
+			continue
+		}
+		arts, err := client.ListArtifacts(context.Background(), endpoint)
+		if err != nil {
+			return nil, err
+		}
+		for id, a := range arts {
+			out[artifactKey{Kind: kind, ID: id}] = a.Version
+		}
+	}
+	return out, nil
+}
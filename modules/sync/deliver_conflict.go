@@ -0,0 +1,301 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+	"github.com/iflowkit/iflowkit-cli/internal/prompt"
+)
+
+// Conflict strategies of `sync deliver --strategy`. "manual" is recorded when a stopped
+// deliver is finished with --continue.
+const (
+	conflictTakeSource = "source"
+	conflictKeepTarget = "target"
+	conflictStop       = "stop"
+	conflictManual     = "manual"
+)
+
+// errDeliverStopped is returned when deliver leaves a prepared merge branch for manual resolution.
+var errDeliverStopped = errors.New("deliver stopped on merge conflicts")
+
+// DeliverConflict records how the merge conflicts of a deliver were resolved.
+type DeliverConflict struct {
+	Strategy    string        `json:"strategy"` // source | target | manual
+	Artifacts   []artifactKey `json:"artifacts"`
+	Paths       []string      `json:"paths,omitempty"` // conflicting files outside artifact folders
+	MergeBranch string        `json:"mergeBranch,omitempty"`
+	ResolvedBy  string        `json:"resolvedBy,omitempty"`
+	ResolvedAt  string        `json:"resolvedAt"`
+}
+
+// stoppedDeliver is kept in the git directory (never committed) while a conflicting merge
+// waits on its prepared branch for `sync deliver --continue` or `--abort`.
+type stoppedDeliver struct {
+	To           string        `json:"to"`
+	SourceBranch string        `json:"sourceBranch"`
+	SourceCommit string        `json:"sourceCommit"`
+	TargetBranch string        `json:"targetBranch"`
+	PreMerge     string        `json:"preMerge"`
+	MergeBranch  string        `json:"mergeBranch"`
+	TransportID  string        `json:"transportId"`
+	CreatedAt    string        `json:"createdAt"`
+	Message      string        `json:"message,omitempty"`
+	Artifacts    []artifactKey `json:"artifacts"`
+	Paths        []string      `json:"paths,omitempty"`
+	StoppedAt    string        `json:"stoppedAt"`
+}
+
+func normalizeConflictStrategy(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "", conflictTakeSource, conflictKeepTarget, conflictStop:
+		return s, nil
+	default:
+		return "", fmt.Errorf("invalid --strategy %q: expected source, target or stop", s)
+	}
+}
+
+// mergeConflicts lists the unmerged paths of the current merge, grouped into artifact folders
+// and other files.
+func mergeConflicts(ctx *app.Context, repoRoot string, meta models.SyncMetadata) ([]artifactKey, []string, error) {
+	out, err := runGitOutput(ctx, repoRoot, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, nil, err
+	}
+	var other []string
+	keys := map[artifactKey]struct{}{}
+	for _, p := range splitLines(out) {
+		found := detectChangedArtifacts(meta, []string{p})
+		if len(found) == 0 {
+			other = append(other, p)
+		}
+		for k := range found {
+			keys[k] = struct{}{}
+		}
+	}
+	return mapKeysToSortedSlice(keys), other, nil
+}
+
+func printMergeConflicts(ctx *app.Context, meta models.SyncMetadata, source, target string, keys []artifactKey, other []string) {
+	out := ctx.Stdout
+	fmt.Fprintf(out, "Merging %s into %s conflicts in %d artifact folder(s):\n", source, target, len(keys))
+	for _, k := range keys {
+		fmt.Fprintf(out, "  %s\n", artifactRepoDir(meta, k))
+	}
+	if len(other) > 0 {
+		fmt.Fprintf(out, "Other conflicting files (%d):\n", len(other))
+		for _, p := range other {
+			fmt.Fprintf(out, "  %s\n", p)
+		}
+	}
+}
+
+// chooseConflictStrategy returns --strategy, asks on a terminal, or stops when neither is possible.
+func chooseConflictStrategy(ctx *app.Context, strategy, source, target string) (string, error) {
+	if strategy != "" {
+		return strategy, nil
+	}
+	if !prompt.IsTerminal(ctx.Stdin) {
+		return conflictStop, nil
+	}
+	io := prompt.NewIO(ctx.Stdin, ctx.Stdout)
+	label := fmt.Sprintf("Resolve conflicting artifacts: [s] take %s, [t] keep %s, [x] stop and leave a merge branch", source, target)
+	answer, err := io.AskString(label, nil, func(v string) error {
+		switch strings.ToLower(v) {
+		case "s", "t", "x":
+			return nil
+		}
+		return fmt.Errorf("please answer s, t or x")
+	})
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(answer) {
+	case "s":
+		return conflictTakeSource, nil
+	case "t":
+		return conflictKeepTarget, nil
+	default:
+		return conflictStop, nil
+	}
+}
+
+// resolveDeliverConflicts handles a failed `git merge` of source into the checked-out target.
+// With source/target the conflicting artifact folders are replaced as a whole from that side and
+// the merge is committed. With stop the merge is redone on a prepared branch and left for the user.
+func resolveDeliverConflicts(ctx *app.Context, repoRoot string, meta models.SyncMetadata, strategy string, st stoppedDeliver, mergeErr error) (*DeliverConflict, error) {
+	keys, other, err := mergeConflicts(ctx, repoRoot, meta)
+	if err != nil || len(keys)+len(other) == 0 {
+		_ = runGit(ctx, repoRoot, "merge", "--abort")
+		return nil, mergeErr
+	}
+	printMergeConflicts(ctx, meta, st.SourceBranch, st.TargetBranch, keys, other)
+	if strategy, err = chooseConflictStrategy(ctx, strategy, st.SourceBranch, st.TargetBranch); err != nil {
+		_ = runGit(ctx, repoRoot, "merge", "--abort")
+		return nil, err
+	}
+	ctx.Logger.Info("resolving deliver conflicts", logging.F("strategy", strategy), logging.F("artifacts", len(keys)), logging.F("files", len(other)))
+
+	if strategy == conflictStop {
+		_ = runGit(ctx, repoRoot, "merge", "--abort")
+		st.Artifacts, st.Paths = keys, other
+		return nil, stopDeliverOnBranch(ctx, repoRoot, st)
+	}
+
+	ref, side := st.SourceBranch, "--theirs"
+	if strategy == conflictKeepTarget {
+		ref, side = "HEAD", "--ours"
+	}
+	if err := checkoutSelectedArtifacts(ctx, repoRoot, meta, ref, keys); err != nil {
+		_ = runGit(ctx, repoRoot, "merge", "--abort")
+		return nil, err
+	}
+	for _, p := range other {
+		if err := runGit(ctx, repoRoot, "checkout", side, "--", p); err != nil {
+			// Deleted on that side.
+			_ = runGit(ctx, repoRoot, "rm", "-q", "--", p)
+			continue
+		}
+		_ = runGit(ctx, repoRoot, "add", "--", p)
+	}
+	if err := runGit(ctx, repoRoot, "commit", "--no-edit"); err != nil {
+		_ = runGit(ctx, repoRoot, "merge", "--abort")
+		return nil, err
+	}
+	name, _, _ := gitUserIdentity(ctx, repoRoot)
+	return &DeliverConflict{Strategy: strategy, Artifacts: keys, Paths: other, ResolvedBy: name, ResolvedAt: time.Now().UTC().Format(time.RFC3339)}, nil
+}
+
+// stopDeliverOnBranch redoes the merge on deliver/<to>/<transportId>, leaves the conflicts in the
+// working tree and remembers the state for --continue.
+func stopDeliverOnBranch(ctx *app.Context, repoRoot string, st stoppedDeliver) error {
+	st.MergeBranch = "deliver/" + st.To + "/" + st.TransportID
+	st.StoppedAt = time.Now().UTC().Format(time.RFC3339)
+	if err := runGit(ctx, repoRoot, "checkout", "-q", "-b", st.MergeBranch, st.PreMerge); err != nil {
+		return err
+	}
+	mergeMsg := buildTransportCommitMessage(st.TransportID, "deliver", "contents", st.Message)
+	_ = runGit(ctx, repoRoot, "merge", "--no-ff", "-m", mergeMsg, st.SourceBranch) // expected to conflict
+	if err := writeStoppedDeliver(ctx, repoRoot, st); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Stdout, "\nDeliver stopped. The merge is prepared on branch %s with the conflicts above.\n", st.MergeBranch)
+	fmt.Fprintln(ctx.Stdout, "Resolve them, `git add` the files and `git commit`, then run:")
+	fmt.Fprintf(ctx.Stdout, "  iflowkit sync deliver --to %s --continue\n", st.To)
+	fmt.Fprintf(ctx.Stdout, "To give up instead: iflowkit sync deliver --to %s --abort\n", st.To)
+	return fmt.Errorf("%w: resolve them on branch %s", errDeliverStopped, st.MergeBranch)
+}
+
+// continueStoppedDeliver checks that the prepared merge was committed and fast-forwards the target
+// branch (checked out at PreMerge) to it.
+func continueStoppedDeliver(ctx *app.Context, repoRoot string, st stoppedDeliver) error {
+	for _, c := range []string{st.PreMerge, st.SourceCommit} {
+		if err := runGit(ctx, repoRoot, "merge-base", "--is-ancestor", c, st.MergeBranch); err != nil {
+			return fmt.Errorf("branch %s does not contain the prepared merge of %s into %s; commit the resolution there first", st.MergeBranch, st.SourceBranch, st.TargetBranch)
+		}
+	}
+	head, _ := runGitOutput(ctx, repoRoot, "rev-parse", "HEAD")
+	if strings.TrimSpace(head) != st.PreMerge {
+		return fmt.Errorf("%s moved since deliver stopped (was %s); run `iflowkit sync deliver --to %s --abort` and deliver again", st.TargetBranch, shortSHA(st.PreMerge), st.To)
+	}
+	if err := runGit(ctx, repoRoot, "merge", "--ff-only", st.MergeBranch); err != nil {
+		return err
+	}
+	_ = runGit(ctx, repoRoot, "branch", "-D", st.MergeBranch)
+	return nil
+}
+
+// abortStoppedDeliver removes the prepared merge branch and the saved state.
+func abortStoppedDeliver(ctx *app.Context, repoRoot string, st stoppedDeliver) error {
+	if branch, _ := gitCurrentBranch(ctx, repoRoot); branch == st.MergeBranch {
+		_ = runGit(ctx, repoRoot, "merge", "--abort")
+		if err := runGit(ctx, repoRoot, "checkout", "-q", st.TargetBranch); err != nil {
+			return err
+		}
+	}
+	_ = runGit(ctx, repoRoot, "branch", "-D", st.MergeBranch)
+	return removeStoppedDeliver(ctx, repoRoot, st.To)
+}
+
+// checkStoppedDeliverCommitted gives a precise error while the user is still inside the merge.
+func checkStoppedDeliverCommitted(ctx *app.Context, repoRoot string, st stoppedDeliver) error {
+	if branch, _ := gitCurrentBranch(ctx, repoRoot); branch != st.MergeBranch {
+		return nil
+	}
+	if _, err := runGitOutput(ctx, repoRoot, "rev-parse", "-q", "--verify", "MERGE_HEAD"); err == nil {
+		out, _ := runGitOutput(ctx, repoRoot, "diff", "--name-only", "--diff-filter=U")
+		if n := len(splitLines(out)); n > 0 {
+			return fmt.Errorf("the merge on %s still has %d unresolved path(s); resolve them, git add and git commit first", st.MergeBranch, n)
+		}
+		return fmt.Errorf("the merge on %s is not committed yet; run git commit first", st.MergeBranch)
+	}
+	return nil
+}
+
+func (st stoppedDeliver) conflict(ctx *app.Context, repoRoot string) *DeliverConflict {
+	name, _, _ := gitUserIdentity(ctx, repoRoot)
+	return &DeliverConflict{Strategy: conflictManual, Artifacts: st.Artifacts, Paths: st.Paths, MergeBranch: st.MergeBranch, ResolvedBy: name, ResolvedAt: time.Now().UTC().Format(time.RFC3339)}
+}
+
+func stoppedDeliverPath(ctx *app.Context, repoRoot, to string) (string, error) {
+	lockPath, err := syncLockPath(ctx, repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(lockPath), "iflowkit-deliver-"+to+".json"), nil
+}
+
+// readStoppedDeliver returns the saved state for target env to, if any.
+func readStoppedDeliver(ctx *app.Context, repoRoot, to string) (*stoppedDeliver, error) {
+	p, err := stoppedDeliverPath(ctx, repoRoot, to)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var st stoppedDeliver
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", p, err)
+	}
+	return &st, nil
+}
+
+func writeStoppedDeliver(ctx *app.Context, repoRoot string, st stoppedDeliver) error {
+	p, err := stoppedDeliverPath(ctx, repoRoot, st.To)
+	if err != nil {
+		return err
+	}
+	b, _ := json.MarshalIndent(st, "", "  ")
+	return os.WriteFile(p, append(b, '\n'), 0o644)
+}
+
+func removeStoppedDeliver(ctx *app.Context, repoRoot, to string) error {
+	p, err := stoppedDeliverPath(ctx, repoRoot, to)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 10 {
+		return sha[:10]
+	}
+	return sha
+}
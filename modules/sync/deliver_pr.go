@@ -0,0 +1,467 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/git"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// DeliverPullRequest records the pull/merge request a deliver was reviewed in.
+type DeliverPullRequest struct {
+	Provider    string   `json:"provider"`
+	Number      int      `json:"number"`
+	URL         string   `json:"url,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	ApprovedBy  []string `json:"approvedBy"`
+	MergeCommit string   `json:"mergeCommit"`
+}
+
+// openedDeliverPR is kept in the git directory (never committed) from `sync deliver --via-pr`
+// until `--complete` delivered or dropped the pull request.
+type openedDeliverPR struct {
+	To           string `json:"to"`
+	Provider     string `json:"provider"`
+	Number       int    `json:"number"`
+	URL          string `json:"url"`
+	SourceBranch string `json:"sourceBranch"`
+	TargetBranch string `json:"targetBranch"`
+	OpenedAt     string `json:"openedAt"`
+}
+
+// repoProvider is the git provider of the sync repository with the coordinates of its remote.
+type repoProvider struct {
+	git.Provider
+	token     string
+	host      string
+	namespace string
+	repo      string
+}
+
+func newRepoProvider(meta models.SyncMetadata) (*repoProvider, error) {
+	p := git.NewProvider(meta.GitProvider)
+	if p == nil {
+		return nil, fmt.Errorf("pull requests are not supported for git provider %q", meta.GitProvider)
+	}
+	ns, repo, err := git.SplitRemoteNamespaceAndRepo(meta.GitRemote)
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate the repository for pull requests: %w", err)
+	}
+	host, err := git.RemoteHost(meta.GitRemote)
+	if err != nil {
+		return nil, err
+	}
+	token, err := git.ResolveToken(meta.GitProvider)
+	if err != nil {
+		return nil, err
+	}
+	return &repoProvider{Provider: p, token: token, host: host, namespace: ns, repo: repo}, nil
+}
+
+func (p *repoProvider) create(in git.PullRequestInput) (git.PullRequest, error) {
+	return p.CreatePullRequest(context.Background(), p.token, p.host, p.namespace, p.repo, in)
+}
+
+func (p *repoProvider) get(number int) (git.PullRequest, error) {
+	return p.GetPullRequest(context.Background(), p.token, p.host, p.namespace, p.repo, number)
+}
+
+func (p *repoProvider) approvals(pr git.PullRequest) (git.PullRequestApprovals, error) {
+	return p.PullRequestApprovals(context.Background(), p.token, p.host, p.namespace, p.repo, pr.Number, pr.HeadSHA)
+}
+
+func (p *repoProvider) merge(pr git.PullRequest, message string) error {
+	return p.MergePullRequest(context.Background(), p.token, p.host, p.namespace, p.repo, pr.Number, pr.HeadSHA, message)
+}
+
+// openDeliverPR implements `sync deliver --via-pr`: it checks the deliver plan and opens a pull
+// request from the source to the target branch. Nothing is merged and CPI is not touched.
+func openDeliverPR(ctx *app.Context, repoRoot string, meta models.SyncMetadata, to, sourceBranch, targetBranch, message string, lockWait time.Duration) error {
+	if opened, err := readOpenedDeliverPR(ctx, repoRoot, to); err != nil {
+		return err
+	} else if opened != nil {
+		return fmt.Errorf("pull request #%d for deliver to %s is already open (%s); finish it with `iflowkit sync deliver --to %s --complete`", opened.Number, to, opened.URL, to)
+	}
+	prov, err := newRepoProvider(meta)
+	if err != nil {
+		return err
+	}
+	if dirty := gitPorcelainPaths(ctx, repoRoot); len(dirty) > 0 {
+		return fmt.Errorf("working tree is not clean (%d paths). commit/stash changes before running deliver", len(dirty))
+	}
+
+	releaseLock, err := acquireSyncLock(ctx, repoRoot, "deliver", to, lockWait)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	originalBranch, _ := gitCurrentBranch(ctx, repoRoot)
+	defer func() {
+		if originalBranch != "" {
+			_ = runGit(ctx, repoRoot, "checkout", originalBranch)
+		}
+	}()
+	if err := ensureDeliverBranches(ctx, repoRoot, meta, to, sourceBranch); err != nil {
+		return err
+	}
+
+	plan, err := planSyncDeliver(ctx, repoRoot, meta, to, sourceBranch, targetBranch, message, nil)
+	if err != nil {
+		return err
+	}
+	if n := plan.failedChecks(); n > 0 {
+		return fmt.Errorf("cannot open a pull request: %d preflight check(s) failed; run with --dry-run for details", n)
+	}
+	if plan.ResumeTransport != "" {
+		return fmt.Errorf("deliver transport %s is still pending; run `iflowkit sync transport retry %s` first", plan.ResumeTransport, plan.ResumeTransport)
+	}
+	if len(plan.Commits) == 0 {
+		return fmt.Errorf("nothing to deliver: %s has no commits that are not in %s", sourceBranch, targetBranch)
+	}
+
+	title := fmt.Sprintf("iFlowKit deliver %s: %s -> %s", meta.PackageID, sourceBranch, targetBranch)
+	if message != "" {
+		title += " (" + message + ")"
+	}
+	pr, err := prov.create(git.PullRequestInput{Title: title, Body: deliverPRBody(plan, to), Head: sourceBranch, Base: targetBranch})
+	if err != nil {
+		return err
+	}
+	opened := openedDeliverPR{
+		To: to, Provider: prov.Name(), Number: pr.Number, URL: pr.URL,
+		SourceBranch: sourceBranch, TargetBranch: targetBranch, OpenedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := writeOpenedDeliverPR(ctx, repoRoot, opened); err != nil {
+		return err
+	}
+	ctx.Logger.Info("deliver pull request opened", logging.F("provider", prov.Name()), logging.F("number", pr.Number), logging.F("url", pr.URL), logging.F("from", sourceBranch), logging.F("to", targetBranch))
+	return ctx.Render(SyncRunResult{
+		Command: "deliver", Tenant: to, Branch: targetBranch,
+		Message: fmt.Sprintf("Opened pull request #%d (%s -> %s): %s\nNothing was merged and CPI %s was not changed. After approval run `iflowkit sync deliver --to %s --complete`.", pr.Number, sourceBranch, targetBranch, pr.URL, tenantDisplay(to), to),
+	})
+}
+
+// deliverPRBody renders the deliver plan as the pull request description (Markdown).
+func deliverPRBody(p *SyncPlan, to string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Transport plan for `%s`: `%s` -> `%s` (CPI %s), generated by iFlowKit.\n", p.PackageID, p.SourceBranch, p.Branch, tenantDisplay(to))
+
+	b.WriteString("\n### Commits\n\n")
+	for _, c := range p.Commits {
+		if !c.Planned {
+			fmt.Fprintf(&b, "- `%s` %s\n", shortSHA(c.SHA), c.Subject)
+		}
+	}
+
+	b.WriteString("\n### CPI changes\n\n")
+	rows := append(append(append([]PlanArtifact{}, p.Delete...), p.Upload...), p.Deploy...)
+	if len(rows) == 0 {
+		b.WriteString("None.\n")
+	} else {
+		b.WriteString("| Action | Kind | ID | Note |\n|---|---|---|---|\n")
+		for _, a := range rows {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", a.Action, a.Kind, a.ID, a.Reason)
+		}
+	}
+
+	b.WriteString("\n### Preflight\n\n")
+	for _, c := range p.Preflight {
+		line := fmt.Sprintf("- %s %s", strings.ToUpper(c.Status), c.Name)
+		if c.Detail != "" {
+			line += ": " + c.Detail
+		}
+		b.WriteString(line + "\n")
+	}
+
+	fmt.Fprintf(&b, "\nThe tenant is updated only by `iflowkit sync deliver --to %s --complete`, which requires this pull request to be approved and merges it if it is still open.\n", to)
+	return b.String()
+}
+
+// deliverPR is a pull request `sync deliver --complete` works on.
+type deliverPR struct {
+	prov      *repoProvider
+	pr        git.PullRequest
+	approvals git.PullRequestApprovals
+}
+
+// loadDeliverPR reads pull request number, or the one opened by --via-pr in this clone.
+func loadDeliverPR(ctx *app.Context, repoRoot string, meta models.SyncMetadata, to, targetBranch string, number int) (*deliverPR, error) {
+	if number == 0 {
+		opened, err := readOpenedDeliverPR(ctx, repoRoot, to)
+		if err != nil {
+			return nil, err
+		}
+		if opened == nil {
+			return nil, fmt.Errorf("no pull request was opened for deliver to %s from this clone; pass --pr <number>", to)
+		}
+		number = opened.Number
+	}
+	prov, err := newRepoProvider(meta)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := prov.get(number)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Base != targetBranch {
+		return nil, fmt.Errorf("pull request #%d targets %s, not %s", number, pr.Base, targetBranch)
+	}
+	approvals, err := prov.approvals(pr)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Logger.Info("deliver pull request loaded", logging.F("number", number), logging.F("state", pr.State), logging.F("approved", approvals.Approved), logging.F("approvedBy", strings.Join(approvals.ApprovedBy, ",")))
+	return &deliverPR{prov: prov, pr: pr, approvals: approvals}, nil
+}
+
+// gate returns why the tenant must not be updated from d, or nil.
+func (d *deliverPR) gate() error {
+	switch {
+	case d.pr.State == git.PullRequestClosed:
+		return fmt.Errorf("pull request #%d was closed without merging; nothing to deliver", d.pr.Number)
+	case (!d.approvals.Approved || len(d.approvals.ApprovedBy) == 0) && len(d.approvals.Outdated) > 0:
+		return fmt.Errorf("pull request #%d is not approved on its head commit %s; approval(s) by %s were given before later pushes and must be renewed (%s)", d.pr.Number, shortSHA(d.pr.HeadSHA), strings.Join(d.approvals.Outdated, ", "), d.pr.URL)
+	case !d.approvals.Approved || len(d.approvals.ApprovedBy) == 0:
+		return fmt.Errorf("pull request #%d is not approved yet (%s); the tenant is only updated after approval", d.pr.Number, d.pr.URL)
+	}
+	return nil
+}
+
+// checkNotDelivered fails when a deliver record of the checked-out target branch already
+// references the pull request, e.g. when it was completed from another clone.
+func (d *deliverPR) checkNotDelivered(ctx *app.Context, repoRoot string, store *TransportStore, to string) error {
+	recs, err := store.ListRecords()
+	if err != nil {
+		return err
+	}
+	for _, r := range recs {
+		if r.TransportType == "deliver" && r.PullRequest != nil && r.PullRequest.Provider == d.prov.Name() && r.PullRequest.Number == d.pr.Number {
+			if err := removeOpenedDeliverPR(ctx, repoRoot, to); err != nil {
+				return err
+			}
+			return fmt.Errorf("pull request #%d was already delivered to %s by transport %s", d.pr.Number, tenantDisplay(to), r.TransportID)
+		}
+	}
+	return nil
+}
+
+func (d *deliverPR) record() *DeliverPullRequest {
+	return &DeliverPullRequest{
+		Provider: d.prov.Name(), Number: d.pr.Number, URL: d.pr.URL, Author: d.pr.Author,
+		ApprovedBy: append([]string{}, d.approvals.ApprovedBy...), MergeCommit: d.pr.MergeCommitSHA,
+	}
+}
+
+// base is the target commit the pull request was (or will be) merged onto: the first parent of a
+// merge commit, the provider's base commit for squash/rebase merges, and origin/<target> while open.
+func (d *deliverPR) base(ctx *app.Context, repoRoot string) string {
+	if d.pr.State != git.PullRequestMerged {
+		return "origin/" + d.pr.Base
+	}
+	out, err := runGitOutput(ctx, repoRoot, "rev-list", "--parents", "-n", "1", d.pr.MergeCommitSHA)
+	if fields := strings.Fields(out); err == nil && len(fields) == 3 {
+		return fields[1]
+	}
+	return d.pr.BaseSHA
+}
+
+// head is the commit the target branch has after the merge (the source tip while open).
+func (d *deliverPR) head() string {
+	if d.pr.State == git.PullRequestMerged {
+		return d.pr.MergeCommitSHA
+	}
+	return "origin/" + d.pr.Head
+}
+
+// unreviewedChanges lists content paths that changed on tip after the pull request's merge commit,
+// i.e. changes of other merges that this pull request's approval does not cover.
+func (d *deliverPR) unreviewedChanges(ctx *app.Context, repoRoot string, meta models.SyncMetadata, ign *RepoIgnore, tip string) []string {
+	out, _ := runGitOutput(ctx, repoRoot, "diff", "--name-only", d.pr.MergeCommitSHA+".."+tip, "--", resolveContentFolder(meta))
+	return ign.Filter(splitLines(out))
+}
+
+// planDeliverPR computes what `sync deliver --complete` would do for d.
+func planDeliverPR(ctx *app.Context, repoRoot string, meta models.SyncMetadata, to, targetBranch, message string, d *deliverPR) (*SyncPlan, error) {
+	p := newSyncPlan("deliver", meta.PackageID, targetBranch, to)
+	p.SourceBranch = d.pr.Head
+	_ = runGit(ctx, repoRoot, "fetch", "origin") // best-effort, read-only on the remote
+
+	detail := fmt.Sprintf("#%d %s", d.pr.Number, d.pr.State)
+	if len(d.approvals.ApprovedBy) > 0 {
+		detail += ", approved by " + strings.Join(d.approvals.ApprovedBy, ", ")
+	}
+	if err := d.gate(); err != nil {
+		p.check("pull request", planCheckFail, err.Error())
+		return p, nil
+	}
+	p.check("pull request", planCheckOK, detail)
+
+	base, head := d.base(ctx, repoRoot), d.head()
+	if !gitRefExists(ctx, repoRoot, base) || !gitRefExists(ctx, repoRoot, head) {
+		p.check("commits", planCheckFail, fmt.Sprintf("%s or the base of #%d not found after fetch", head, d.pr.Number))
+		return p, nil
+	}
+
+	store, err := NewTransportStore(repoRoot, to)
+	if err != nil {
+		return nil, err
+	}
+	if pendingRec, _, hasPending, err := store.LoadLatestPendingTransport(meta.PackageID, targetBranch, "deliver"); err != nil {
+		return nil, err
+	} else if hasPending {
+		p.ResumeTransport = pendingRec.TransportID
+		p.note("pull request already delivered to git by the pending transport; only remaining CPI work would run")
+		client := planTenantClient(ctx, p, to)
+		planCPIActions(ctx, p, client, meta, pendingRec.UploadRemaining, pendingRec.DeleteRemaining, pendingRec.DeployRemaining, func(k artifactKey) bool {
+			return gitRefHasDir(ctx, repoRoot, "origin/"+targetBranch, filepath.ToSlash(filepath.Join(meta.BaseFolder, k.Kind, k.ID)))
+		})
+		return p, nil
+	}
+
+	ign, err := LoadRepoIgnore(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	planTenantMatchesRef(ctx, p, repoRoot, meta, to, base, ign)
+	if d.pr.State == git.PullRequestMerged {
+		if later := d.unreviewedChanges(ctx, repoRoot, meta, ign, "origin/"+targetBranch); len(later) > 0 {
+			p.check("later merges", planCheckFail, fmt.Sprintf("%s changed after #%d was merged: %s", targetBranch, d.pr.Number, strings.Join(samplePaths(later, 10), ", ")))
+		}
+	}
+
+	commitsOut, _ := runGitOutput(ctx, repoRoot, "rev-list", "--reverse", base+".."+head)
+	p.Commits = append(p.Commits, planCommitSummaries(ctx, repoRoot, splitLines(commitsOut))...)
+	if d.pr.State == git.PullRequestOpen {
+		p.Commits = append(p.Commits, PlanCommit{Subject: buildTransportCommitMessage("<new>", "deliver", "contents", message), Planned: true})
+		p.note("pull request #%d would be merged by iFlowKit", d.pr.Number)
+	}
+
+	baseFolder := resolveContentFolder(meta)
+	diffOut, _ := runGitOutput(ctx, repoRoot, "diff", "--name-only", base+"..."+head, "--", baseFolder)
+	keys := detectChangedArtifacts(meta, ign.Filter(splitLines(diffOut)))
+	uploads, deletes := []artifactKey{}, []artifactKey{}
+	for _, k := range mapKeysToSortedSlice(keys) {
+		if gitRefHasDir(ctx, repoRoot, head, filepath.ToSlash(filepath.Join(baseFolder, k.Kind, k.ID))) {
+			uploads = append(uploads, k)
+		} else if isDeployableKind(k.Kind) {
+			deletes = append(deletes, k)
+		}
+	}
+	if len(uploads) > 0 || len(deletes) > 0 {
+		client := planTenantClient(ctx, p, to)
+		planCPIActions(ctx, p, client, meta, uploads, deletes, nil, func(k artifactKey) bool {
+			return gitRefHasDir(ctx, repoRoot, head, filepath.ToSlash(filepath.Join(meta.BaseFolder, k.Kind, k.ID)))
+		})
+	}
+	p.Commits = append(p.Commits, PlanCommit{Subject: buildTransportCommitMessage("<new>", "deliver", "logs", message), Planned: true})
+	p.note("tag %s would be created on success", transportTagName("<new>", targetBranch))
+	return p, nil
+}
+
+// completeDeliverPR brings the merged pull request into the local target branch: it checks that
+// the tenant matches the branch before the merge, merges an open pull request with mergeMsg and
+// checks out the merged target. Content merged onto the target after the pull request is refused:
+// it was not reviewed in it. It returns the pre-merge commit.
+func completeDeliverPR(ctx *app.Context, repoRoot string, meta models.SyncMetadata, ign *RepoIgnore, to, targetBranch, mergeMsg string, d *deliverPR) (string, error) {
+	_ = runGit(ctx, repoRoot, "fetch", "origin")
+	base := d.base(ctx, repoRoot)
+	if !gitRefExists(ctx, repoRoot, base) {
+		return "", fmt.Errorf("cannot determine the base commit of pull request #%d", d.pr.Number)
+	}
+	var diffPaths []string
+	eq := false
+	if err := withRefWorktree(ctx, repoRoot, base, func(dir string) error {
+		var err error
+		eq, diffPaths, err = compareTenantWithCurrentBranch(ctx, dir, meta, to, ign)
+		return err
+	}); err != nil {
+		return "", err
+	}
+	if !eq {
+		return "", fmt.Errorf("%s tenant and %s before the merge (%s) differ (after applying .iflowkit/ignore). first diffs: %s", tenantDisplay(to), targetBranch, shortSHA(base), strings.Join(samplePaths(diffPaths, 10), ", "))
+	}
+
+	if d.pr.State == git.PullRequestOpen {
+		ctx.Logger.Info("merging pull request", logging.F("number", d.pr.Number), logging.F("url", d.pr.URL))
+		if err := d.prov.merge(d.pr, mergeMsg); err != nil {
+			return "", err
+		}
+		pr, err := d.prov.get(d.pr.Number)
+		if err != nil {
+			return "", err
+		}
+		if pr.State != git.PullRequestMerged || pr.MergeCommitSHA == "" {
+			return "", fmt.Errorf("pull request #%d is %s after merging; expected merged", pr.Number, pr.State)
+		}
+		d.pr = pr
+	}
+
+	if err := ensureBranchFetchedAndCheckedOut(ctx, repoRoot, targetBranch); err != nil {
+		return "", err
+	}
+	if err := runGit(ctx, repoRoot, "merge-base", "--is-ancestor", d.pr.MergeCommitSHA, "HEAD"); err != nil {
+		return "", fmt.Errorf("merge commit %s of pull request #%d is not on %s", shortSHA(d.pr.MergeCommitSHA), d.pr.Number, targetBranch)
+	}
+	if later := d.unreviewedChanges(ctx, repoRoot, meta, ign, "HEAD"); len(later) > 0 {
+		return "", fmt.Errorf("%s has content changes merged after pull request #%d that were not reviewed in it; complete the pull requests in merge order before anything else is merged. first diffs: %s", targetBranch, d.pr.Number, strings.Join(samplePaths(later, 10), ", "))
+	}
+	return d.base(ctx, repoRoot), nil
+}
+
+func openedDeliverPRPath(ctx *app.Context, repoRoot, to string) (string, error) {
+	lockPath, err := syncLockPath(ctx, repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(lockPath), "iflowkit-deliver-pr-"+to+".json"), nil
+}
+
+func readOpenedDeliverPR(ctx *app.Context, repoRoot, to string) (*openedDeliverPR, error) {
+	p, err := openedDeliverPRPath(ctx, repoRoot, to)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var st openedDeliverPR
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", p, err)
+	}
+	return &st, nil
+}
+
+func writeOpenedDeliverPR(ctx *app.Context, repoRoot string, st openedDeliverPR) error {
+	p, err := openedDeliverPRPath(ctx, repoRoot, st.To)
+	if err != nil {
+		return err
+	}
+	b, _ := json.MarshalIndent(st, "", "  ")
+	return os.WriteFile(p, append(b, '\n'), 0o644)
+}
+
+func removeOpenedDeliverPR(ctx *app.Context, repoRoot, to string) error {
+	p, err := openedDeliverPRPath(ctx, repoRoot, to)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package sync
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iflowkit/iflowkit-cli/internal/git"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+func TestDeliverPRGate(t *testing.T) {
+	approved := git.PullRequestApprovals{Approved: true, ApprovedBy: []string{"lead"}}
+	cases := []struct {
+		name      string
+		state     string
+		approvals git.PullRequestApprovals
+		wantErr   string
+	}{
+		{"open approved", git.PullRequestOpen, approved, ""},
+		{"merged approved", git.PullRequestMerged, approved, ""},
+		{"closed", git.PullRequestClosed, approved, "closed without merging"},
+		{"not approved", git.PullRequestOpen, git.PullRequestApprovals{ApprovedBy: []string{"lead"}}, "not approved"},
+		{"merged without review", git.PullRequestMerged, git.PullRequestApprovals{}, "not approved"},
+		// GitLab projects without approval rules report approved with nobody approving.
+		{"approved by nobody", git.PullRequestOpen, git.PullRequestApprovals{Approved: true}, "not approved"},
+		{"approval of an earlier head", git.PullRequestOpen, git.PullRequestApprovals{Outdated: []string{"lead"}}, "lead were given before later pushes"},
+		{"outdated approval next to a current one", git.PullRequestOpen, git.PullRequestApprovals{Approved: true, ApprovedBy: []string{"qa"}, Outdated: []string{"lead"}}, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &deliverPR{pr: git.PullRequest{Number: 3, State: tc.state}, approvals: tc.approvals}
+			err := d.gate()
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("gate() = %v, want nil", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Errorf("gate() = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// prHistory builds main: A -- C -- M (merge of dev: B) -- S (squash of dev2: D) and returns the SHAs.
+func prHistory(r *testRepo) map[string]string {
+	sha := map[string]string{}
+	sha["A"] = r.commit("A", map[string]string{"IntegrationPackage/IntegrationFlow/F1/f.txt": "1"})
+	r.git("checkout", "-q", "-b", "dev")
+	sha["B"] = r.commit("B", map[string]string{"IntegrationPackage/IntegrationFlow/F1/f.txt": "2"})
+	r.git("checkout", "-q", "main")
+	sha["C"] = r.commit("C", map[string]string{"IntegrationPackage/ValueMapping/V1/v.txt": "1"})
+	r.git("merge", "-q", "--no-ff", "-m", "M", "dev")
+	sha["M"] = r.git("rev-parse", "HEAD")
+	r.git("checkout", "-q", "-b", "dev2")
+	r.commit("D", map[string]string{"IntegrationPackage/IntegrationFlow/F2/f.txt": "1"})
+	r.git("checkout", "-q", "main")
+	r.git("merge", "-q", "--squash", "dev2")
+	sha["S"] = r.commit("S", nil)
+	return sha
+}
+
+func TestDeliverPRBaseHead(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := newTestContext(t)
+	sha := prHistory(r)
+
+	cases := []struct {
+		name string
+		pr   git.PullRequest
+		base string
+		head string
+	}{
+		{
+			name: "open",
+			pr:   git.PullRequest{State: git.PullRequestOpen, Head: "dev", Base: "main", BaseSHA: sha["A"]},
+			base: "origin/main", head: "origin/dev",
+		},
+		{
+			name: "merge commit",
+			pr:   git.PullRequest{State: git.PullRequestMerged, Head: "dev", Base: "main", BaseSHA: sha["A"], MergeCommitSHA: sha["M"]},
+			// The first parent is the target before the merge, not the (older) provider base.
+			base: sha["C"], head: sha["M"],
+		},
+		{
+			name: "squash merge",
+			pr:   git.PullRequest{State: git.PullRequestMerged, Head: "dev2", Base: "main", BaseSHA: sha["M"], MergeCommitSHA: sha["S"]},
+			base: sha["M"], head: sha["S"],
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &deliverPR{pr: tc.pr}
+			if got := d.base(ctx, r.dir); got != tc.base {
+				t.Errorf("base() = %s, want %s", got, tc.base)
+			}
+			if got := d.head(); got != tc.head {
+				t.Errorf("head() = %s, want %s", got, tc.head)
+			}
+		})
+	}
+}
+
+func TestDeliverPRUnreviewedChanges(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := newTestContext(t)
+	sha := prHistory(r)
+	meta := models.SyncMetadata{BaseFolder: "IntegrationPackage"}
+	ign, err := LoadRepoIgnore(r.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &deliverPR{pr: git.PullRequest{State: git.PullRequestMerged, MergeCommitSHA: sha["M"]}}
+	if got := d.unreviewedChanges(ctx, r.dir, meta, ign, sha["M"]); len(got) != 0 {
+		t.Errorf("tip == merge commit: got %v, want none", got)
+	}
+	// The squash merge of another pull request landed after M.
+	want := []string{"IntegrationPackage/IntegrationFlow/F2/f.txt"}
+	if got := d.unreviewedChanges(ctx, r.dir, meta, ign, "HEAD"); !reflect.DeepEqual(got, want) {
+		t.Errorf("later merge: got %v, want %v", got, want)
+	}
+
+	// Transport records and other non-content commits after the merge are not unreviewed content.
+	r.commit("logs", map[string]string{".iflowkit/transports/x.json": "{}"})
+	d.pr.MergeCommitSHA = sha["S"]
+	if got := d.unreviewedChanges(ctx, r.dir, meta, ign, "HEAD"); len(got) != 0 {
+		t.Errorf("metadata commit: got %v, want none", got)
+	}
+}
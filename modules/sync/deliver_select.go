@@ -0,0 +1,199 @@
+package sync
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// ArtifactSource records which source branch commit a selectively delivered artifact was taken from.
+type ArtifactSource struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Branch string `json:"branch"`
+	Commit string `json:"commit"`
+}
+
+// deliverSelection is the artifact set of `sync deliver --only`.
+type deliverSelection struct {
+	Selected   []artifactKey // artifacts to bring from the source branch
+	Unselected []artifactKey // artifacts that differ as well but stay behind
+	Unmatched  []string      // --only patterns that match no differing artifact
+	// TargetOnly are artifacts matched by --only that differ only because the target changed
+	// them (e.g. a hotfix); they are not delivered since that would revert the change.
+	TargetOnly []artifactKey
+}
+
+// selectDeliverArtifacts lists artifacts changed on sourceRef since its merge base with targetRef
+// that still differ from targetRef, and applies sel. Picking an artifact the target changed as
+// well is an error: copying the source folder would silently drop the target's change.
+func selectDeliverArtifacts(ctx *app.Context, repoRoot string, meta models.SyncMetadata, ign *RepoIgnore, sel *artifactSelector, sourceRef, targetRef string) (*deliverSelection, error) {
+	content := resolveContentFolder(meta)
+	diffOut, err := runGitOutput(ctx, repoRoot, "diff", "--name-only", targetRef, sourceRef, "--", content)
+	if err != nil {
+		return nil, err
+	}
+	sourceOut, err := runGitOutput(ctx, repoRoot, "diff", "--name-only", targetRef+"..."+sourceRef, "--", content)
+	if err != nil {
+		return nil, err
+	}
+	mergeBase, err := runGitOutput(ctx, repoRoot, "merge-base", targetRef, sourceRef)
+	if err != nil {
+		return nil, fmt.Errorf("%s and %s have no common history: %w", sourceRef, targetRef, err)
+	}
+	mergeBase = strings.TrimSpace(mergeBase)
+
+	onSource := detectChangedArtifacts(meta, ign.Filter(splitLines(sourceOut)))
+	var keys, targetOnly []artifactKey
+	for _, k := range mapKeysToSortedSlice(detectChangedArtifacts(meta, ign.Filter(splitLines(diffOut)))) {
+		if _, ok := onSource[k]; ok {
+			keys = append(keys, k)
+		} else {
+			targetOnly = append(targetOnly, k)
+		}
+	}
+	ds := &deliverSelection{Unmatched: sel.unmatchedOnly(keys)}
+	ds.Selected, ds.Unselected = sel.partition(keys)
+	ds.TargetOnly, _ = sel.partition(targetOnly)
+	if len(ds.Selected) == 0 {
+		return ds, fmt.Errorf("--only selected none of the %d artifact(s) changed on %s that differ from %s", len(keys), sourceRef, targetRef)
+	}
+	var both []string
+	for _, k := range ds.Selected {
+		if targetChangedArtifact(ctx, repoRoot, artifactRepoDir(meta, k), mergeBase, sourceRef, targetRef) {
+			both = append(both, k.Kind+"/"+k.ID)
+		}
+	}
+	if len(both) > 0 {
+		return ds, fmt.Errorf("%s changed on %s as well; delivering the %s version would drop that change. Bring it back to %s first or deliver without --only", strings.Join(both, ", "), targetRef, sourceRef, sourceRef)
+	}
+	return ds, nil
+}
+
+// targetChangedArtifact reports whether targetRef holds a version of dir that is neither the merge
+// base version nor one taken from sourceRef since then (e.g. by an earlier `deliver --only`).
+func targetChangedArtifact(ctx *app.Context, repoRoot, dir, mergeBase, sourceRef, targetRef string) bool {
+	target := gitTreeID(ctx, repoRoot, targetRef, dir)
+	if target == gitTreeID(ctx, repoRoot, mergeBase, dir) {
+		return false
+	}
+	revs, err := runGitOutput(ctx, repoRoot, "rev-list", mergeBase+".."+sourceRef, "--", dir)
+	if err != nil {
+		return true
+	}
+	for _, rev := range splitLines(revs) {
+		if gitTreeID(ctx, repoRoot, rev, dir) == target {
+			return false
+		}
+	}
+	return true
+}
+
+// gitTreeID returns the object id of path at ref, or "" when it does not exist there.
+func gitTreeID(ctx *app.Context, repoRoot, ref, path string) string {
+	out, err := runGitOutput(ctx, repoRoot, "rev-parse", "--verify", "--quiet", ref+":"+path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// deliverDependencyWarnings reports selected artifacts whose source content mentions the id of an
+// unselected artifact that also has changes (e.g. an iFlow calling a script collection that stays behind).
+func deliverDependencyWarnings(ctx *app.Context, repoRoot string, meta models.SyncMetadata, sourceRef string, ds *deliverSelection) []string {
+	if len(ds.Unselected) == 0 {
+		return nil
+	}
+	dirs := make([]string, 0, len(ds.Selected))
+	for _, k := range ds.Selected {
+		dirs = append(dirs, artifactRepoDir(meta, k))
+	}
+	var out []string
+	for _, u := range ds.Unselected {
+		args := append([]string{"grep", "-l", "-F", "-e", u.ID, sourceRef, "--"}, dirs...)
+		res, err := runGitOutput(ctx, repoRoot, args...)
+		if err != nil {
+			continue // exit 1: no match
+		}
+		paths := []string{}
+		for _, l := range splitLines(res) {
+			paths = append(paths, strings.TrimPrefix(l, sourceRef+":"))
+		}
+		for _, k := range mapKeysToSortedSlice(detectChangedArtifacts(meta, paths)) {
+			out = append(out, fmt.Sprintf("%s/%s references %s/%s, which has changes that are not selected", k.Kind, k.ID, u.Kind, u.ID))
+		}
+	}
+	return out
+}
+
+// checkoutSelectedArtifacts replaces the selected artifact folders on the current branch with their
+// sourceRef version (removing folders that no longer exist there) and stages the result.
+func checkoutSelectedArtifacts(ctx *app.Context, repoRoot string, meta models.SyncMetadata, sourceRef string, keys []artifactKey) error {
+	for _, k := range keys {
+		dir := artifactRepoDir(meta, k)
+		if err := runGit(ctx, repoRoot, "rm", "-r", "-q", "--ignore-unmatch", "--", dir); err != nil {
+			return err
+		}
+		if gitRefHasDir(ctx, repoRoot, sourceRef, dir) {
+			if err := runGit(ctx, repoRoot, "checkout", sourceRef, "--", dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// artifactSourceCommits returns the last commit on sourceBranch that touched each artifact folder.
+func artifactSourceCommits(ctx *app.Context, repoRoot string, meta models.SyncMetadata, sourceBranch string, keys []artifactKey) []ArtifactSource {
+	out := make([]ArtifactSource, 0, len(keys))
+	for _, k := range keys {
+		sha, _ := runGitOutput(ctx, repoRoot, "log", "-1", "--format=%H", sourceBranch, "--", artifactRepoDir(meta, k))
+		out = append(out, ArtifactSource{Kind: k.Kind, ID: k.ID, Branch: sourceBranch, Commit: strings.TrimSpace(sha)})
+	}
+	return out
+}
+
+func artifactRepoDir(meta models.SyncMetadata, k artifactKey) string {
+	return filepath.ToSlash(filepath.Join(resolveContentFolder(meta), k.Kind, k.ID))
+}
+
+// planSelectiveDeliver fills the deliver plan for --only: no merge, one commit with the selected folders.
+func planSelectiveDeliver(ctx *app.Context, p *SyncPlan, repoRoot string, meta models.SyncMetadata, ign *RepoIgnore, sel *artifactSelector, to, sourceRef, targetRef, targetBranch, message string) {
+	ds, err := selectDeliverArtifacts(ctx, repoRoot, meta, ign, sel, sourceRef, targetRef)
+	if err != nil {
+		p.check("selection", planCheckFail, err.Error())
+		return
+	}
+	p.check("selection", planCheckOK, fmt.Sprintf("%d of %d differing artifact(s)", len(ds.Selected), len(ds.Selected)+len(ds.Unselected)))
+	for _, pat := range ds.Unmatched {
+		p.check("selection", planCheckWarn, fmt.Sprintf("--only %s matches no differing artifact", pat))
+	}
+	for _, k := range ds.TargetOnly {
+		p.check("selection", planCheckWarn, fmt.Sprintf("%s/%s differs only by changes on %s; it is not delivered (that would revert them)", k.Kind, k.ID, targetRef))
+	}
+	for _, w := range deliverDependencyWarnings(ctx, repoRoot, meta, sourceRef, ds) {
+		p.check("dependency", planCheckWarn, w)
+	}
+	for _, k := range ds.Unselected {
+		p.Deferred = append(p.Deferred, PlanArtifact{Kind: k.Kind, ID: k.ID, Action: "skip", Reason: "not selected by --only; stays on " + sourceRef})
+	}
+
+	p.Commits = append(p.Commits, PlanCommit{Subject: buildTransportCommitMessage("<new>", "deliver", "contents", message), Planned: true})
+	uploads, deletes := []artifactKey{}, []artifactKey{}
+	for _, k := range ds.Selected {
+		if gitRefHasDir(ctx, repoRoot, sourceRef, artifactRepoDir(meta, k)) {
+			uploads = append(uploads, k)
+		} else if isDeployableKind(k.Kind) {
+			deletes = append(deletes, k)
+		}
+	}
+	client := planTenantClient(ctx, p, to)
+	planCPIActions(ctx, p, client, meta, uploads, deletes, nil, func(k artifactKey) bool {
+		return gitRefHasDir(ctx, repoRoot, sourceRef, artifactRepoDir(meta, k))
+	})
+	p.Commits = append(p.Commits, PlanCommit{Subject: buildTransportCommitMessage("<new>", "deliver", "logs", message), Planned: true})
+	p.note("tag %s would be created on success", transportTagName("<new>", targetBranch))
+}
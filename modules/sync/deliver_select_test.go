@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+func TestSelectDeliverArtifactsSourceChangesOnly(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("init", map[string]string{
+		"IntegrationPackage/iFlows/A/a.xml": "a1",
+		"IntegrationPackage/iFlows/B/b.xml": "b1",
+		"IntegrationPackage/iFlows/C/c.xml": "c1",
+	})
+	r.git("branch", "qas")
+	r.commit("develop A and C", map[string]string{
+		"IntegrationPackage/iFlows/A/a.xml": "a2",
+		"IntegrationPackage/iFlows/C/c.xml": "c2",
+	})
+	r.git("checkout", "-q", "qas")
+	r.commit("hotfix B and C", map[string]string{
+		"IntegrationPackage/iFlows/B/b.xml": "b-hotfix",
+		"IntegrationPackage/iFlows/C/c.xml": "c-hotfix",
+	})
+	r.git("checkout", "-q", "main")
+
+	ctx := newTestContext(t)
+	var meta models.SyncMetadata
+	sel := func(only ...string) *artifactSelector {
+		s, err := newArtifactSelector(only, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	// B was only hotfixed on qas: it is never a candidate, so it cannot be reverted.
+	ds, err := selectDeliverArtifacts(ctx, r.dir, meta, nil, sel("iFlows/A", "iFlows/B"), "main", "qas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.Selected) != 1 || ds.Selected[0].ID != "A" {
+		t.Errorf("selected = %v, want only A", ds.Selected)
+	}
+	if len(ds.Unselected) != 1 || ds.Unselected[0].ID != "C" {
+		t.Errorf("unselected = %v, want only C", ds.Unselected)
+	}
+	if len(ds.TargetOnly) != 1 || ds.TargetOnly[0].ID != "B" {
+		t.Errorf("target only = %v, want B", ds.TargetOnly)
+	}
+
+	if _, err := selectDeliverArtifacts(ctx, r.dir, meta, nil, sel("iFlows/B"), "main", "qas"); err == nil {
+		t.Error("selecting only the target-side hotfix succeeded")
+	}
+
+	// C changed on both sides: picking it would drop the qas hotfix.
+	_, err = selectDeliverArtifacts(ctx, r.dir, meta, nil, sel("iFlows/C"), "main", "qas")
+	if err == nil || !strings.Contains(err.Error(), "iFlows/C changed on qas") {
+		t.Errorf("err = %v, want the target change on iFlows/C reported", err)
+	}
+
+	// A version taken from main by an earlier selective deliver is not a target change.
+	r.git("checkout", "-q", "qas")
+	r.commit("deliver A", map[string]string{"IntegrationPackage/iFlows/A/a.xml": "a2"})
+	r.git("checkout", "-q", "main")
+	r.commit("develop A again", map[string]string{"IntegrationPackage/iFlows/A/a.xml": "a3"})
+	ds, err = selectDeliverArtifacts(ctx, r.dir, meta, nil, sel("iFlows/A"), "main", "qas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.Selected) != 1 || ds.Selected[0].ID != "A" {
+		t.Errorf("selected = %v, want A", ds.Selected)
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"strings"
 
 	"github.com/iflowkit/iflowkit-cli/internal/app"
-	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
 	"github.com/iflowkit/iflowkit-cli/internal/logging"
 	"github.com/iflowkit/iflowkit-cli/internal/validate"
 )
@@ -24,8 +23,10 @@ func runSyncDeploy(ctx *app.Context, args []string) error {
 	switch args[0] {
 	case "status":
 		return runSyncDeployStatus(ctx, args[1:])
+	case "run":
+		return runSyncDeployRun(ctx, args[1:])
 	default:
-		fmt.Fprintln(ctx.Stdout, "Usage: iflowkit sync deploy status [--env dev|qas|prd] [--transport <transportId>]")
+		syncDeployHelp(ctx)
 		return fmt.Errorf("unknown sync deploy command: %s", args[0])
 	}
 }
@@ -76,15 +77,14 @@ func runSyncDeployStatus(ctx *app.Context, args []string) error {
 			return err
 		}
 		if !ok {
-			fmt.Fprintln(ctx.Stdout, "No transport records found.")
-			return nil
+			return ctx.Render(DeployStatusResult{Env: env, Artifacts: []DeployStatusItem{}})
 		}
 		rec = *r
 	}
 
+	res := DeployStatusResult{Env: env, TransportID: rec.TransportID, Artifacts: []DeployStatusItem{}}
 	if len(rec.Objects) == 0 {
-		fmt.Fprintln(ctx.Stdout, "No objects recorded for this transport.")
-		return nil
+		return ctx.Render(res)
 	}
 
 	// Resolve profile + target tenant.
@@ -102,7 +102,7 @@ func runSyncDeployStatus(ctx *app.Context, args []string) error {
 	if err != nil {
 		return fmt.Errorf("%s tenant not found for profile %q: %w", strings.ToUpper(env), profileID, err)
 	}
-	client := cpix.NewClient(tenant, ctx.Logger)
+	client := newTenantClient(ctx, profileID, env, tenant)
 
 	// Sort for stable output.
 	objs := append([]SyncObject(nil), rec.Objects...)
@@ -113,7 +113,6 @@ func runSyncDeployStatus(ctx *app.Context, args []string) error {
 		return objs[i].Kind < objs[j].Kind
 	})
 
-	fmt.Fprintf(ctx.Stdout, "%-14s %-48s %-14s %s\n", "KIND", "NAME", "STATUS", "DEPLOYED_AT")
 	for _, o := range objs {
 		rt, found, err := client.GetIntegrationRuntimeArtifact(context.Background(), o.ID)
 		st := rt.Status
@@ -128,7 +127,40 @@ func runSyncDeployStatus(ctx *app.Context, args []string) error {
 			st = "NOT_FOUND"
 			deployedAt = ""
 		}
-		fmt.Fprintf(ctx.Stdout, "%-14s %-48s %-14s %s\n", o.Kind, o.ID, st, deployedAt)
+		res.Artifacts = append(res.Artifacts, DeployStatusItem{Kind: o.Kind, ID: o.ID, Status: st, DeployedAt: deployedAt})
+	}
+	return ctx.Render(res)
+}
+
+// DeployStatusResult is the result of `sync deploy status`. TransportID is empty when
+// the tenant has no transport records.
+type DeployStatusResult struct {
+	Env         string             `json:"env"`
+	TransportID string             `json:"transportId"`
+	Artifacts   []DeployStatusItem `json:"artifacts"`
+}
+
+type DeployStatusItem struct {
+	Kind       string `json:"kind"`
+	ID         string `json:"id"`
+	Status     string `json:"status"`
+	DeployedAt string `json:"deployedAt"`
+}
+
+func (r DeployStatusResult) ResultKind() string { return "SyncDeployStatus" }
+
+func (r DeployStatusResult) WriteTable(w io.Writer) error {
+	switch {
+	case r.TransportID == "":
+		fmt.Fprintln(w, "No transport records found.")
+		return nil
+	case len(r.Artifacts) == 0:
+		fmt.Fprintln(w, "No objects recorded for this transport.")
+		return nil
+	}
+	fmt.Fprintf(w, "%-14s %-48s %-14s %s\n", "KIND", "NAME", "STATUS", "DEPLOYED_AT")
+	for _, a := range r.Artifacts {
+		fmt.Fprintf(w, "%-14s %-48s %-14s %s\n", a.Kind, a.ID, a.Status, a.DeployedAt)
 	}
 	return nil
 }
@@ -0,0 +1,370 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+	"github.com/iflowkit/iflowkit-cli/internal/validate"
+)
+
+// deployWaitInterval is the polling interval of `sync deploy run --wait`.
+const deployWaitInterval = 5 * time.Second
+
+// runSyncDeployRun redeploys artifacts that already exist in the tenant, without uploading content.
+//
+// The artifacts are chosen with --kind/--id, --all (every deployable artifact in the repo) or
+// --transport (the objects of an existing record). The run is recorded as a transport of type deploy
+// under the target tenant and committed on the current branch.
+func runSyncDeployRun(ctx *app.Context, args []string) (retErr error) {
+	fs := flag.NewFlagSet("iflowkit sync deploy run", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var env, kind, fromTransport, toFlag, message string
+	var ids stringListFlag
+	var all, wait, dryRun, yes bool
+	var timeout time.Duration
+	fs.StringVar(&env, "env", "", "Tenant environment (defaults to the tenant mapped to the current branch)")
+	fs.StringVar(&kind, "kind", "", "Artifact kind (iFlows|Scripts|ValueMappings|MessageMappings)")
+	fs.Var(&ids, "id", "Artifact id (repeatable or comma-separated; requires --kind)")
+	fs.BoolVar(&all, "all", false, "Deploy every deployable artifact of the package")
+	fs.StringVar(&fromTransport, "transport", "", "Deploy the objects of this transport record")
+	fs.StringVar(&toFlag, "to", "", "Safety confirmation for the target tenant (required for prd)")
+	fs.StringVar(&message, "message", "", "Optional message appended to generated commit messages")
+	fs.BoolVar(&wait, "wait", false, "Wait until the runtime reports the new deployment as started or failed")
+	fs.DurationVar(&timeout, "timeout", 5*time.Minute, "Maximum time to wait with --wait")
+	fs.BoolVar(&dryRun, "dry-run", false, "Print the plan without changing CPI")
+	fs.BoolVar(&yes, "yes", false, "Confirm a PRD run without prompting (required when stdin is not a terminal)")
+	lockWait := lockWaitFlag(fs)
+	noNotify := noNotifyFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		syncDeployHelp(ctx)
+		return err
+	}
+	kind = strings.TrimSpace(kind)
+	fromTransport = strings.TrimSpace(fromTransport)
+	message = strings.TrimSpace(message)
+
+	modes := 0
+	for _, set := range []bool{len(ids) > 0, all, fromTransport != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		syncDeployHelp(ctx)
+		return fmt.Errorf("choose exactly one of --kind/--id, --all or --transport")
+	}
+	if len(ids) > 0 && !isDeployableKind(kind) {
+		return fmt.Errorf("--id requires --kind iFlows|Scripts|ValueMappings|MessageMappings (got %q)", kind)
+	}
+	if len(ids) == 0 && kind != "" {
+		return fmt.Errorf("--kind is only used together with --id")
+	}
+	if wait && timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	cwd, _ := os.Getwd()
+	repoRoot, err := findSyncRepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	meta, err := loadPackageMetadata(repoRoot)
+	if err != nil {
+		return err
+	}
+	if err := meta.ValidateRequired(); err != nil {
+		return err
+	}
+	branch, err := gitCurrentBranch(ctx, repoRoot)
+	if err != nil {
+		return err
+	}
+	env = strings.ToLower(strings.TrimSpace(env))
+	if env == "" {
+		if env, _, err = resolveTargetTenant(meta, branch); err != nil {
+			return fmt.Errorf("%w; pass --env", err)
+		}
+	}
+	if err := validate.Env(env); err != nil {
+		return err
+	}
+	if env == "qas" && meta.CPITenantLevels != 3 {
+		return fmt.Errorf("qas is not enabled: cpiTenantLevels=%d (expected 3)", meta.CPITenantLevels)
+	}
+	if err := validateToFlag(toFlag, env); err != nil {
+		return err
+	}
+
+	var targets []deployTarget
+	switch {
+	case len(ids) > 0:
+		for _, id := range ids {
+			targets = mergeDeployRemaining(targets, []deployTarget{{Kind: kind, ID: id}})
+		}
+	case all:
+		local, err := listLocalArtifactKeys(repoRoot, meta)
+		if err != nil {
+			return err
+		}
+		for _, k := range mapKeysToSortedSlice(local) {
+			if isDeployableKind(k.Kind) {
+				targets = append(targets, deployTarget{Kind: k.Kind, ID: k.ID})
+			}
+		}
+	default:
+		_, src, err := locateTransportRecord(ctx, repoRoot, "", fromTransport)
+		if err != nil {
+			return err
+		}
+		for _, o := range src.Objects {
+			if isDeployableKind(o.Kind) {
+				targets = mergeDeployRemaining(targets, []deployTarget{{Kind: o.Kind, ID: o.ID}})
+			}
+		}
+	}
+	targets = sortDeployTargets(targets)
+	if len(targets) == 0 {
+		return ctx.Render(SyncRunResult{Command: "deploy run", Tenant: env, Branch: branch, Message: "No deployable artifacts selected."})
+	}
+
+	if dryRun {
+		return finishPlan(ctx, planSyncDeployRun(ctx, repoRoot, meta, env, branch, targets, message, wait))
+	}
+	var confirmation *TransportConfirmation
+	if env == "prd" {
+		plan := planSyncDeployRun(ctx, repoRoot, meta, env, branch, targets, message, wait)
+		if confirmation, err = confirmPRD(ctx, repoRoot, plan, yes); err != nil {
+			return err
+		}
+	}
+
+	if dirty := gitPorcelainPaths(ctx, repoRoot); len(dirty) > 0 {
+		return fmt.Errorf("working tree is not clean (%d paths). commit/stash changes before running deploy", len(dirty))
+	}
+	releaseLock, err := acquireSyncLock(ctx, repoRoot, "deploy run", env, *lockWait)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+	ctx.Logger.Info("sync deploy run started", logging.F("repo", repoRoot), logging.F("branch", branch), logging.F("tenant", env), logging.F("artifacts", len(targets)))
+
+	store, err := NewTransportStore(repoRoot, env)
+	if err != nil {
+		return err
+	}
+	id, createdAt := newTransportIDs(time.Now())
+	head, _ := runGitOutput(ctx, repoRoot, "rev-parse", "HEAD")
+	gitUserName, gitUserEmail, _ := gitUserIdentity(ctx, repoRoot)
+	objects := make([]artifactKey, 0, len(targets))
+	for _, t := range targets {
+		objects = append(objects, artifactKey{Kind: t.Kind, ID: t.ID})
+	}
+	rec := TransportRecord{
+		SchemaVersion:   1,
+		TransportID:     id,
+		TransportType:   "deploy",
+		PackageID:       meta.PackageID,
+		Branch:          branch,
+		CreatedAt:       createdAt,
+		GitCommits:      splitLines(head),
+		GitUserName:     gitUserName,
+		GitUserEmail:    gitUserEmail,
+		Objects:         keysToObjects(keySet(objects)),
+		TransportStatus: "pending",
+		DeployRemaining: append([]deployTarget{}, targets...),
+		Confirmation:    confirmation,
+	}
+	recPath, err := store.PersistTransportRecord(rec)
+	if err != nil {
+		return err
+	}
+	ctx.Logger.Info("deploy transport record created", logging.F("path", filepath.ToSlash(strings.TrimPrefix(recPath, repoRoot+string(os.PathSeparator)))), logging.F("deploy", len(targets)))
+
+	defer func() {
+		msg := buildTransportCommitMessage(id, "deploy", "logs", message)
+		if err := gitCommitAndPushLogs(ctx, repoRoot, branch, msg); err != nil {
+			if retErr == nil {
+				retErr = err
+				return
+			}
+			ctx.Logger.Warn("failed to push .iflowkit metadata", logging.F("error", err.Error()))
+		}
+	}()
+
+	// Remember the current runtime deployment so --wait can tell the new one apart.
+	var client *cpix.Client
+	before := map[string]string{}
+	if wait {
+		if client, err = tenantClientForEnv(ctx, env); err != nil {
+			return err
+		}
+		for _, t := range targets {
+			rt, found, err := client.GetIntegrationRuntimeArtifact(context.Background(), t.ID)
+			if err == nil && found {
+				before[t.ID] = rt.DeployedOn
+			}
+		}
+	}
+
+	run, err := executeTransport(ctx, repoRoot, meta, env, &rec, store, !*noNotify)
+	if err != nil {
+		return err
+	}
+	deployed := run.Deployed
+	if _, err := store.PersistTransportRecord(rec); err != nil {
+		return err
+	}
+	ctx.Logger.Info("sync deploy run completed", logging.F("tenant", env), logging.F("transportId", id), logging.F("deployedArtifacts", deployed))
+	res := SyncRunResult{
+		Command:     "deploy run",
+		Tenant:      env,
+		Branch:      branch,
+		TransportID: id,
+		Changed:     deployed > 0,
+		Deployed:    deployed,
+		Steps:       run.Steps,
+		Message:     fmt.Sprintf("Deploy triggered for %d artifact(s) in CPI %s. Transport: %s", deployed, tenantDisplay(env), id),
+	}
+	if !wait {
+		return ctx.Render(res)
+	}
+	var waitErr error
+	res.Deployments, waitErr = waitForRuntimeStatus(ctx, client, targets, before, timeout)
+	if err := ctx.Render(res); err != nil {
+		return err
+	}
+	return waitErr
+}
+
+// waitForRuntimeStatus polls the runtime until every target shows a deployment newer than before
+// that is no longer starting, and returns the final status of each target.
+func waitForRuntimeStatus(ctx *app.Context, client *cpix.Client, targets []deployTarget, before map[string]string, timeout time.Duration) ([]DeployStatusItem, error) {
+	deadline := time.Now().Add(timeout)
+	final := map[string]cpix.RuntimeArtifactStatus{}
+	for {
+		for _, t := range targets {
+			if _, done := final[t.ID]; done {
+				continue
+			}
+			rt, found, err := client.GetIntegrationRuntimeArtifact(context.Background(), t.ID)
+			if err != nil {
+				ctx.Logger.Warn("deployment status check failed", logging.F("id", t.ID), logging.F("error", err.Error()))
+				continue
+			}
+			if !found || rt.DeployedOn == before[t.ID] {
+				continue
+			}
+			if st := strings.ToUpper(rt.Status); st == "STARTED" || st == "ERROR" {
+				final[t.ID] = rt
+			}
+		}
+		if len(final) == len(targets) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(deployWaitInterval)
+	}
+
+	failed, pending := 0, 0
+	out := make([]DeployStatusItem, 0, len(targets))
+	for _, t := range targets {
+		rt, ok := final[t.ID]
+		st := strings.ToUpper(rt.Status)
+		switch {
+		case !ok:
+			st = "TIMEOUT"
+			pending++
+		case st == "ERROR":
+			failed++
+		}
+		out = append(out, DeployStatusItem{Kind: t.Kind, ID: t.ID, Status: st, DeployedAt: rt.DeployedOn})
+	}
+	if failed > 0 || pending > 0 {
+		return out, fmt.Errorf("deployment not healthy: %d failed, %d not started within %s", failed, pending, timeout)
+	}
+	return out, nil
+}
+
+// tenantClientForEnv returns a client for the tenant of env in the resolved profile.
+func tenantClientForEnv(ctx *app.Context, env string) (*cpix.Client, error) {
+	profileID, _, err := ctx.Stores.ResolveProfileID(ctx.Flags.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ctx.Stores.Tenants.Read(profileID, env)
+	if err != nil {
+		return nil, fmt.Errorf("%s tenant not found for profile %q: %w", tenantDisplay(env), profileID, err)
+	}
+	return newTenantClient(ctx, profileID, env, key), nil
+}
+
+func sortDeployTargets(list []deployTarget) []deployTarget {
+	keys := make([]artifactKey, 0, len(list))
+	for _, t := range list {
+		keys = append(keys, artifactKey{Kind: t.Kind, ID: t.ID})
+	}
+	out := make([]deployTarget, 0, len(list))
+	for _, k := range sortArtifactKeys(keys) {
+		out = append(out, deployTarget{Kind: k.Kind, ID: k.ID})
+	}
+	return out
+}
+
+// planSyncDeployRun lists the artifacts `sync deploy run` would deploy and checks that they exist in the tenant.
+func planSyncDeployRun(ctx *app.Context, repoRoot string, meta models.SyncMetadata, env, branch string, targets []deployTarget, message string, wait bool) *SyncPlan {
+	p := newSyncPlan("deploy", meta.PackageID, branch, env)
+	if dirty := gitPorcelainPaths(ctx, repoRoot); len(dirty) > 0 {
+		p.check("working tree", planCheckFail, fmt.Sprintf("not clean (%d paths)", len(dirty)))
+	} else {
+		p.check("working tree", planCheckOK, "clean")
+	}
+
+	client := planTenantClient(ctx, p, env)
+	arts := map[string]map[string]cpix.ArtifactInfo{}
+	if client != nil {
+		for _, t := range targets {
+			if _, done := arts[t.Kind]; done {
+				continue
+			}
+			endpoint, ok := listEndpointForKind(meta.PackageID, t.Kind)
+			if !ok {
+				continue
+			}
+			m, err := client.ListArtifacts(context.Background(), endpoint)
+			if err != nil {
+				p.check("list "+t.Kind, planCheckFail, err.Error())
+				continue
+			}
+			arts[t.Kind] = m
+		}
+	}
+	for _, t := range targets {
+		a := PlanArtifact{Kind: t.Kind, ID: t.ID, Action: "deploy"}
+		if listed, ok := arts[t.Kind]; !ok {
+			a.Reason = "tenant not checked"
+		} else if _, found := listed[t.ID]; !found {
+			a.Reason = "not found in tenant; deploy would fail"
+			p.check("artifact", planCheckFail, fmt.Sprintf("%s/%s does not exist in %s", t.Kind, t.ID, tenantDisplay(env)))
+		}
+		p.Deploy = append(p.Deploy, a)
+	}
+	p.Commits = append(p.Commits, PlanCommit{Subject: buildTransportCommitMessage("<new>", "deploy", "logs", message), Planned: true})
+	p.note("no content is uploaded; the tenant's current design-time version is deployed")
+	if wait {
+		p.note("would wait for the runtime to report each deployment as started or failed")
+	}
+	return p
+}
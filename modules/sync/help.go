@@ -27,6 +27,21 @@ func syncHelp(ctx *app.Context, path []string) {
 		case "compare":
 			syncCompareHelp(ctx)
 			return
+		case "rollback":
+			syncRollbackHelp(ctx)
+			return
+		case "transport":
+			syncTransportHelp(ctx)
+			return
+		case "status":
+			syncStatusHelp(ctx)
+			return
+		case "watch":
+			syncWatchHelp(ctx)
+			return
+		case "lock":
+			syncLockHelp(ctx)
+			return
 		}
 	}
 
@@ -42,7 +57,12 @@ func syncHelp(ctx *app.Context, path []string) {
 	fmt.Fprintln(out, "  push   Push local changes to git and update CPI tenant (based on current branch)")
 	fmt.Fprintln(out, "  deliver Promote changes between environment branches and update the target tenant")
 	fmt.Fprintln(out, "  compare Show IntegrationPackage differences between current branch and an environment branch")
-	fmt.Fprintln(out, "  deploy Inspect local deployment records (status/remaining work)")
+	fmt.Fprintln(out, "  deploy Inspect deployment status or redeploy artifacts without uploading content")
+	fmt.Fprintln(out, "  rollback Restore an environment branch and tenant to a previous transport tag")
+	fmt.Fprintln(out, "  transport Inspect and manage transport records (list/show/retry/abandon/reindex)")
+	fmt.Fprintln(out, "  status Report drift between tenants and environment branches (read-only)")
+	fmt.Fprintln(out, "  watch  Poll a tenant for web UI edits and pull them automatically")
+	fmt.Fprintln(out, "  lock   Show or release the repository and tenant locks")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Help:")
 	fmt.Fprintln(out, "  iflowkit help sync")
@@ -52,24 +72,121 @@ func syncHelp(ctx *app.Context, path []string) {
 	fmt.Fprintln(out, "  iflowkit help sync deliver")
 	fmt.Fprintln(out, "  iflowkit help sync compare")
 	fmt.Fprintln(out, "  iflowkit help sync deploy")
+	fmt.Fprintln(out, "  iflowkit help sync rollback")
+	fmt.Fprintln(out, "  iflowkit help sync transport")
+	fmt.Fprintln(out, "  iflowkit help sync status")
+	fmt.Fprintln(out, "  iflowkit help sync watch")
+	fmt.Fprintln(out, "  iflowkit help sync lock")
+	fmt.Fprintln(out, "")
+}
+
+func syncRollbackHelp(ctx *app.Context) {
+	out := ctx.Stdout
+	fmt.Fprintln(out, "Restore an environment to a previous transport tag")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  iflowkit sync rollback --env dev|qas|prd --to <transportId> [--message <commitMessage>] [--yes] [--dry-run]")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "What it does:")
+	fmt.Fprintln(out, "  - Requires the tag <transportId>_<env> (created by successful push/deliver on env branches)")
+	fmt.Fprintln(out, "  - Compares IntegrationPackage/ between the tag and the branch tip (using .iflowkit/ignore)")
+	fmt.Fprintln(out, "  - Commits the tag version of the differing artifact folders on top of <env> and pushes it")
+	fmt.Fprintln(out, "  - Uploads, deletes and deploys those artifacts in the tenant as a transport of type rollback")
+	fmt.Fprintln(out, "  - A pending rollback record is resumed (CPI work only) when the command is run again")
+	fmt.Fprintln(out, "  - Artifacts CPI cannot restore (e.g. deleted in the tenant since the tag) fail the rollback: the record stays")
+	fmt.Fprintln(out, "    pending with them and no tag is created; recreate them in CPI and retry")
+	fmt.Fprintln(out, "  - Webhooks: sends completed/failed/resumed notifications for the CPI transport (.iflowkit/webhooks.json and the profile's webhooks.json); --no-notify turns them off")
+	fmt.Fprintln(out, "  - PRD confirmation: on a terminal the plan is shown and you must type the package id; non-interactive runs need --yes")
+	fmt.Fprintln(out, "  - Locking: takes the repository lock and the team-wide tenant lock; --lock-wait <duration> waits instead of failing (see `iflowkit help sync lock`)")
+	fmt.Fprintln(out, "")
+}
+
+func syncTransportHelp(ctx *app.Context) {
+	out := ctx.Stdout
+	fmt.Fprintln(out, "Inspect and manage transport records (.iflowkit/transports/<tenant>/)")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  iflowkit sync transport list [--env dev|qas|prd] [--type <type>] [--status pending|completed|cancelled]")
+	fmt.Fprintln(out, "                              [--branch <branch>] [--user <nameOrEmail>] [--since <date>] [--until <date>]")
+	fmt.Fprintln(out, "  iflowkit sync transport show <transportId> [--env dev|qas|prd]")
+	fmt.Fprintln(out, "  iflowkit sync transport retry <transportId> [--env dev|qas|prd] [--yes] [--no-notify]")
+	fmt.Fprintln(out, "  iflowkit sync transport abandon <transportId> --reason <text> [--env dev|qas|prd]")
+	fmt.Fprintln(out, "  iflowkit sync transport reindex [--env dev|qas|prd]")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Notes:")
+	fmt.Fprintln(out, "  - list/show/reindex read the records of the current checkout")
+	fmt.Fprintln(out, "  - reindex empties an index whose records are all gone and reports unreadable record files it left out")
+	fmt.Fprintln(out, "  - retry resumes the remaining CPI work of a pending push/deliver/rollback record on its branch")
+	fmt.Fprintln(out, "  - abandon marks a pending record as cancelled; push/deliver/rollback no longer resume it")
+	fmt.Fprintln(out, "  - retry and abandon check out the record's branch (clean working tree required) and push the updated record")
+	fmt.Fprintln(out, "  - retry and abandon take the repository lock and the record's tenant lock; --lock-wait <duration> waits for them")
+	fmt.Fprintln(out, "  - retry sends the configured webhook notifications (resumed, then completed or failed); --no-notify turns them off")
+	fmt.Fprintln(out, "  - Dates: YYYY-MM-DD or RFC3339")
+	fmt.Fprintln(out, "")
+}
+
+func syncStatusHelp(ctx *app.Context) {
+	out := ctx.Stdout
+	fmt.Fprintln(out, "Report drift between tenants and environment branches")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  iflowkit sync status [--env dev|qas|prd]")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "What it does:")
+	fmt.Fprintln(out, "  - For every env enabled by cpiTenantLevels (or only --env), exports the tenant to a temp folder")
+	fmt.Fprintln(out, "    and compares it with origin/<env> (IntegrationPackage/ only, after .iflowkit/ignore)")
+	fmt.Fprintln(out, "  - Reports per-artifact drift: changed-in-tenant, missing-in-tenant, missing-in-branch")
+	fmt.Fprintln(out, "  - Lists pending transport records and how far local env branches are ahead/behind origin")
+	fmt.Fprintln(out, "  - Read-only: nothing is committed, pushed or changed in CPI")
+	fmt.Fprintln(out, "  - Exits non-zero when drift is found or an environment cannot be checked (suitable for nightly jobs)")
+	fmt.Fprintln(out, "")
+}
+
+func syncWatchHelp(ctx *app.Context) {
+	out := ctx.Stdout
+	fmt.Fprintln(out, "Watch a tenant for web UI edits and pull them automatically")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  iflowkit sync watch [--env dev|qas|prd] [--interval 1m] [--quiet-hours HH:MM-HH:MM] [--notify-only] [--message <msg>]")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "What it does:")
+	fmt.Fprintln(out, "  - Polls the artifact lists of the package (version and modification time) every --interval (minimum 10s)")
+	fmt.Fprintln(out, "  - When something changed since the previous poll, runs `sync pull` on the current branch")
+	fmt.Fprintln(out, "  - --notify-only: only prints the changed artifacts (required for prd)")
+	fmt.Fprintln(out, "  - Without --notify-only the current branch must be the watched env branch")
+	fmt.Fprintln(out, "  - --quiet-hours: no polling inside this local time window (may wrap midnight, e.g. 20:00-07:00)")
+	fmt.Fprintln(out, "  - Automatic pulls take the same locks as `sync pull`, so they never overlap with a push or deliver; a blocked or failed pull is retried on the next poll")
+	fmt.Fprintln(out, "  - Changes made before the watch started are not pulled; run `sync pull` first")
+	fmt.Fprintln(out, "  - Runs until interrupted (Ctrl+C / SIGTERM)")
 	fmt.Fprintln(out, "")
 }
 
 func syncCompareHelp(ctx *app.Context) {
 	out := ctx.Stdout
-	fmt.Fprintln(out, "Compare IntegrationPackage content between branches")
+	fmt.Fprintln(out, "Compare IntegrationPackage content between branches or between two tenants")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Usage:")
-	fmt.Fprintln(out, "  iflowkit sync compare --to qas|prd")
+	fmt.Fprintln(out, "  iflowkit sync compare --to qas|prd [--stat] [--diff] [--kind <kind>] [--id <glob>]")
+	fmt.Fprintln(out, "  iflowkit sync compare --tenant dev|qas|prd --against-tenant dev|qas|prd [--stat] [--diff] [--kind <kind>] [--id <glob>]")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "What it does:")
 	fmt.Fprintln(out, "  - Compares the current branch with origin/<to> using git diff (IntegrationPackage/ only)")
 	fmt.Fprintln(out, "  - Applies ignore patterns from .iflowkit/ignore (plus built-in defaults)")
 	fmt.Fprintln(out, "  - Prints a summary list: Kind - ObjectId")
+	fmt.Fprintln(out, "  - --stat: per-file added/removed line counts, grouped by artifact")
+	fmt.Fprintln(out, "  - --diff: per-file unified diffs (a/ = origin/<to>, b/ = current branch); XML (.iflw, .mmap, .xsd, ...) and JSON")
+	fmt.Fprintln(out, "    are pretty-printed first, so whitespace-only changes in those files are not shown")
+	fmt.Fprintln(out, "  - --kind / --id: only show matching artifacts (repeatable; --id accepts globs such as Order*)")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Tenant mode (--tenant/--against-tenant):")
+	fmt.Fprintln(out, "  - Exports both tenants to temp folders; git and the current branch are not used")
+	fmt.Fprintln(out, "  - Lists artifacts that exist in only one tenant, differ in content, or differ in design-time Version")
+	fmt.Fprintln(out, "  - --diff uses a/ = --tenant, b/ = --against-tenant")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Rules:")
 	fmt.Fprintln(out, "  - --to qas: only when cpiTenantLevels=3")
 	fmt.Fprintln(out, "  - --to prd: when cpiTenantLevels=2 or 3")
+	fmt.Fprintln(out, "  - --tenant/--against-tenant must differ and cannot be combined with --to; qas needs cpiTenantLevels=3")
 	fmt.Fprintln(out, "")
 }
 
@@ -78,14 +195,49 @@ func syncDeliverHelp(ctx *app.Context) {
 	fmt.Fprintln(out, "Promote changes between environments (branch merge + tenant update)")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Usage:")
-	fmt.Fprintln(out, "  iflowkit sync deliver --to qas|prd [--message <commitMessage>]")
+	fmt.Fprintln(out, "  iflowkit sync deliver --to qas|prd [--message <commitMessage>] [--only Kind/ID]... [--strategy source|target|stop] [--yes] [--dry-run]")
+	fmt.Fprintln(out, "  iflowkit sync deliver --to qas|prd --continue|--abort")
+	fmt.Fprintln(out, "  iflowkit sync deliver --to qas|prd --via-pr [--message <text>]")
+	fmt.Fprintln(out, "  iflowkit sync deliver --to qas|prd --complete [--pr <number>] [--yes] [--dry-run]")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Rules:")
 	fmt.Fprintln(out, "  - --to qas: only when cpiTenantLevels=3 (DEV -> QAS)")
 	fmt.Fprintln(out, "  - --to prd: when cpiTenantLevels=3 (QAS -> PRD) or cpiTenantLevels=2 (DEV -> PRD)")
-	fmt.Fprintln(out, "  - PRD safety: --to prd is mandatory (this flag is the confirmation)")
+	fmt.Fprintln(out, "  - PRD safety: --to prd is mandatory")
+	fmt.Fprintln(out, "  - Locking: takes the repository lock and the team-wide tenant lock; --lock-wait <duration> waits instead of failing (see `iflowkit help sync lock`)")
+	fmt.Fprintln(out, "  - Hooks: pre-deliver runs before any git or CPI change (non-zero exit aborts), post-deliver after the transport; see .iflowkit/hooks.json")
+	fmt.Fprintln(out, "  - Webhooks: sends completed/failed/resumed notifications for the CPI transport (.iflowkit/webhooks.json and the profile's webhooks.json); --no-notify turns them off")
+	fmt.Fprintln(out, "  - PRD confirmation: on a terminal the plan is shown and you must type the package id; non-interactive runs need --yes")
+	fmt.Fprintln(out, "  - --dry-run: print the plan (commits, CPI delete/upload/deploy, preflight checks) without touching git remotes or CPI; exits non-zero if a preflight check fails")
 	fmt.Fprintln(out, "  - Compares tenant vs target branch using .iflowkit/ignore; if different, the command fails")
+	fmt.Fprintln(out, "  - --only Kind/ID (glob, repeatable or comma separated): instead of merging, copies only the selected artifact")
+	fmt.Fprintln(out, "    folders from the source branch as one commit and updates just those in the tenant; warns when a selected")
+	fmt.Fprintln(out, "    artifact references an unselected artifact that also changed")
+	fmt.Fprintln(out, "    Only artifacts changed on the source since the merge base are selectable; target-only changes (hotfixes)")
+	fmt.Fprintln(out, "    are skipped, and selecting an artifact the target changed as well fails instead of reverting that change")
 	fmt.Fprintln(out, "  - If origin/qas or origin/prd does not exist, it is bootstrapped from the tenant (init transport + tag)")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Merge conflicts:")
+	fmt.Fprintln(out, "  - The conflicting artifact folders (and other files) are listed")
+	fmt.Fprintln(out, "  - --strategy source: replace each conflicting artifact folder with the source branch version")
+	fmt.Fprintln(out, "  - --strategy target: keep the target branch version of each conflicting artifact folder")
+	fmt.Fprintln(out, "  - --strategy stop: redo the merge on branch deliver/<to>/<transportId> and leave the conflicts there;")
+	fmt.Fprintln(out, "    resolve, git add, git commit, then run --continue (or --abort to drop the branch)")
+	fmt.Fprintln(out, "  - Without --strategy you are asked on a terminal; non-interactive runs stop")
+	fmt.Fprintln(out, "  - The strategy and the conflicting artifacts are stored in the transport record (conflict)")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Pull requests (GitHub, GitLab, Gitea/Forgejo):")
+	fmt.Fprintln(out, "  - --via-pr: runs the preflight checks and opens a pull/merge request from the source to the target branch")
+	fmt.Fprintln(out, "    with the transport plan as description; nothing is merged and CPI is not changed")
+	fmt.Fprintln(out, "  - --complete: requires the request to be approved, merges it if still open, then updates the tenant from")
+	fmt.Fprintln(out, "    the merged target branch (preflight: tenant vs the target before the merge)")
+	fmt.Fprintln(out, "  - Approvals count only on the current head commit; the merge is pinned to that commit and refused if the head moved")
+	fmt.Fprintln(out, "  - --pr <number>: complete a request opened elsewhere; by default the one opened by --via-pr in this clone")
+	fmt.Fprintln(out, "  - While a request opened here is waiting, a plain deliver to the same env is refused")
+	fmt.Fprintln(out, "  - Token: IFLOWKIT_GIT_TOKEN or GITHUB_TOKEN/GH_TOKEN, GITLAB_TOKEN/GITLAB_PRIVATE_TOKEN,")
+	fmt.Fprintln(out, "    GITEA_TOKEN/FORGEJO_TOKEN; IFLOWKIT_GIT_API_URL overrides the API base URL")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Records:")
 	fmt.Fprintln(out, "  - Writes a transport record (*.transport.json) with transportType=deliver under .iflowkit/transports/<tenant>/")
 	fmt.Fprintln(out, "  - On success, creates and pushes a git tag named <transportId> on the target branch")
 	fmt.Fprintln(out, "")
@@ -96,28 +248,53 @@ func syncPullHelp(ctx *app.Context) {
 	fmt.Fprintln(out, "Refresh local repo from CPI and push CPI state to Git")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Usage:")
-	fmt.Fprintln(out, "  iflowkit sync pull [--to dev|qas|prd] [--message <commitMessage>]")
+	fmt.Fprintln(out, "  iflowkit sync pull [--to dev|qas|prd] [--message <commitMessage>] [--yes] [--dry-run]")
+	fmt.Fprintln(out, "  iflowkit sync pull [--into feature/<name>|bugfix/<name>] [--only <Kind/ID>...] [--message <commitMessage>] [--dry-run]")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "What it does:")
 	fmt.Fprintln(out, "  - Must be executed inside an existing sync repo (finds .iflowkit/package.json)")
-	fmt.Fprintln(out, "  - Allowed branch: dev, qas (only when cpiTenantLevels=3), prd")
+	fmt.Fprintln(out, "  - Allowed branch: dev, qas (only when cpiTenantLevels=3), prd, or a work branch (see below)")
 	fmt.Fprintln(out, "  - Reads the IntegrationPackage from the mapped tenant and re-exports to IntegrationPackage/")
 	fmt.Fprintln(out, "  - Commits and pushes the CPI state to origin/<current-branch>")
+	fmt.Fprintln(out, "  - Uncommitted local edits are stashed, then merged back on top of the tenant state (three-way):")
+	fmt.Fprintln(out, "    edits that do not overlap stay in the working tree; artifacts changed both locally and on the tenant")
+	fmt.Fprintln(out, "    are left at the tenant version and listed, and only then is the stash kept (git stash show -p <sha>)")
 	fmt.Fprintln(out, "  - PRD safety: must pass --to prd")
+	fmt.Fprintln(out, "  - Locking: takes the repository lock and the team-wide tenant lock; --lock-wait <duration> waits instead of failing (see `iflowkit help sync lock`)")
+	fmt.Fprintln(out, "  - Hooks: post-pull runs after an environment-branch pull wrote its transport record; see .iflowkit/hooks.json")
+	fmt.Fprintln(out, "  - PRD confirmation: on a terminal the plan is shown and you must type the package id; non-interactive runs need --yes")
+	fmt.Fprintln(out, "  - --dry-run: print the plan (commits, CPI delete/upload/deploy, preflight checks) without touching git remotes or CPI; exits non-zero if a preflight check fails")
 	fmt.Fprintln(out, "  - Writes a transport record (*.transport.json) with transportType=pull")
 	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Work branches (feature/*, bugfix/*):")
+	fmt.Fprintln(out, "  - Runs on the current work branch, or on the branch given with --into (checked out, created from HEAD if missing, then switched back)")
+	fmt.Fprintln(out, "  - Exports DEV and commits only the artifacts that differ from the branch; package-level files are not taken")
+	fmt.Fprintln(out, "  - --only: take just these artifacts (Kind/ID, glob allowed, repeatable), so other developers' DEV edits stay out")
+	fmt.Fprintln(out, "  - Artifacts missing on DEV are kept (never deleted); the working tree must be clean")
+	fmt.Fprintln(out, "  - The commit is pushed to origin/<branch>; no transport record is written")
+	fmt.Fprintln(out, "")
 }
 
 func syncDeployHelp(ctx *app.Context) {
 	out := ctx.Stdout
-	fmt.Fprintln(out, "Inspect deployment records")
+	fmt.Fprintln(out, "Inspect deployment records and redeploy artifacts")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Usage:")
 	fmt.Fprintln(out, "  iflowkit sync deploy status [--env dev|qas|prd] [--transport <transportId>]")
+	fmt.Fprintln(out, "  iflowkit sync deploy run (--kind <kind> --id <id>[,<id>...] | --all | --transport <transportId>)")
+	fmt.Fprintln(out, "                           [--env dev|qas|prd] [--to prd] [--wait [--timeout 5m]] [--message <msg>] [--yes] [--dry-run]")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Notes:")
-	fmt.Fprintln(out, "  - Reads local records under .iflowkit/transports/")
-	fmt.Fprintln(out, "  - By default, shows the most recent record")
+	fmt.Fprintln(out, "  - status reads local records under .iflowkit/transports/; by default, shows the most recent record")
+	fmt.Fprintln(out, "  - run deploys the tenant's current design-time version; nothing is uploaded")
+	fmt.Fprintln(out, "  - run targets the tenant mapped to the current branch unless --env is given")
+	fmt.Fprintln(out, "  - --kind: iFlows, Scripts, ValueMappings or MessageMappings; --all takes every deployable artifact in the repo")
+	fmt.Fprintln(out, "  - --transport: redeploys the deployable objects of that record (from any tenant)")
+	fmt.Fprintln(out, "  - PRD safety: must pass --to prd; the plan must be confirmed by typing the package id, or --yes when non-interactive")
+	fmt.Fprintln(out, "  - Locking: takes the repository lock and the team-wide tenant lock; --lock-wait <duration> waits instead of failing (see `iflowkit help sync lock`)")
+	fmt.Fprintln(out, "  - --wait: polls the runtime until each new deployment is STARTED or ERROR; exits non-zero on ERROR or timeout")
+	fmt.Fprintln(out, "  - Writes a transport record (transportType=deploy) and commits it on the current branch; a failed run can be resumed with `sync transport retry`")
+	fmt.Fprintln(out, "  - Webhooks: sends completed/failed/resumed notifications for the deploy (.iflowkit/webhooks.json and the profile's webhooks.json); --no-notify turns them off")
 	fmt.Fprintln(out, "")
 }
 
@@ -131,7 +308,8 @@ func syncInitHelp(ctx *app.Context) {
 	fmt.Fprintln(out, "Notes:")
 	fmt.Fprintln(out, "  - Uses DEV tenant only (no --env)")
 	fmt.Fprintln(out, "  - If --dir is provided, the repo is created under <parentPath>/<packageId>")
-	fmt.Fprintln(out, "  - Creates a private repo on GitHub/GitLab when possible")
+	fmt.Fprintln(out, "  - Creates a private repo on GitHub/GitLab/Gitea/Forgejo when possible")
+	fmt.Fprintln(out, "  - Self-hosted hosts without a provider name are probed for the Gitea/Forgejo API (/api/v1/version)")
 	fmt.Fprintln(out, "  - Pushes exported content to branch 'dev'")
 	fmt.Fprintln(out, "  - Writes sync metadata to .iflowkit/package.json")
 	fmt.Fprintln(out, "  - Writes/updates .gitignore (does not ignore .iflowkit/transports)")
@@ -146,15 +324,22 @@ func syncPushHelp(ctx *app.Context) {
 	fmt.Fprintln(out, "Push local changes to Git and update CPI tenant")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Usage:")
-	fmt.Fprintln(out, "  iflowkit sync push [--to dev|qas|prd] [--message <commitMessage>]")
+	fmt.Fprintln(out, "  iflowkit sync push [--to dev|qas|prd] [--message <commitMessage>] [--only Kind/ID]... [--exclude Kind/ID]... [--yes] [--dry-run]")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "What it does:")
 	fmt.Fprintln(out, "  - Finds .iflowkit/package.json by walking up from current directory")
 	fmt.Fprintln(out, "  - Detects local changes via git diff (including untracked files)")
 	fmt.Fprintln(out, "  - Commits and pushes the current branch to origin")
 	fmt.Fprintln(out, "  - Updates the mapped CPI tenant only for changed artifacts under IntegrationPackage/")
+	fmt.Fprintln(out, "  - --only/--exclude Kind/ID (glob, repeatable or comma separated) limit which artifacts go to CPI;")
+	fmt.Fprintln(out, "    git still gets every commit, unselected artifacts stay queued in the pending transport for the next push")
 	fmt.Fprintln(out, "  - PRD safety: must pass --to prd")
+	fmt.Fprintln(out, "  - Locking: takes the repository lock and the team-wide tenant lock; --lock-wait <duration> waits instead of failing (see `iflowkit help sync lock`)")
+	fmt.Fprintln(out, "  - Hooks: pre-push runs before any git or CPI change (non-zero exit aborts), post-push after the transport; see .iflowkit/hooks.json")
+	fmt.Fprintln(out, "  - PRD confirmation: on a terminal the plan is shown and you must type the package id; non-interactive runs need --yes")
+	fmt.Fprintln(out, "  - --dry-run: print the plan (commits, CPI delete/upload/deploy, preflight checks) without touching git remotes or CPI; exits non-zero if a preflight check fails")
 	fmt.Fprintln(out, "  - Deploys updated iFlows after upload")
+	fmt.Fprintln(out, "  - Webhooks: sends completed/failed/resumed notifications for the CPI transport (.iflowkit/webhooks.json and the profile's webhooks.json); --no-notify turns them off")
 	fmt.Fprintln(out, "  - Uses .iflowkit/transports/<tenant>/index.json and *.transport.json records as retry state after CPI failures")
 	fmt.Fprintln(out, "  - On environment branches (dev/qas/prd), creates and pushes a git tag named <transportId>")
 	fmt.Fprintln(out, "")
@@ -167,3 +352,24 @@ func syncPushHelp(ctx *app.Context) {
 	fmt.Fprintln(out, "  iflowkit sync push --message \"Update iFlow step\"")
 	fmt.Fprintln(out, "")
 }
+
+func syncLockHelp(ctx *app.Context) {
+	out := ctx.Stdout
+	fmt.Fprintln(out, "Show or release the locks that keep sync runs from overlapping")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  iflowkit sync lock status")
+	fmt.Fprintln(out, "  iflowkit sync lock release (--env dev|qas|prd | --local) --force")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Locks:")
+	fmt.Fprintln(out, "  - Local: one sync run at a time per clone (a file in the git directory, never committed); a lock of a process that no longer exists is replaced")
+	fmt.Fprintln(out, "  - Tenant: one writing run per tenant for the whole team, stored on origin as refs/iflowkit/locks/<env> (owner, command, expiry)")
+	fmt.Fprintln(out, "  - push, pull, deliver, rollback, deploy run and transport retry/abandon take both; a pull into a work branch takes only the local lock")
+	fmt.Fprintln(out, "  - A running command renews its tenant lock; a lock not renewed for 15 minutes expires and is taken over by the next run")
+	fmt.Fprintln(out, "  - --lock-wait <duration> on those commands waits for a held lock instead of failing at once")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Notes:")
+	fmt.Fprintln(out, "  - status lists the local lock and the lock of every tenant (free, held, expired)")
+	fmt.Fprintln(out, "  - release removes a stale lock left by a crashed or interrupted run; --force is required")
+	fmt.Fprintln(out, "")
+}
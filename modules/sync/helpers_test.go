@@ -0,0 +1,90 @@
+package sync
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/paths"
+	"github.com/iflowkit/iflowkit-cli/internal/store"
+)
+
+// newTestContext returns a context with a config root (profiles, logs) in a temp dir and
+// discarded console output.
+func newTestContext(t *testing.T) *app.Context {
+	t.Helper()
+	root := t.TempDir()
+	p := &paths.Paths{
+		ConfigRoot:        root,
+		ProfilesDir:       filepath.Join(root, "profiles"),
+		ConfigFile:        filepath.Join(root, "config.json"),
+		ActiveProfileFile: filepath.Join(root, "active_profile"),
+		LogsDir:           filepath.Join(root, "logs"),
+	}
+	lg, err := logging.New(logging.Options{LogsDir: p.LogsDir, Level: "error", Format: "text", Stdout: io.Discard, Stderr: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = lg.Close() })
+	return &app.Context{
+		Stdin: strings.NewReader(""), Stdout: io.Discard, Stderr: io.Discard,
+		Paths: p, Logger: lg, Stores: store.NewStores(p, lg),
+	}
+}
+
+// testRepo is a throwaway git repository with a deterministic identity.
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	for k, v := range map[string]string{
+		"GIT_AUTHOR_NAME": "Test", "GIT_AUTHOR_EMAIL": "test@example.com",
+		"GIT_COMMITTER_NAME": "Test", "GIT_COMMITTER_EMAIL": "test@example.com",
+		"GIT_CONFIG_NOSYSTEM": "1", "GIT_CONFIG_GLOBAL": os.DevNull,
+	} {
+		t.Setenv(k, v)
+	}
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("init", "-q", "-b", "main")
+	return r
+}
+
+// git runs a git command in the repository and returns its trimmed output.
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes files (path -> content) and commits them, returning the new commit SHA.
+func (r *testRepo) commit(msg string, files map[string]string) string {
+	r.t.Helper()
+	for p, content := range files {
+		full := filepath.Join(r.dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			r.t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	r.git("add", "-A")
+	r.git("commit", "-q", "--allow-empty", "-m", msg)
+	return r.git("rev-parse", "HEAD")
+}
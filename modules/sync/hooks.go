@@ -0,0 +1,276 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+	"github.com/iflowkit/iflowkit-cli/internal/validate"
+)
+
+const (
+	repoHooksFileName  = "hooks.json"
+	repoHooksDirName   = "hooks"
+	defaultHookTimeout = 10 * time.Minute
+)
+
+// Hook events. Pre-hooks run before any git or CPI change and abort the command on a non-zero
+// exit; post-hooks run after the transport record was committed and only log failures.
+const (
+	hookPrePush     = "pre-push"
+	hookPostPush    = "post-push"
+	hookPreDeliver  = "pre-deliver"
+	hookPostDeliver = "post-deliver"
+	hookPostPull    = "post-pull"
+)
+
+var hookEvents = []string{hookPrePush, hookPostPush, hookPreDeliver, hookPostDeliver, hookPostPull}
+
+// HooksFile is .iflowkit/hooks.json:
+//
+//	{"hooks": {"pre-push": [{"name": "lint", "run": "make lint", "envs": ["qas", "prd"]}]}}
+type HooksFile struct {
+	Hooks map[string][]HookSpec `json:"hooks"`
+}
+
+// HookSpec is one command of an event. Run is executed by the shell (sh -c, cmd /C on Windows)
+// in the repository root.
+type HookSpec struct {
+	Name string `json:"name,omitempty"`
+	Run  string `json:"run"`
+	// Envs limits the hook to these tenants; empty means all.
+	Envs []string `json:"envs,omitempty"`
+	// Timeout is a Go duration (default 10m).
+	Timeout string `json:"timeout,omitempty"`
+}
+
+type repoHook struct {
+	name    string
+	argv    []string
+	envs    []string
+	timeout time.Duration
+}
+
+// repoHooks are the hooks of a repository by event: hooks.json entries first, then the
+// executable .iflowkit/hooks/<event>, if present.
+type repoHooks struct {
+	repoRoot string
+	byEvent  map[string][]repoHook
+}
+
+// hookRun is what a hook is told about the command.
+type hookRun struct {
+	Event  string
+	Env    string
+	Branch string
+	Record TransportRecord
+	// Result and Err are set for post-hooks.
+	Result string // succeeded|failed
+	Err    error
+}
+
+// loadRepoHooks reads .iflowkit/hooks.json and .iflowkit/hooks/. Missing files mean no hooks.
+func loadRepoHooks(repoRoot string) (*repoHooks, error) {
+	h := &repoHooks{repoRoot: repoRoot, byEvent: map[string][]repoHook{}}
+
+	p := filepath.Join(repoRoot, ".iflowkit", repoHooksFileName)
+	b, err := os.ReadFile(p)
+	switch {
+	case err == nil:
+		var f HooksFile
+		if err := json.Unmarshal(b, &f); err != nil {
+			return nil, fmt.Errorf("invalid .iflowkit/%s: %w", repoHooksFileName, err)
+		}
+		for event, specs := range f.Hooks {
+			if !slices.Contains(hookEvents, event) {
+				return nil, fmt.Errorf("invalid .iflowkit/%s: unknown hook event %q (expected one of %s)", repoHooksFileName, event, strings.Join(hookEvents, ", "))
+			}
+			for i, s := range specs {
+				hook, err := s.compile(event, i)
+				if err != nil {
+					return nil, fmt.Errorf("invalid .iflowkit/%s: %w", repoHooksFileName, err)
+				}
+				h.byEvent[event] = append(h.byEvent[event], hook)
+			}
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	for _, event := range hookEvents {
+		p := filepath.Join(repoRoot, ".iflowkit", repoHooksDirName, event)
+		st, err := os.Stat(p)
+		if err != nil || st.IsDir() {
+			continue
+		}
+		if runtime.GOOS != "windows" && st.Mode().Perm()&0o111 == 0 {
+			return nil, fmt.Errorf("hook .iflowkit/%s/%s is not executable; run `chmod +x` on it", repoHooksDirName, event)
+		}
+		h.byEvent[event] = append(h.byEvent[event], repoHook{name: repoHooksDirName + "/" + event, argv: []string{p}, timeout: defaultHookTimeout})
+	}
+	return h, nil
+}
+
+func (s HookSpec) compile(event string, i int) (repoHook, error) {
+	name := strings.TrimSpace(s.Name)
+	if name == "" {
+		name = fmt.Sprintf("%s[%d]", event, i)
+	}
+	if strings.TrimSpace(s.Run) == "" {
+		return repoHook{}, fmt.Errorf("hook %s has no run command", name)
+	}
+	h := repoHook{name: name, timeout: defaultHookTimeout}
+	if runtime.GOOS == "windows" {
+		h.argv = []string{"cmd", "/C", s.Run}
+	} else {
+		h.argv = []string{"sh", "-c", s.Run}
+	}
+	for _, e := range s.Envs {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if err := validate.Env(e); err != nil {
+			return repoHook{}, fmt.Errorf("hook %s: %w", name, err)
+		}
+		h.envs = append(h.envs, e)
+	}
+	if s.Timeout != "" {
+		d, err := time.ParseDuration(s.Timeout)
+		if err != nil || d <= 0 {
+			return repoHook{}, fmt.Errorf("hook %s: invalid timeout %q", name, s.Timeout)
+		}
+		h.timeout = d
+	}
+	return h, nil
+}
+
+// has reports whether event has a hook for env.
+func (h *repoHooks) has(event, env string) bool {
+	return len(h.forEnv(event, env)) > 0
+}
+
+func (h *repoHooks) forEnv(event, env string) []repoHook {
+	var out []repoHook
+	for _, hook := range h.byEvent[event] {
+		if len(hook.envs) == 0 || slices.Contains(hook.envs, env) {
+			out = append(out, hook)
+		}
+	}
+	return out
+}
+
+// run executes the hooks of r.Event in order and stops at the first failure. Each hook gets the
+// transport record as JSON on stdin and IFLOWKIT_* variables in its environment.
+func (h *repoHooks) run(ctx *app.Context, meta models.SyncMetadata, r hookRun) error {
+	hooks := h.forEnv(r.Event, r.Env)
+	if len(hooks) == 0 {
+		return nil
+	}
+	rec := r.Record
+	rec.Error = logging.Redact(rec.Error)
+	stdin, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	env := append(os.Environ(),
+		"IFLOWKIT_HOOK="+r.Event,
+		"IFLOWKIT_REPO_ROOT="+h.repoRoot,
+		"IFLOWKIT_PACKAGE_ID="+meta.PackageID,
+		"IFLOWKIT_ENV="+r.Env,
+		"IFLOWKIT_BRANCH="+r.Branch,
+		"IFLOWKIT_TRANSPORT_ID="+r.Record.TransportID,
+		"IFLOWKIT_TRANSPORT_TYPE="+r.Record.TransportType,
+		"IFLOWKIT_TRANSPORT_STATUS="+r.Record.TransportStatus,
+	)
+	if r.Result != "" {
+		env = append(env, "IFLOWKIT_RESULT="+r.Result)
+	}
+	if r.Err != nil {
+		env = append(env, "IFLOWKIT_ERROR="+logging.Redact(r.Err.Error()))
+	}
+
+	for _, hook := range hooks {
+		ctx.Logger.Info("running hook", logging.F("event", r.Event), logging.F("hook", hook.name))
+		started := time.Now()
+		cctx, cancel := context.WithTimeout(context.Background(), hook.timeout)
+		cmd := exec.CommandContext(cctx, hook.argv[0], hook.argv[1:]...)
+		cmd.Dir = h.repoRoot
+		cmd.Env = env
+		cmd.Stdin = bytes.NewReader(stdin)
+		cmd.Stdout = ctx.Stdout
+		cmd.Stderr = ctx.Stderr
+		err := cmd.Run()
+		timedOut := cctx.Err() == context.DeadlineExceeded
+		cancel()
+		if timedOut {
+			err = fmt.Errorf("timed out after %s", hook.timeout)
+		}
+		if err != nil {
+			return fmt.Errorf("%s hook %s failed: %w", r.Event, hook.name, err)
+		}
+		ctx.Logger.Info("hook finished", logging.F("event", r.Event), logging.F("hook", hook.name), logging.F("duration", time.Since(started).Round(time.Millisecond).String()))
+	}
+	return nil
+}
+
+// runPostHooks runs the post-hooks of event for rec. Failures are logged; the command result stands.
+func (h *repoHooks) runPostHooks(ctx *app.Context, meta models.SyncMetadata, event, env, branch string, rec TransportRecord, cmdErr error) {
+	r := hookRun{Event: event, Env: env, Branch: branch, Record: rec, Result: "succeeded", Err: cmdErr}
+	if cmdErr != nil {
+		r.Result = "failed"
+	}
+	if err := h.run(ctx, meta, r); err != nil {
+		ctx.Logger.Warn("post hook failed", logging.F("event", event), logging.F("error", err.Error()))
+	}
+}
+
+// plannedTransportRecord describes the transport a plan would run; pre-hooks receive it on stdin.
+// Its status is "planned"; id is the transport id the run will use.
+func plannedTransportRecord(ctx *app.Context, repoRoot string, p *SyncPlan, transportType, id, createdAt string) TransportRecord {
+	name, email, _ := gitUserIdentity(ctx, repoRoot)
+	rec := TransportRecord{
+		SchemaVersion:   1,
+		TransportID:     id,
+		TransportType:   transportType,
+		PackageID:       p.PackageID,
+		Branch:          p.Branch,
+		CreatedAt:       createdAt,
+		GitCommits:      []string{},
+		GitUserName:     name,
+		GitUserEmail:    email,
+		Objects:         []SyncObject{},
+		TransportStatus: "planned",
+		UploadRemaining: []artifactKey{},
+		DeployRemaining: []deployTarget{},
+	}
+	for _, c := range p.Commits {
+		if c.SHA != "" {
+			rec.GitCommits = append(rec.GitCommits, c.SHA)
+		}
+	}
+	// Like a real record, every changed artifact is queued; the plan's skips show up as skipped steps.
+	for _, a := range p.Upload {
+		rec.Objects = append(rec.Objects, SyncObject{Kind: a.Kind, ID: a.ID})
+		rec.UploadRemaining = append(rec.UploadRemaining, artifactKey{Kind: a.Kind, ID: a.ID})
+	}
+	for _, a := range p.Delete {
+		rec.DeletedObjects = append(rec.DeletedObjects, SyncObject{Kind: a.Kind, ID: a.ID})
+		rec.DeleteRemaining = append(rec.DeleteRemaining, artifactKey{Kind: a.Kind, ID: a.ID})
+	}
+	for _, a := range p.Deploy {
+		if a.Action != "skip" {
+			rec.DeployRemaining = append(rec.DeployRemaining, deployTarget{Kind: a.Kind, ID: a.ID})
+		}
+	}
+	return rec
+}
@@ -0,0 +1,138 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+)
+
+// syncLockFile lives in the git directory so it is never committed by the logs commits.
+const syncLockFile = "iflowkit-sync.lock"
+
+// SyncLock is the content of the local sync lock file.
+type SyncLock struct {
+	Command    string `json:"command"`
+	PID        int    `json:"pid"`
+	Host       string `json:"host"`
+	AcquiredAt string `json:"acquiredAt"`
+}
+
+// lockWaitFlag registers --lock-wait on a command that takes the sync locks.
+func lockWaitFlag(fs *flag.FlagSet) *time.Duration {
+	return fs.Duration("lock-wait", 0, "Wait up to this long (e.g. 10m) when another sync run holds the lock; default: fail at once")
+}
+
+// acquireSyncLock takes the locks of a command that changes the repository or a tenant:
+// the local lock of this clone and, when env is set, the team-wide lease of that tenant
+// (see acquireTenantLease). While another run holds either, it waits up to wait and then fails.
+// The returned release frees both.
+func acquireSyncLock(ctx *app.Context, repoRoot, command, env string, wait time.Duration) (release func(), err error) {
+	deadline := time.Now().Add(wait)
+	releaseLocal, err := acquireLocalSyncLock(ctx, repoRoot, command, deadline)
+	if err != nil {
+		return nil, err
+	}
+	if env == "" {
+		return releaseLocal, nil
+	}
+	releaseLease, err := acquireTenantLease(ctx, repoRoot, env, command, time.Until(deadline))
+	if err != nil {
+		releaseLocal()
+		return nil, err
+	}
+	return func() {
+		releaseLease()
+		releaseLocal()
+	}, nil
+}
+
+// acquireLocalSyncLock takes the repository-wide lock that keeps sync commands from running
+// at the same time in one clone. A lock left by a process that no longer exists is replaced.
+func acquireLocalSyncLock(ctx *app.Context, repoRoot, command string, deadline time.Time) (release func(), err error) {
+	path, err := syncLockPath(ctx, repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	lock := SyncLock{Command: command, PID: os.Getpid(), Host: host, AcquiredAt: time.Now().UTC().Format(time.RFC3339)}
+	b, _ := json.Marshal(lock)
+
+	staleRemoved, waiting := false, false
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, werr := f.Write(b)
+			cerr := f.Close()
+			if werr != nil || cerr != nil {
+				_ = os.Remove(path)
+				return nil, fmt.Errorf("cannot write sync lock: %w", errors.Join(werr, cerr))
+			}
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("cannot create sync lock: %w", err)
+		}
+		held, rerr := readSyncLock(path)
+		if rerr == nil && held.Host == host && !processAlive(held.PID) && !staleRemoved {
+			ctx.Logger.Warn("removing stale sync lock", logging.F("command", held.Command), logging.F("pid", held.PID), logging.F("acquiredAt", held.AcquiredAt))
+			_ = os.Remove(path)
+			staleRemoved = true
+			continue
+		}
+		if time.Now().Before(deadline) {
+			if !waiting {
+				fmt.Fprintln(ctx.Stdout, "Waiting for another sync run in this repository to finish...")
+				waiting = true
+			}
+			time.Sleep(time.Second)
+			continue
+		}
+		if rerr != nil {
+			return nil, fmt.Errorf("another sync command holds the lock (%s)", path)
+		}
+		return nil, fmt.Errorf("sync %s is already running in this repository (pid %d on %s since %s); if that process is gone, remove the lock with `iflowkit sync lock release --local --force`", held.Command, held.PID, held.Host, held.AcquiredAt)
+	}
+}
+
+func syncLockPath(ctx *app.Context, repoRoot string) (string, error) {
+	gitDir, err := runGitOutput(ctx, repoRoot, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(repoRoot, gitDir)
+	}
+	return filepath.Join(gitDir, syncLockFile), nil
+}
+
+func readSyncLock(path string) (SyncLock, error) {
+	var l SyncLock
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return l, err
+	}
+	err = json.Unmarshal(b, &l)
+	return l, err
+}
+
+// processAlive reports whether pid refers to a running process on this host.
+// Where signals cannot be sent (e.g. Windows), processes are assumed to be alive.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || !errors.Is(err, os.ErrProcessDone) && !errors.Is(err, syscall.ESRCH)
+}
@@ -0,0 +1,375 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+const currentPlanSchemaVersion = 1
+
+// Plan check statuses.
+const (
+	planCheckOK      = "ok"
+	planCheckWarn    = "warn"
+	planCheckFail    = "fail"
+	planCheckSkipped = "skipped"
+)
+
+// SyncPlan describes what push/pull/deliver would do, computed without writing to git remotes or CPI.
+type SyncPlan struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Command       string `json:"command"` // push|pull|deliver|rollback|deploy|retry
+	PackageID     string `json:"packageId"`
+	Branch        string `json:"branch"`
+	SourceBranch  string `json:"sourceBranch,omitempty"`
+	Tenant        string `json:"tenant"`
+
+	// ResumeTransport is set when a pending transport record would be continued.
+	ResumeTransport string `json:"resumeTransport,omitempty"`
+
+	Commits []PlanCommit   `json:"commits"`
+	Delete  []PlanArtifact `json:"delete"`
+	Upload  []PlanArtifact `json:"upload"`
+	Deploy  []PlanArtifact `json:"deploy"`
+	// Deferred lists artifacts left out by --only/--exclude; they stay queued in the transport record.
+	Deferred []PlanArtifact `json:"deferred,omitempty"`
+	// RepoChanges lists artifacts changed in git by pull (CPI -> repo).
+	RepoChanges []PlanArtifact `json:"repoChanges,omitempty"`
+
+	Preflight []PlanCheck `json:"preflight"`
+	Notes     []string    `json:"notes,omitempty"`
+	PlanHash  string      `json:"planHash,omitempty"`
+
+	// confirming is set when the plan is shown for PRD confirmation instead of --dry-run.
+	confirming bool
+}
+
+// PlanCommit is an existing commit to push or a commit the command would create (Planned).
+type PlanCommit struct {
+	SHA     string `json:"sha,omitempty"`
+	Subject string `json:"subject"`
+	Planned bool   `json:"planned,omitempty"`
+}
+
+type PlanArtifact struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type PlanCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func newSyncPlan(command, packageID, branch, tenant string) *SyncPlan {
+	return &SyncPlan{
+		SchemaVersion: currentPlanSchemaVersion,
+		Command:       command,
+		PackageID:     packageID,
+		Branch:        branch,
+		Tenant:        tenant,
+		Commits:       []PlanCommit{},
+		Delete:        []PlanArtifact{},
+		Upload:        []PlanArtifact{},
+		Deploy:        []PlanArtifact{},
+		Preflight:     []PlanCheck{},
+	}
+}
+
+func (p *SyncPlan) check(name, status, detail string) {
+	p.Preflight = append(p.Preflight, PlanCheck{Name: name, Status: status, Detail: detail})
+}
+
+func (p *SyncPlan) note(format string, args ...any) {
+	p.Notes = append(p.Notes, fmt.Sprintf(format, args...))
+}
+
+// failedChecks returns the number of failing preflight checks.
+func (p *SyncPlan) failedChecks() int {
+	n := 0
+	for _, c := range p.Preflight {
+		if c.Status == planCheckFail {
+			n++
+		}
+	}
+	return n
+}
+
+// Hash returns a sha256 of the planned changes (preflight results and notes excluded),
+// so a recorded confirmation can be matched against a later --dry-run.
+func (p *SyncPlan) Hash() string {
+	c := *p
+	c.Preflight = nil
+	c.Notes = nil
+	c.PlanHash = ""
+	b, _ := json.Marshal(c)
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (p *SyncPlan) empty() bool {
+	return len(p.Commits) == 0 && len(p.Deferred) == 0 && len(p.Delete) == 0 && len(p.Upload) == 0 && len(p.Deploy) == 0 && len(p.RepoChanges) == 0
+}
+
+// finishPlan prints the plan and turns failing preflight checks into an error (non-zero exit).
+func finishPlan(ctx *app.Context, p *SyncPlan) error {
+	p.PlanHash = p.Hash()
+	if err := ctx.Render(p); err != nil {
+		return err
+	}
+	if n := p.failedChecks(); n > 0 {
+		return fmt.Errorf("dry-run: %d preflight check(s) failed", n)
+	}
+	return nil
+}
+
+func (p *SyncPlan) ResultKind() string { return "SyncPlan" }
+
+// WriteTable prints the human-readable plan.
+func (p *SyncPlan) WriteTable(out io.Writer) error {
+	target := p.Branch
+	if p.SourceBranch != "" {
+		target = p.SourceBranch + " -> " + p.Branch
+	}
+	mode := "dry-run"
+	if p.confirming {
+		mode = "awaiting PRD confirmation"
+	}
+	fmt.Fprintf(out, "Plan: sync %s (%s)\n", p.Command, mode)
+	fmt.Fprintf(out, "  Package: %s\n", p.PackageID)
+	fmt.Fprintf(out, "  Branch:  %s\n", target)
+	fmt.Fprintf(out, "  Tenant:  %s\n", tenantDisplay(p.Tenant))
+	if p.ResumeTransport != "" {
+		fmt.Fprintf(out, "  Resumes pending transport: %s\n", p.ResumeTransport)
+	}
+
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Preflight:")
+	if len(p.Preflight) == 0 {
+		fmt.Fprintln(out, "  (none)")
+	}
+	for _, c := range p.Preflight {
+		line := fmt.Sprintf("  [%-7s] %s", strings.ToUpper(c.Status), c.Name)
+		if c.Detail != "" {
+			line += ": " + c.Detail
+		}
+		fmt.Fprintln(out, line)
+	}
+
+	fmt.Fprintln(out, "")
+	fmt.Fprintf(out, "Commits (%d):\n", len(p.Commits))
+	for _, c := range p.Commits {
+		sha := c.SHA
+		if c.Planned {
+			sha = "(new)"
+		} else if len(sha) > 10 {
+			sha = sha[:10]
+		}
+		fmt.Fprintf(out, "  %-10s %s\n", sha, c.Subject)
+	}
+
+	printPlanArtifacts(out, "Repository changes", p.RepoChanges, p.Command == "pull")
+	printPlanArtifacts(out, "CPI delete", p.Delete, p.Command != "pull" && p.Command != "deploy")
+	printPlanArtifacts(out, "CPI upload", p.Upload, p.Command != "pull" && p.Command != "deploy")
+	printPlanArtifacts(out, "CPI deploy", p.Deploy, p.Command != "pull")
+	printPlanArtifacts(out, "Deferred", p.Deferred, false)
+
+	if len(p.Notes) > 0 {
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, "Notes:")
+		for _, n := range p.Notes {
+			fmt.Fprintf(out, "  - %s\n", n)
+		}
+	}
+	fmt.Fprintln(out, "")
+	switch {
+	case p.failedChecks() > 0:
+		fmt.Fprintln(out, "Preflight failed; the command would stop before completing this plan.")
+	case p.empty():
+		fmt.Fprintln(out, "Nothing to do.")
+	case p.confirming:
+	default:
+		fmt.Fprintln(out, "No changes were made (dry-run).")
+	}
+	return nil
+}
+
+func printPlanArtifacts(out io.Writer, title string, list []PlanArtifact, always bool) {
+	if len(list) == 0 && !always {
+		return
+	}
+	fmt.Fprintln(out, "")
+	fmt.Fprintf(out, "%s (%d):\n", title, len(list))
+	for _, a := range list {
+		line := fmt.Sprintf("  %-8s %-16s %s", a.Action, a.Kind, a.ID)
+		if a.Reason != "" {
+			line += "  (" + a.Reason + ")"
+		}
+		fmt.Fprintln(out, line)
+	}
+}
+
+// isDeployableKind reports whether CPI exposes a deploy endpoint for the kind.
+func isDeployableKind(kind string) bool {
+	switch kind {
+	case "iFlows", "Scripts", "ValueMappings", "MessageMappings":
+		return true
+	default:
+		return false
+	}
+}
+
+func sortArtifactKeys(list []artifactKey) []artifactKey {
+	out := append([]artifactKey{}, list...)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind == out[j].Kind {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].Kind < out[j].Kind
+	})
+	return out
+}
+
+// planTenantClient resolves the tenant key for env and verifies it with a token request.
+// Failures are recorded as preflight checks; nil is returned when CPI cannot be used.
+func planTenantClient(ctx *app.Context, p *SyncPlan, env string) *cpix.Client {
+	profileID, _, err := ctx.Stores.ResolveProfileID(ctx.Flags.ProfileID)
+	if err != nil {
+		p.check("tenant key", planCheckFail, err.Error())
+		return nil
+	}
+	key, source, err := ctx.Stores.Tenants.ReadWithSource(profileID, env)
+	if err != nil {
+		p.check("tenant key", planCheckFail, fmt.Sprintf("%s tenant not found for profile %q: %v", tenantDisplay(env), profileID, err))
+		return nil
+	}
+	p.check("tenant key", planCheckOK, fmt.Sprintf("%s/%s (source: %s)", profileID, env, source))
+	client := newTenantClient(ctx, profileID, env, key)
+	if err := client.VerifyAuth(context.Background()); err != nil {
+		p.check("tenant auth", planCheckFail, err.Error())
+		return nil
+	}
+	p.check("tenant auth", planCheckOK, key.TokenHost())
+	return client
+}
+
+// planCPIActions fills the delete/upload/deploy sections like the CPI phase of push/deliver would execute them.
+// exists reports whether an artifact folder is present in the content that would be uploaded.
+func planCPIActions(ctx *app.Context, p *SyncPlan, client *cpix.Client, meta models.SyncMetadata, uploads, deletes []artifactKey, pendingDeploy []deployTarget, exists func(artifactKey) bool) {
+	for _, k := range sortArtifactKeys(deletes) {
+		p.Delete = append(p.Delete, PlanArtifact{Kind: k.Kind, ID: k.ID, Action: "delete"})
+	}
+
+	arts := map[string]map[string]cpix.ArtifactInfo{}
+	if client != nil {
+		kinds := map[string]struct{}{}
+		for _, k := range uploads {
+			kinds[k.Kind] = struct{}{}
+		}
+		for kind := range kinds {
+			endpoint, ok := listEndpointForKind(meta.PackageID, kind)
+			if !ok {
+				continue
+			}
+			m, err := client.ListArtifacts(context.Background(), endpoint)
+			if err != nil {
+				p.check("list "+kind, planCheckFail, err.Error())
+				continue
+			}
+			arts[kind] = m
+		}
+	}
+
+	deploy := append([]deployTarget{}, pendingDeploy...)
+	for _, k := range sortArtifactKeys(uploads) {
+		a := PlanArtifact{Kind: k.Kind, ID: k.ID, Action: "update"}
+		switch {
+		case !exists(k):
+			a.Action, a.Reason = "skip", "artifact directory missing"
+		case kindToEntitySet(k.Kind) == "":
+			a.Action, a.Reason = "skip", "kind not supported for CPI updates"
+		case client == nil:
+			a.Reason = "tenant not checked"
+		default:
+			if _, ok := arts[k.Kind][k.ID]; !ok {
+				if _, listed := arts[k.Kind]; listed {
+					a.Action, a.Reason = "skip", "not found in tenant; artifact creation is not supported"
+				} else {
+					a.Reason = "tenant not checked"
+				}
+			}
+		}
+		p.Upload = append(p.Upload, a)
+		if a.Action == "update" && isDeployableKind(k.Kind) {
+			deploy = mergeDeployRemaining(deploy, []deployTarget{{Kind: k.Kind, ID: k.ID}})
+		}
+	}
+	sort.Slice(deploy, func(i, j int) bool {
+		if deploy[i].Kind == deploy[j].Kind {
+			return deploy[i].ID < deploy[j].ID
+		}
+		return deploy[i].Kind < deploy[j].Kind
+	})
+	for _, d := range deploy {
+		a := PlanArtifact{Kind: d.Kind, ID: d.ID, Action: "deploy"}
+		if !isDeployableKind(d.Kind) {
+			a.Action, a.Reason = "skip", "deploy kind not supported"
+		}
+		p.Deploy = append(p.Deploy, a)
+	}
+}
+
+// planCommitSummaries returns commit subjects for the given SHAs (oldest first, as given).
+func planCommitSummaries(ctx *app.Context, repoRoot string, shas []string) []PlanCommit {
+	out := make([]PlanCommit, 0, len(shas))
+	for _, sha := range shas {
+		subject, err := runGitOutput(ctx, repoRoot, "log", "-1", "--format=%s", sha)
+		if err != nil {
+			subject = ""
+		}
+		out = append(out, PlanCommit{SHA: sha, Subject: strings.TrimSpace(subject)})
+	}
+	return out
+}
+
+// gitRefExists reports whether ref resolves to a commit.
+func gitRefExists(ctx *app.Context, dir, ref string) bool {
+	_, err := runGitOutput(ctx, dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return err == nil
+}
+
+// withRefWorktree checks out ref into a temporary detached worktree, calls fn, and removes the worktree.
+// The current checkout of repoRoot is left untouched.
+func withRefWorktree(ctx *app.Context, repoRoot, ref string, fn func(dir string) error) error {
+	tmp, err := os.MkdirTemp("", "iflowkit-plan-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	wt := filepath.Join(tmp, "tree")
+	if err := runGit(ctx, repoRoot, "worktree", "add", "--detach", wt, ref); err != nil {
+		return err
+	}
+	defer func() {
+		if err := runGit(ctx, repoRoot, "worktree", "remove", "--force", wt); err != nil {
+			ctx.Logger.Warn("failed to remove temporary worktree", logging.F("path", wt), logging.F("error", err.Error()))
+		}
+	}()
+	return fn(wt)
+}
@@ -0,0 +1,87 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/prompt"
+)
+
+// Confirmation methods recorded in TransportConfirmation.Method.
+const (
+	confirmInteractive = "interactive"
+	confirmYesFlag     = "yes-flag"
+)
+
+// TransportConfirmation records who approved a PRD run, when, and which plan they saw.
+type TransportConfirmation struct {
+	ConfirmedBy      string `json:"confirmedBy"`
+	ConfirmedByEmail string `json:"confirmedByEmail,omitempty"`
+	OSUser           string `json:"osUser,omitempty"`
+	ConfirmedAt      string `json:"confirmedAt"`
+	Method           string `json:"method"` // interactive | yes-flag
+	PlanHash         string `json:"planHash"`
+}
+
+// confirmPRD asks for an explicit approval before push/pull/deliver touch the PRD tenant.
+//
+// On a TTY the plan is printed and the user must type the package id. Non-interactive
+// runs must pass --yes (in addition to --to prd). The plan is computed in both cases so
+// the approval can be tied to a plan hash.
+func confirmPRD(ctx *app.Context, repoRoot string, plan *SyncPlan, yes bool) (*TransportConfirmation, error) {
+	interactive := prompt.IsTerminal(ctx.Stdin)
+	plan.confirming = true
+	if !interactive && !yes {
+		return nil, fmt.Errorf("PRD %s requires confirmation: stdin is not a terminal, pass --yes together with --to prd", plan.Command)
+	}
+	if n := plan.failedChecks(); n > 0 {
+		if interactive {
+			_ = plan.WriteTable(ctx.Stdout)
+		}
+		return nil, fmt.Errorf("PRD %s aborted: %d preflight check(s) failed; run with --dry-run for details", plan.Command, n)
+	}
+
+	name, email, _ := gitUserIdentity(ctx, repoRoot)
+	conf := &TransportConfirmation{
+		ConfirmedBy:      name,
+		ConfirmedByEmail: email,
+		OSUser:           currentOSUser(),
+		PlanHash:         plan.Hash(),
+		Method:           confirmYesFlag,
+	}
+	if conf.ConfirmedBy == "" {
+		conf.ConfirmedBy = conf.OSUser
+	}
+	if interactive {
+		if err := plan.WriteTable(ctx.Stdout); err != nil {
+			return nil, err
+		}
+		fmt.Fprintln(ctx.Stdout, "")
+		io := prompt.NewIO(ctx.Stdin, ctx.Stdout)
+		typed, err := io.AskString(fmt.Sprintf("This will change the PRD tenant. Type the package id (%s) to continue", plan.PackageID), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if typed != plan.PackageID {
+			return nil, fmt.Errorf("PRD %s cancelled: confirmation did not match package id %q", plan.Command, plan.PackageID)
+		}
+		conf.Method = confirmInteractive
+	}
+	conf.ConfirmedAt = time.Now().UTC().Format(time.RFC3339)
+
+	ctx.Logger.Info("PRD run confirmed", logging.F("command", plan.Command), logging.F("by", conf.ConfirmedBy), logging.F("method", conf.Method), logging.F("planHash", conf.PlanHash))
+	return conf, nil
+}
+
+func currentOSUser() string {
+	for _, k := range []string{"USER", "USERNAME", "LOGNAME"} {
+		if v := strings.TrimSpace(os.Getenv(k)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
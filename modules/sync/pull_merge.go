@@ -0,0 +1,137 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// LocalReapply reports how local edits stashed by sync pull were merged back on top of the tenant state.
+type LocalReapply struct {
+	Paths     int  `json:"paths"`
+	Reapplied bool `json:"reapplied"`
+	// Conflicts are artifacts changed both locally and on the tenant. They are left at the
+	// tenant version; the local version stays in Stash.
+	Conflicts []SyncObject `json:"conflicts,omitempty"`
+	// ConflictPaths are overlapping files outside artifact folders, handled the same way.
+	ConflictPaths []string `json:"conflictPaths,omitempty"`
+	// Stash is the commit of the kept stash; empty when it was dropped.
+	Stash string `json:"stash,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// stashPullChanges stashes local work (including untracked files) before the tenant export
+// and returns the stash commit, which stays valid when other stashes are pushed later.
+func stashPullChanges(ctx *app.Context, repoRoot, message string) (string, error) {
+	if err := runGit(ctx, repoRoot, "stash", "push", "-u", "-m", message); err != nil {
+		return "", err
+	}
+	sha, err := runGitOutput(ctx, repoRoot, "rev-parse", "stash@{0}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sha), nil
+}
+
+// reapplyPullStash merges the stashed local edits into the working tree. `git stash apply` is a
+// three-way merge: base is the commit the edits were made on, one side the committed tenant state,
+// the other the local edits. Edits that do not overlap are kept in the working tree (uncommitted).
+// Overlapping artifacts are reset to the tenant version as a whole and the stash is kept for them;
+// otherwise the stash is dropped.
+//
+// When failed is set the pull stopped half-way: the content folder is reset to HEAD first so the
+// partial export does not block the merge.
+func reapplyPullStash(ctx *app.Context, repoRoot string, meta models.SyncMetadata, stash string, paths int, failed bool) *LocalReapply {
+	r := &LocalReapply{Paths: paths, Stash: stash}
+	if failed {
+		contentPath := resolveContentFolder(meta)
+		_ = runGit(ctx, repoRoot, "reset", "-q", "HEAD", "--", contentPath)
+		_ = runGit(ctx, repoRoot, "checkout", "HEAD", "--", contentPath)
+		_ = runGit(ctx, repoRoot, "clean", "-fdq", "--", contentPath)
+	}
+
+	if err := runGit(ctx, repoRoot, "stash", "apply", stash); err == nil {
+		r.Reapplied = true
+		r.Stash = ""
+		if err := dropStash(ctx, repoRoot, stash); err != nil {
+			ctx.Logger.Warn("failed to drop the re-applied stash", logging.F("stash", stash), logging.F("error", err.Error()))
+		}
+		return r
+	}
+
+	out, _ := runGitOutput(ctx, repoRoot, "diff", "--name-only", "--diff-filter=U")
+	unmerged := splitLines(out)
+	if len(unmerged) == 0 {
+		// Not a content conflict (e.g. an untracked file collides with a file the tenant added).
+		r.Error = fmt.Sprintf("could not re-apply local changes automatically; they are kept in stash %s (restore with: git stash apply %s)", shortSHA(stash), stash)
+		return r
+	}
+
+	conflicts := detectChangedArtifacts(meta, unmerged)
+	for _, k := range mapKeysToSortedSlice(conflicts) {
+		resetPathToHead(ctx, repoRoot, artifactRepoDir(meta, k))
+	}
+	for _, p := range unmerged {
+		if len(detectChangedArtifacts(meta, []string{p})) == 0 {
+			resetPathToHead(ctx, repoRoot, p)
+			r.ConflictPaths = append(r.ConflictPaths, p)
+		}
+	}
+	// Leave the re-applied edits as plain working tree changes, like before the pull.
+	_ = runGit(ctx, repoRoot, "reset", "-q")
+	r.Reapplied = true
+	r.Conflicts = keysToObjects(conflicts)
+	return r
+}
+
+// resetPathToHead replaces p (file or folder) in the index and working tree with its HEAD version,
+// or removes it when HEAD does not have it.
+func resetPathToHead(ctx *app.Context, repoRoot, p string) {
+	_ = runGit(ctx, repoRoot, "rm", "-r", "-q", "-f", "--cached", "--ignore-unmatch", "--", p)
+	_ = os.RemoveAll(filepath.Join(repoRoot, filepath.FromSlash(p)))
+	if out, _ := runGitOutput(ctx, repoRoot, "ls-tree", "--name-only", "HEAD", "--", p); strings.TrimSpace(out) != "" {
+		_ = runGit(ctx, repoRoot, "checkout", "HEAD", "--", p)
+	}
+}
+
+// dropStash drops the stash entry whose commit is sha.
+func dropStash(ctx *app.Context, repoRoot, sha string) error {
+	out, err := runGitOutput(ctx, repoRoot, "stash", "list", "--format=%H")
+	if err != nil {
+		return err
+	}
+	for i, l := range splitLines(out) {
+		if l == sha {
+			return runGit(ctx, repoRoot, "stash", "drop", "-q", "stash@{"+strconv.Itoa(i)+"}")
+		}
+	}
+	return fmt.Errorf("stash %s not found", shortSHA(sha))
+}
+
+// summary is the human-readable outcome, one line per fact.
+func (r *LocalReapply) summary() []string {
+	switch {
+	case r.Error != "":
+		return []string{r.Error}
+	case len(r.Conflicts) == 0 && len(r.ConflictPaths) == 0:
+		return []string{fmt.Sprintf("Local changes (%d paths) were re-applied on top of the tenant state.", r.Paths)}
+	}
+	lines := []string{"Local changes overlap with tenant changes; these were left at the tenant version:"}
+	for _, o := range r.Conflicts {
+		lines = append(lines, fmt.Sprintf("  %s/%s", o.Kind, o.ID))
+	}
+	for _, p := range r.ConflictPaths {
+		lines = append(lines, "  "+p)
+	}
+	lines = append(lines,
+		"Other local changes were re-applied.",
+		fmt.Sprintf("Your version of the overlapping files is kept in stash %s; inspect with: git stash show -p %s", shortSHA(r.Stash), r.Stash),
+	)
+	return lines
+}
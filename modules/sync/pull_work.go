@@ -0,0 +1,274 @@
+package sync
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// workPullDiff is the artifact-level difference between a DEV export and a work branch.
+type workPullDiff struct {
+	// Added and Updated are selected and taken from the tenant.
+	Added   []artifactKey
+	Updated []artifactKey
+	// Unselected differ but are not matched by --only.
+	Unselected []artifactKey
+	// BranchOnly are missing on the tenant. A work-branch pull never deletes them:
+	// they are usually new work that has not been delivered to DEV yet.
+	BranchOnly []artifactKey
+	// Unmatched are --only patterns that match no differing artifact.
+	Unmatched []string
+}
+
+func (d *workPullDiff) selected() []artifactKey {
+	return append(append([]artifactKey{}, d.Added...), d.Updated...)
+}
+
+// diffWorkBranch compares the tenant export under tenantRoot with the branch checkout under branchRoot.
+// Both roots contain the content folder. Package-level files (list JSONs, IntegrationPackage.json)
+// are shared by every developer and are not taken.
+func diffWorkBranch(meta models.SyncMetadata, ign *RepoIgnore, sel *artifactSelector, tenantRoot, branchRoot string) (*workPullDiff, error) {
+	baseFolder := resolveContentFolder(meta)
+	diffs, err := CompareFolderTrees(baseFolder, filepath.Join(tenantRoot, baseFolder), filepath.Join(branchRoot, baseFolder), ign)
+	if err != nil {
+		return nil, err
+	}
+	onTenant, _ := listLocalArtifactKeys(tenantRoot, meta)
+	onBranch, _ := listLocalArtifactKeys(branchRoot, meta)
+
+	d := &workPullDiff{}
+	differing := mapKeysToSortedSlice(detectChangedArtifacts(meta, diffs))
+	for _, k := range differing {
+		_, tenantHas := onTenant[k]
+		_, branchHas := onBranch[k]
+		switch {
+		case !tenantHas:
+			d.BranchOnly = append(d.BranchOnly, k)
+		case !sel.match(k):
+			d.Unselected = append(d.Unselected, k)
+		case !branchHas:
+			d.Added = append(d.Added, k)
+		default:
+			d.Updated = append(d.Updated, k)
+		}
+	}
+	d.Unmatched = sel.unmatchedOnly(differing)
+	return d, nil
+}
+
+// planWorkBranchPull fills the pull plan for a feature/* or bugfix/* branch.
+// The branch is read from a temporary worktree of ref, so the current checkout is not touched.
+func planWorkBranchPull(ctx *app.Context, repoRoot string, meta models.SyncMetadata, sel *artifactSelector, branch, ref, message string) (*SyncPlan, error) {
+	p := newSyncPlan("pull", meta.PackageID, branch, "dev")
+	p.check("branch", planCheckOK, fmt.Sprintf("%s <- DEV tenant (work branch; no transport record)", branch))
+	if ref == "HEAD" {
+		p.note("%s does not exist yet; it would be created from the current HEAD", branch)
+	}
+	if dirty := gitPorcelainPaths(ctx, repoRoot); len(dirty) > 0 {
+		p.check("working tree", planCheckFail, fmt.Sprintf("not clean (%d paths)", len(dirty)))
+	} else {
+		p.check("working tree", planCheckOK, "clean")
+	}
+
+	client := planTenantClient(ctx, p, "dev")
+	if client == nil {
+		return p, nil
+	}
+	tmp, err := os.MkdirTemp("", "iflowkit-plan-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	tenantBase := filepath.Join(tmp, resolveContentFolder(meta))
+	if err := os.MkdirAll(tenantBase, 0o755); err != nil {
+		return nil, err
+	}
+	_, raw, err := client.ReadIntegrationPackage(meta.PackageID)
+	if err == nil {
+		err = client.ExportIntegrationPackageFromRaw(meta.PackageID, raw, tenantBase)
+	}
+	if err != nil {
+		p.check("tenant export", planCheckFail, err.Error())
+		return p, nil
+	}
+	p.check("tenant export", planCheckOK, "")
+
+	ign, err := LoadRepoIgnore(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	var d *workPullDiff
+	if err := withRefWorktree(ctx, repoRoot, ref, func(dir string) error {
+		d, err = diffWorkBranch(meta, ign, sel, tmp, dir)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, k := range d.Added {
+		p.RepoChanges = append(p.RepoChanges, PlanArtifact{Kind: k.Kind, ID: k.ID, Action: "add"})
+	}
+	for _, k := range d.Updated {
+		p.RepoChanges = append(p.RepoChanges, PlanArtifact{Kind: k.Kind, ID: k.ID, Action: "update"})
+	}
+	for _, k := range d.Unselected {
+		p.Deferred = append(p.Deferred, PlanArtifact{Kind: k.Kind, ID: k.ID, Action: "skip", Reason: "not selected by --only"})
+	}
+	for _, k := range d.BranchOnly {
+		p.Deferred = append(p.Deferred, PlanArtifact{Kind: k.Kind, ID: k.ID, Action: "keep", Reason: "not on the DEV tenant; work-branch pulls never delete"})
+	}
+	for _, pat := range d.Unmatched {
+		p.check("selection", planCheckWarn, fmt.Sprintf("--only %s matches no differing artifact", pat))
+	}
+	if len(p.RepoChanges) > 0 {
+		p.Commits = append(p.Commits, PlanCommit{Subject: workPullCommitSubject(branch, message), Planned: true})
+		p.note("the commit would be pushed to origin/%s", branch)
+	}
+	return p, nil
+}
+
+// runWorkBranchPull exports DEV and commits the selected artifacts that differ from branch.
+// When branch is not the current branch it is checked out (created from HEAD if it does not exist)
+// and the original branch is restored afterwards. No transport record is written: work branches
+// reach the tenants through push/deliver.
+func runWorkBranchPull(ctx *app.Context, repoRoot string, meta models.SyncMetadata, sel *artifactSelector, branch, message string, lockWait time.Duration) error {
+	if dirty := gitPorcelainPaths(ctx, repoRoot); len(dirty) > 0 {
+		return fmt.Errorf("working tree is not clean (%d paths); commit or stash your changes before pulling into %s", len(dirty), branch)
+	}
+
+	releaseLock, err := acquireSyncLock(ctx, repoRoot, "pull", "", lockWait)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	ctx.Logger.Info("sync pull started", logging.F("repo", repoRoot), logging.F("branch", branch), logging.F("tenant", "dev"), logging.F("packageId", meta.PackageID), logging.F("workBranch", true))
+
+	current, _ := gitCurrentBranch(ctx, repoRoot)
+	_ = runGit(ctx, repoRoot, "fetch", "origin", branch) // best-effort
+	if current != branch {
+		if err := checkoutBranch(ctx, repoRoot, branch); err != nil {
+			return err
+		}
+		defer func() {
+			if err := runGit(ctx, repoRoot, "checkout", current); err != nil {
+				ctx.Logger.Warn("failed to switch back to the original branch", logging.F("branch", current), logging.F("error", err.Error()))
+			}
+		}()
+	}
+
+	remoteRef := "origin/" + branch
+	if gitRemoteBranchExists(ctx, repoRoot, branch) {
+		behind, ahead := gitAheadBehind(ctx, repoRoot, remoteRef, "HEAD")
+		if behind > 0 && ahead > 0 {
+			return fmt.Errorf("local branch diverged from %s (ahead=%d, behind=%d); resolve with rebase/merge before running sync pull", remoteRef, ahead, behind)
+		}
+		if behind > 0 {
+			if err := runGit(ctx, repoRoot, "merge", "--ff-only", remoteRef); err != nil {
+				return err
+			}
+		}
+	}
+
+	tmp, err := os.MkdirTemp("", "iflowkit-pull-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := exportTenantPackage(ctx, meta, "dev", filepath.Join(tmp, resolveContentFolder(meta))); err != nil {
+		return err
+	}
+	ign, err := LoadRepoIgnore(repoRoot)
+	if err != nil {
+		return err
+	}
+	d, err := diffWorkBranch(meta, ign, sel, tmp, repoRoot)
+	if err != nil {
+		return err
+	}
+	for _, p := range d.Unmatched {
+		ctx.Logger.Warn("--only pattern matches no differing artifact", logging.F("pattern", p))
+	}
+	for _, k := range d.BranchOnly {
+		ctx.Logger.Info("artifact is not on the DEV tenant; kept", logging.F("kind", k.Kind), logging.F("id", k.ID))
+	}
+
+	res := SyncRunResult{Command: "pull", Tenant: "dev", Branch: branch, Deferred: len(d.Unselected)}
+	take := d.selected()
+	if len(take) == 0 {
+		res.Message = fmt.Sprintf("%s is up to date with CPI DEV for the selected artifacts; nothing to commit.", branch)
+		return ctx.Render(res)
+	}
+
+	names := make([]string, 0, len(take))
+	for _, k := range take {
+		dir := artifactRepoDir(meta, k)
+		if err := os.RemoveAll(filepath.Join(repoRoot, dir)); err != nil {
+			return err
+		}
+		if err := copyDir(filepath.Join(tmp, dir), filepath.Join(repoRoot, dir)); err != nil {
+			return err
+		}
+		if err := runGit(ctx, repoRoot, "add", "-A", "--", dir); err != nil {
+			return err
+		}
+		names = append(names, k.Kind+"/"+k.ID)
+	}
+	body := "Artifacts from CPI DEV:\n  " + strings.Join(names, "\n  ")
+	if err := runGit(ctx, repoRoot, "commit", "-m", workPullCommitSubject(branch, message), "-m", body); err != nil {
+		return err
+	}
+	if err := runGit(ctx, repoRoot, "push", "-u", "origin", branch); err != nil {
+		return err
+	}
+	sha, _ := runGitOutput(ctx, repoRoot, "rev-parse", "HEAD")
+
+	ctx.Logger.Info("sync pull completed", logging.F("branch", branch), logging.F("tenant", "dev"), logging.F("added", len(d.Added)), logging.F("updated", len(d.Updated)), logging.F("skipped", len(d.Unselected)))
+	res.Changed = true
+	res.Updated = len(take)
+	res.Message = fmt.Sprintf("Sync pull completed. %d artifact(s) from CPI DEV committed to %s (%s) and pushed to origin/%s: %s", len(take), branch, shortSHA(sha), branch, strings.Join(names, ", "))
+	if len(d.Unselected) > 0 {
+		res.Message += fmt.Sprintf(". %d other differing artifact(s) were not selected by --only", len(d.Unselected))
+	}
+	return ctx.Render(res)
+}
+
+func workPullCommitSubject(branch, message string) string {
+	subject := "sync pull DEV into " + branch
+	if message = strings.TrimSpace(message); message != "" {
+		subject += " " + message
+	}
+	return subject
+}
+
+// copyDir copies the regular files of src into dst, creating folders as needed.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if e.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if !e.Type().IsRegular() {
+			return nil
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, b, 0o644)
+	})
+}
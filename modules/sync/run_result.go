@@ -0,0 +1,49 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+)
+
+// SyncRunResult is the result of the commands that change a branch or a tenant
+// (push, pull, deliver, rollback, deploy run, transport retry/abandon).
+type SyncRunResult struct {
+	Command     string             `json:"command"`
+	Tenant      string             `json:"tenant"`
+	Branch      string             `json:"branch"`
+	TransportID string             `json:"transportId,omitempty"`
+	Changed     bool               `json:"changed"`
+	Deleted     int                `json:"deleted"`
+	Updated     int                `json:"updated"`
+	Deployed    int                `json:"deployed"`
+	Deferred    int                `json:"deferred"`
+	Deployments []DeployStatusItem `json:"deployments,omitempty"`
+	// Steps are the transport steps this run finished (see TransportStep).
+	Steps   []TransportStep `json:"steps,omitempty"`
+	Message string          `json:"message"`
+	// LocalChanges is set by pull when local edits were stashed and merged back.
+	LocalChanges *LocalReapply `json:"localChanges,omitempty"`
+}
+
+func (r SyncRunResult) ResultKind() string { return "SyncRun" }
+
+func (r SyncRunResult) WriteTable(w io.Writer) error {
+	fmt.Fprintln(w, r.Message)
+	if r.LocalChanges != nil {
+		for _, l := range r.LocalChanges.summary() {
+			fmt.Fprintln(w, l)
+		}
+	}
+	for _, st := range r.Steps {
+		if st.Status == stepSkipped {
+			fmt.Fprintf(w, "Skipped %s %s/%s: %s\n", st.Phase, st.Kind, st.ID, st.Reason)
+		}
+	}
+	if len(r.Deployments) > 0 {
+		fmt.Fprintf(w, "%-14s %-48s %-14s %s\n", "KIND", "NAME", "STATUS", "DEPLOYED_AT")
+		for _, d := range r.Deployments {
+			fmt.Fprintf(w, "%-14s %-48s %-14s %s\n", d.Kind, d.ID, d.Status, d.DeployedAt)
+		}
+	}
+	return nil
+}
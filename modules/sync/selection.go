@@ -0,0 +1,121 @@
+package sync
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// stringListFlag collects a repeatable flag; each value may also be comma separated.
+type stringListFlag []string
+
+func (f *stringListFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringListFlag) Set(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			*f = append(*f, part)
+		}
+	}
+	return nil
+}
+
+// artifactSelector filters artifacts with Kind/ID glob patterns (path.Match syntax),
+// e.g. "iFlows/Order*" or "*/Common_Script".
+type artifactSelector struct {
+	only    []string
+	exclude []string
+}
+
+func newArtifactSelector(only, exclude []string) (*artifactSelector, error) {
+	for _, list := range []struct {
+		flag     string
+		patterns []string
+	}{{"--only", only}, {"--exclude", exclude}} {
+		for _, p := range list.patterns {
+			kind, id, ok := strings.Cut(p, "/")
+			if !ok || kind == "" || id == "" || strings.Contains(id, "/") {
+				return nil, fmt.Errorf("invalid %s %q: expected Kind/ID (glob allowed), e.g. iFlows/Order*", list.flag, p)
+			}
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", list.flag, p, err)
+			}
+		}
+	}
+	return &artifactSelector{only: only, exclude: exclude}, nil
+}
+
+// active reports whether any --only/--exclude pattern was given.
+func (s *artifactSelector) active() bool {
+	return s != nil && (len(s.only) > 0 || len(s.exclude) > 0)
+}
+
+func (s *artifactSelector) match(k artifactKey) bool {
+	if !s.active() {
+		return true
+	}
+	name := k.Kind + "/" + k.ID
+	if len(s.only) > 0 && !matchAnyPattern(s.only, name) {
+		return false
+	}
+	return !matchAnyPattern(s.exclude, name)
+}
+
+// partition splits keys into selected and deferred (not selected) lists, preserving order.
+func (s *artifactSelector) partition(keys []artifactKey) (selected, deferred []artifactKey) {
+	for _, k := range keys {
+		if s.match(k) {
+			selected = append(selected, k)
+		} else {
+			deferred = append(deferred, k)
+		}
+	}
+	return selected, deferred
+}
+
+// unmatchedOnly returns --only patterns that match none of keys.
+func (s *artifactSelector) unmatchedOnly(keys []artifactKey) []string {
+	if s == nil {
+		return nil
+	}
+	var out []string
+	for _, p := range s.only {
+		hit := false
+		for _, k := range keys {
+			if ok, _ := path.Match(p, k.Kind+"/"+k.ID); ok {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func matchAnyPattern(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyArtifactSelection returns previously deferred artifacts to the work queue and then
+// defers everything sel does not select. Without a selection everything is processed.
+func applyArtifactSelection(rec *TransportRecord, sel *artifactSelector) {
+	upload := mergeUpload(append(append([]artifactKey{}, rec.UploadRemaining...), rec.DeferredUpload...), nil)
+	del := mergeUpload(append(append([]artifactKey{}, rec.DeleteRemaining...), rec.DeferredDelete...), nil)
+	rec.UploadRemaining, rec.DeferredUpload = sel.partition(upload)
+	rec.DeleteRemaining, rec.DeferredDelete = sel.partition(del)
+}
+
+func keySet(list []artifactKey) map[artifactKey]struct{} {
+	m := make(map[artifactKey]struct{}, len(list))
+	for _, k := range list {
+		m[k] = struct{}{}
+	}
+	return m
+}
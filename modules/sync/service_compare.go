@@ -10,13 +10,22 @@ import (
 
 	"github.com/iflowkit/iflowkit-cli/internal/app"
 	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/validate"
 )
 
 func runSyncCompare(ctx *app.Context, args []string) error {
 	fs := flag.NewFlagSet("iflowkit sync compare", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
-	var to string
+	var to, tenantA, tenantB string
+	var showDiff, showStat bool
+	var kinds, ids stringListFlag
 	fs.StringVar(&to, "to", "", "Target environment branch (qas|prd)")
+	fs.StringVar(&tenantA, "tenant", "", "Compare this tenant (dev|qas|prd) with --against-tenant instead of branches")
+	fs.StringVar(&tenantB, "against-tenant", "", "Second tenant for --tenant")
+	fs.BoolVar(&showDiff, "diff", false, "Show per-file unified diffs (XML/JSON pretty-printed)")
+	fs.BoolVar(&showStat, "stat", false, "Show per-file added/removed line counts")
+	fs.Var(&kinds, "kind", "Only show these artifact kinds (repeatable or comma-separated)")
+	fs.Var(&ids, "id", "Only show artifacts whose id matches this glob (repeatable or comma-separated)")
 	if err := fs.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return nil
@@ -25,9 +34,23 @@ func runSyncCompare(ctx *app.Context, args []string) error {
 		return err
 	}
 	to = strings.ToLower(strings.TrimSpace(to))
-	if to != "qas" && to != "prd" {
+	tenantA = strings.ToLower(strings.TrimSpace(tenantA))
+	tenantB = strings.ToLower(strings.TrimSpace(tenantB))
+	tenantMode := tenantA != "" || tenantB != ""
+	switch {
+	case tenantMode && to != "":
+		return fmt.Errorf("--to cannot be combined with --tenant/--against-tenant")
+	case tenantMode && (tenantA == "" || tenantB == ""):
+		return fmt.Errorf("--tenant and --against-tenant must be used together")
+	case tenantMode && tenantA == tenantB:
+		return fmt.Errorf("--tenant and --against-tenant must differ")
+	case !tenantMode && to != "qas" && to != "prd":
 		return fmt.Errorf("--to must be qas or prd")
 	}
+	filter, err := newCompareFilter(kinds, ids)
+	if err != nil {
+		return err
+	}
 
 	cwd, _ := os.Getwd()
 	repoRoot, err := findSyncRepoRoot(cwd)
@@ -43,6 +66,21 @@ func runSyncCompare(ctx *app.Context, args []string) error {
 	}
 
 	levels := meta.CPITenantLevels
+	if tenantMode {
+		for _, e := range []string{tenantA, tenantB} {
+			if err := validate.Env(e); err != nil {
+				return err
+			}
+			if e == "qas" && levels != 3 {
+				return fmt.Errorf("qas is not enabled: cpiTenantLevels=%d (expected 3)", levels)
+			}
+		}
+		ign, err := LoadRepoIgnore(repoRoot)
+		if err != nil {
+			return err
+		}
+		return compareTenants(ctx, meta, ign, filter, tenantA, tenantB, showStat, showDiff)
+	}
 	if to == "qas" && levels != 3 {
 		return fmt.Errorf("qas compare is not enabled: cpiTenantLevels=%d (expected 3)", levels)
 	}
@@ -74,18 +112,21 @@ func runSyncCompare(ctx *app.Context, args []string) error {
 		return err
 	}
 	changedPaths := splitLines(out)
-	changedPaths = ign.Filter(changedPaths)
-	keys := detectChangedArtifacts(meta, changedPaths)
-	objs := keysToObjects(keys)
+	changedPaths = filter.filterPaths(meta, ign.Filter(changedPaths))
 
-	if len(objs) == 0 {
-		fmt.Fprintf(ctx.Stdout, "No IntegrationPackage differences between %s and %s (after applying .iflowkit/ignore).\n", branch, rightRef)
-		return nil
+	res := CompareResult{Mode: compareModeBranch, Left: rightRef, Right: branch, Paths: changedPaths, Artifacts: []CompareArtifact{}, meta: meta, showStat: showStat, showDiff: showDiff}
+	if res.Paths == nil {
+		res.Paths = []string{}
 	}
-
-	fmt.Fprintf(ctx.Stdout, "IntegrationPackage differences (after applying .iflowkit/ignore): %s vs %s\n", branch, rightRef)
-	for _, o := range objs {
-		fmt.Fprintf(ctx.Stdout, "%s - %s\n", o.Kind, o.ID)
+	for _, o := range keysToObjects(detectChangedArtifacts(meta, changedPaths)) {
+		res.Artifacts = append(res.Artifacts, CompareArtifact{Kind: o.Kind, ID: o.ID})
+	}
+	if len(changedPaths) > 0 && (showDiff || showStat) {
+		// a/ is the target branch, b/ the current branch (the change a deliver would bring).
+		res.Files, err = diffFiles(ctx, changedPaths, gitRefSource(repoRoot, rightRef), gitRefSource(repoRoot, "HEAD"), showDiff)
+		if err != nil {
+			return err
+		}
 	}
-	return nil
+	return ctx.Render(res)
 }
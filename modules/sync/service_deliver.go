@@ -11,7 +11,7 @@ import (
 	"time"
 
 	"github.com/iflowkit/iflowkit-cli/internal/app"
-	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
+	"github.com/iflowkit/iflowkit-cli/internal/git"
 	"github.com/iflowkit/iflowkit-cli/internal/logging"
 	"github.com/iflowkit/iflowkit-cli/internal/models"
 )
@@ -23,6 +23,23 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 	var message string
 	fs.StringVar(&to, "to", "", "Target environment (qas|prd)")
 	fs.StringVar(&message, "message", "", "Optional message appended to generated commit messages")
+	var dryRun, yes bool
+	fs.BoolVar(&dryRun, "dry-run", false, "Print the plan without merging or changing CPI")
+	var only stringListFlag
+	fs.Var(&only, "only", "Promote only matching artifacts (Kind/ID, glob, repeatable) as one commit instead of merging")
+	fs.BoolVar(&yes, "yes", false, "Confirm a PRD run without prompting (required when stdin is not a terminal)")
+	var strategy string
+	var resume, abort bool
+	fs.StringVar(&strategy, "strategy", "", "On merge conflicts: source (take source), target (keep target) or stop (leave a merge branch); asks on a terminal")
+	fs.BoolVar(&resume, "continue", false, "Finish a deliver that stopped on merge conflicts after resolving them")
+	fs.BoolVar(&abort, "abort", false, "Discard a deliver that stopped on merge conflicts")
+	var viaPR, complete bool
+	var prNumber int
+	fs.BoolVar(&viaPR, "via-pr", false, "Open a pull/merge request from the source to the target branch instead of merging; CPI is not changed")
+	fs.BoolVar(&complete, "complete", false, "Merge an approved pull request (if still open) and update the tenant")
+	fs.IntVar(&prNumber, "pr", 0, "Pull/merge request number for --complete (default: the one opened by --via-pr in this clone)")
+	lockWait := lockWaitFlag(fs)
+	noNotify := noNotifyFlag(fs)
 	if err := fs.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return nil
@@ -35,7 +52,30 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 	if to != "qas" && to != "prd" {
 		return fmt.Errorf("--to must be qas or prd")
 	}
-	// Safety: PRD requires explicit confirmation. For deliver, --to prd is the confirmation.
+	sel, err := newArtifactSelector(only, nil)
+	if err != nil {
+		return err
+	}
+	if strategy, err = normalizeConflictStrategy(strategy); err != nil {
+		return err
+	}
+	switch {
+	case resume && abort:
+		return fmt.Errorf("--continue and --abort cannot be combined")
+	case (resume || abort) && (dryRun || sel.active()):
+		return fmt.Errorf("--continue/--abort cannot be combined with --dry-run or --only")
+	case viaPR && complete:
+		return fmt.Errorf("--via-pr and --complete cannot be combined")
+	case (viaPR || complete) && (resume || abort || sel.active() || strategy != ""):
+		return fmt.Errorf("--via-pr/--complete cannot be combined with --continue, --abort, --only or --strategy")
+	case viaPR && dryRun:
+		return fmt.Errorf("--via-pr cannot be combined with --dry-run; use --dry-run alone to see the plan")
+	case prNumber != 0 && !complete:
+		return fmt.Errorf("--pr requires --complete")
+	case prNumber < 0:
+		return fmt.Errorf("--pr must be a positive number")
+	}
+	// Safety: PRD requires --to prd plus a typed confirmation (or --yes when not on a terminal).
 	if to == "prd" {
 		if err := validateToFlag("prd", "prd"); err != nil {
 			return err
@@ -71,9 +111,110 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 		sourceBranch = "qas"
 	}
 
+	stopped, err := readStoppedDeliver(ctx, repoRoot, to)
+	if err != nil {
+		return err
+	}
+	switch {
+	case abort && stopped == nil, resume && stopped == nil:
+		return fmt.Errorf("no deliver to %s is waiting on merge conflicts", to)
+	case abort:
+		if err := abortStoppedDeliver(ctx, repoRoot, *stopped); err != nil {
+			return err
+		}
+		ctx.Logger.Info("stopped deliver aborted", logging.F("to", to), logging.F("branch", stopped.MergeBranch))
+		return ctx.Render(SyncRunResult{Command: "deliver", Tenant: to, Branch: targetBranch, Message: fmt.Sprintf("Deliver to %s aborted; removed branch %s. Nothing was changed in CPI.", tenantDisplay(to), stopped.MergeBranch)})
+	case !resume && stopped != nil:
+		return fmt.Errorf("a deliver to %s stopped on merge conflicts (branch %s); resolve and run with --continue, or run with --abort", to, stopped.MergeBranch)
+	case resume:
+		if err := checkStoppedDeliverCommitted(ctx, repoRoot, *stopped); err != nil {
+			return err
+		}
+	}
+	if viaPR {
+		return openDeliverPR(ctx, repoRoot, meta, to, sourceBranch, targetBranch, message, *lockWait)
+	}
+
+	// A deliver reviewed in a pull request only reaches the tenant through --complete.
+	var review *deliverPR
+	if complete {
+		if review, err = loadDeliverPR(ctx, repoRoot, meta, to, targetBranch, prNumber); err != nil {
+			return err
+		}
+		if review.pr.State == git.PullRequestClosed {
+			if err := removeOpenedDeliverPR(ctx, repoRoot, to); err != nil {
+				return err
+			}
+		}
+		if !dryRun {
+			if err := review.gate(); err != nil {
+				return err
+			}
+		}
+	} else if opened, err := readOpenedDeliverPR(ctx, repoRoot, to); err != nil {
+		return err
+	} else if opened != nil {
+		return fmt.Errorf("pull request #%d for deliver to %s is open (%s); finish it with `iflowkit sync deliver --to %s --complete`", opened.Number, to, opened.URL, to)
+	}
+	planDeliver := func() (*SyncPlan, error) {
+		if review != nil {
+			return planDeliverPR(ctx, repoRoot, meta, to, targetBranch, message, review)
+		}
+		return planSyncDeliver(ctx, repoRoot, meta, to, sourceBranch, targetBranch, message, sel)
+	}
+
+	if dryRun {
+		plan, err := planDeliver()
+		if err != nil {
+			return err
+		}
+		return finishPlan(ctx, plan)
+	}
+	hooks, err := loadRepoHooks(repoRoot)
+	if err != nil {
+		return err
+	}
+	var plan *SyncPlan
+	var confirmation *TransportConfirmation
+	if to == "prd" {
+		if plan, err = planDeliver(); err != nil {
+			return err
+		}
+		if confirmation, err = confirmPRD(ctx, repoRoot, plan, yes); err != nil {
+			return err
+		}
+	}
+
+	releaseLock, err := acquireSyncLock(ctx, repoRoot, "deliver", to, *lockWait)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	// Transport id for a new record. The pre-deliver hook already ran when a stopped deliver started.
+	newID, newCreatedAt := newTransportIDs(time.Now())
+	if !resume && hooks.has(hookPreDeliver, to) {
+		if plan == nil {
+			if plan, err = planDeliver(); err != nil {
+				return err
+			}
+		}
+		id := newID
+		if plan.ResumeTransport != "" {
+			id = plan.ResumeTransport
+		}
+		planned := plannedTransportRecord(ctx, repoRoot, plan, "deliver", id, newCreatedAt)
+		if err := hooks.run(ctx, meta, hookRun{Event: hookPreDeliver, Env: to, Branch: targetBranch, Record: planned}); err != nil {
+			return fmt.Errorf("%w; nothing was delivered", err)
+		}
+	}
+
 	ctx.Logger.Info("sync deliver started", logging.F("repo", repoRoot), logging.F("to", to), logging.F("from", sourceBranch), logging.F("packageId", meta.PackageID))
 
 	originalBranch, _ := gitCurrentBranch(ctx, repoRoot)
+	if stopped != nil && originalBranch == stopped.MergeBranch {
+		originalBranch = targetBranch // the merge branch is deleted by --continue
+	}
 	defer func() {
 		if originalBranch == "" {
 			return
@@ -91,19 +232,8 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 		return err
 	}
 
-	// Ensure env branches exist on origin when required.
-	if levels == 3 {
-		// qas branch should exist when we either deliver to qas or use it as a source for prd.
-		if to == "qas" || sourceBranch == "qas" {
-			if err := ensureEnvBranchOnRemote(ctx, repoRoot, meta, "qas"); err != nil {
-				return err
-			}
-		}
-	}
-	if to == "prd" {
-		if err := ensureEnvBranchOnRemote(ctx, repoRoot, meta, "prd"); err != nil {
-			return err
-		}
+	if err := ensureDeliverBranches(ctx, repoRoot, meta, to, sourceBranch); err != nil {
+		return err
 	}
 
 	// Always refresh source branch.
@@ -119,6 +249,13 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 	transportTouched := false
 	transportID := ""
 	deliverSucceeded := false
+	var rec TransportRecord
+	// Post-hooks run last, after the record and tag were pushed.
+	defer func() {
+		if transportTouched {
+			hooks.runPostHooks(ctx, meta, hookPostDeliver, to, targetBranch, rec, retErr)
+		}
+	}()
 	defer func() {
 		if !transportTouched {
 			return
@@ -154,9 +291,14 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 	if err != nil {
 		return err
 	}
-	var rec TransportRecord
+	if hasPending && resume {
+		return fmt.Errorf("deliver transport %s is still pending; run `iflowkit sync transport retry %s` before --continue", pendingRec.TransportID, pendingRec.TransportID)
+	}
 	if hasPending {
 		rec = *pendingRec
+		if confirmation != nil {
+			rec.Confirmation = confirmation
+		}
 		transportTouched = true
 		transportID = rec.TransportID
 		ctx.Logger.Info("resuming pending deliver transport", logging.F("transportId", rec.TransportID), logging.F("record", filepath.ToSlash(strings.TrimPrefix(pendingPath, repoRoot+string(os.PathSeparator)))))
@@ -166,19 +308,29 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 		}
 	} else {
 		// Preflight: tenant must match target branch (ignoring .iflowkit/ignore patterns).
+		// For a pull request the check runs against the target before the merge (completeDeliverPR).
 		if err := ensureBranchFetchedAndCheckedOut(ctx, repoRoot, targetBranch); err != nil {
 			return err
 		}
-		eq, diffPaths, err := compareTenantWithCurrentBranch(ctx, repoRoot, meta, to, ign)
-		if err != nil {
-			return err
-		}
-		if !eq {
-			return fmt.Errorf("%s tenant and %s branch differ (after applying .iflowkit/ignore). first diffs: %s", tenantDisplay(to), targetBranch, strings.Join(samplePaths(diffPaths, 10), ", "))
+		if review != nil {
+			if err := review.checkNotDelivered(ctx, repoRoot, store, to); err != nil {
+				return err
+			}
+		} else {
+			eq, diffPaths, err := compareTenantWithCurrentBranch(ctx, repoRoot, meta, to, ign)
+			if err != nil {
+				return err
+			}
+			if !eq {
+				return fmt.Errorf("%s tenant and %s branch differ (after applying .iflowkit/ignore). first diffs: %s", tenantDisplay(to), targetBranch, strings.Join(samplePaths(diffPaths, 10), ", "))
+			}
 		}
 
 		// Create a new transport id so merge commit uses the strict format.
-		id, createdAt := newTransportIDs(time.Now())
+		id, createdAt := newID, newCreatedAt
+		if resume {
+			id, createdAt = stopped.TransportID, stopped.CreatedAt
+		}
 		transportID = id
 
 		// Merge source -> target.
@@ -191,16 +343,69 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 		preMerge, _ := runGitOutput(ctx, repoRoot, "rev-parse", "HEAD")
 		preMerge = strings.TrimSpace(preMerge)
 		mergeMsg := buildTransportCommitMessage(transportID, "deliver", "contents", message)
-		ctx.Logger.Info("merging branches", logging.F("from", sourceBranch), logging.F("to", targetBranch), logging.F("transportId", transportID))
-		if err := runGit(ctx, repoRoot, "merge", "--no-ff", "-m", mergeMsg, sourceBranch); err != nil {
-			// Best-effort cleanup so the user doesn't remain stuck in a conflicted state.
-			_ = runGit(ctx, repoRoot, "merge", "--abort")
-			return err
+		var sources []ArtifactSource
+		var conflict *DeliverConflict
+		if resume {
+			preMerge = stopped.PreMerge
+			ctx.Logger.Info("continuing stopped deliver", logging.F("branch", stopped.MergeBranch), logging.F("transportId", transportID))
+			if err := continueStoppedDeliver(ctx, repoRoot, *stopped); err != nil {
+				return err
+			}
+			conflict = stopped.conflict(ctx, repoRoot)
+		} else if review != nil {
+			if preMerge, err = completeDeliverPR(ctx, repoRoot, meta, ign, to, targetBranch, mergeMsg, review); err != nil {
+				return err
+			}
+		} else if sel.active() {
+			// Selective deliver: copy only the selected artifact folders from the source branch as one commit.
+			ds, err := selectDeliverArtifacts(ctx, repoRoot, meta, ign, sel, sourceBranch, targetBranch)
+			if err != nil {
+				return err
+			}
+			for _, p := range ds.Unmatched {
+				ctx.Logger.Warn("--only pattern matches no differing artifact", logging.F("pattern", p))
+			}
+			for _, k := range ds.TargetOnly {
+				ctx.Logger.Warn("artifact differs only by changes on the target; not delivered", logging.F("artifact", k.Kind+"/"+k.ID), logging.F("target", targetBranch))
+			}
+			for _, w := range deliverDependencyWarnings(ctx, repoRoot, meta, sourceBranch, ds) {
+				ctx.Logger.Warn("selected artifact depends on unselected changes", logging.F("detail", w))
+			}
+			ctx.Logger.Info("delivering selected artifacts", logging.F("from", sourceBranch), logging.F("to", targetBranch), logging.F("selected", len(ds.Selected)), logging.F("left", len(ds.Unselected)), logging.F("transportId", transportID))
+			if err := checkoutSelectedArtifacts(ctx, repoRoot, meta, sourceBranch, ds.Selected); err != nil {
+				_ = runGit(ctx, repoRoot, "reset", "-q", "--hard", "HEAD")
+				return err
+			}
+			if err := runGit(ctx, repoRoot, "commit", "-m", mergeMsg); err != nil {
+				_ = runGit(ctx, repoRoot, "reset", "-q", "--hard", "HEAD")
+				return err
+			}
+			sources = artifactSourceCommits(ctx, repoRoot, meta, sourceBranch, ds.Selected)
+		} else {
+			ctx.Logger.Info("merging branches", logging.F("from", sourceBranch), logging.F("to", targetBranch), logging.F("transportId", transportID))
+			if mergeErr := runGit(ctx, repoRoot, "merge", "--no-ff", "-m", mergeMsg, sourceBranch); mergeErr != nil {
+				sourceCommit, _ := runGitOutput(ctx, repoRoot, "rev-parse", sourceBranch)
+				st := stoppedDeliver{
+					To: to, SourceBranch: sourceBranch, SourceCommit: strings.TrimSpace(sourceCommit), TargetBranch: targetBranch,
+					PreMerge: preMerge, TransportID: transportID, CreatedAt: createdAt, Message: message,
+				}
+				if conflict, err = resolveDeliverConflicts(ctx, repoRoot, meta, strategy, st, mergeErr); err != nil {
+					if errors.Is(err, errDeliverStopped) {
+						originalBranch = "" // leave the user on the prepared merge branch
+					}
+					return err
+				}
+			}
 		}
 
 		// Compute changed artifact set for CPI based on IntegrationPackage diffs.
+		// A pull request covers its merge commit only, not what was merged onto the target after it.
+		postMerge := "HEAD"
+		if review != nil {
+			postMerge = review.pr.MergeCommitSHA
+		}
 		baseFolder := resolveContentFolder(meta)
-		diffOut, _ := runGitOutput(ctx, repoRoot, "diff", "--name-only", preMerge+"..HEAD", "--", baseFolder)
+		diffOut, _ := runGitOutput(ctx, repoRoot, "diff", "--name-only", preMerge+".."+postMerge, "--", baseFolder)
 		changedPaths := splitLines(diffOut)
 		changedPaths = ign.Filter(changedPaths)
 		keysChanged := detectChangedArtifacts(meta, changedPaths)
@@ -222,6 +427,13 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 			}
 		}
 
+		if review != nil {
+			// The provider already pushed the merge; the record lists the commits it brought in.
+			if out, err := runGitOutput(ctx, repoRoot, "rev-list", "--reverse", "--topo-order", preMerge+".."+postMerge); err == nil {
+				commitsToPush = splitLines(out)
+			}
+		}
+
 		gitUserName, gitUserEmail, _ := gitUserIdentity(ctx, repoRoot)
 		rec = TransportRecord{
 			SchemaVersion:   1,
@@ -239,6 +451,12 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 			UploadRemaining: mapKeysToSortedSlice(toUpload),
 			DeleteRemaining: mapKeysToSortedSlice(toDelete),
 			DeployRemaining: nil,
+			Confirmation:    confirmation,
+			SourceArtifacts: sources,
+			Conflict:        conflict,
+		}
+		if review != nil {
+			rec.PullRequest = review.record()
 		}
 
 		// Persist plan before CPI work.
@@ -247,6 +465,16 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 			return err
 		}
 		transportTouched = true
+		if resume {
+			if err := removeStoppedDeliver(ctx, repoRoot, to); err != nil {
+				return err
+			}
+		}
+		if review != nil {
+			if err := removeOpenedDeliverPR(ctx, repoRoot, to); err != nil {
+				return err
+			}
+		}
 		ctx.Logger.Info("deliver transport record created", logging.F("path", filepath.ToSlash(strings.TrimPrefix(recPath, repoRoot+string(os.PathSeparator)))), logging.F("upload", len(rec.UploadRemaining)), logging.F("delete", len(rec.DeleteRemaining)))
 
 		// Push target branch after merge.
@@ -259,10 +487,11 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 	}
 
 	// CPI phase.
-	deleted, updated, deployed, err := applyTransportToTenant(ctx, repoRoot, meta, to, &rec, store)
+	run, err := executeTransport(ctx, repoRoot, meta, to, &rec, store, !*noNotify)
 	if err != nil {
 		return err
 	}
+	deleted, updated, deployed := run.Deleted, run.Updated, run.Deployed
 	deliverSucceeded = true
 
 	// Persist the latest record state so the deferred logs commit includes it.
@@ -270,8 +499,26 @@ func runSyncDeliver(ctx *app.Context, args []string) (retErr error) {
 		return err
 	}
 
-	fmt.Fprintf(ctx.Stdout, "Sync deliver completed. Updated CPI %s: deleted %d, updated %d, deployed %d. Target branch: %s. Transport: %s\n", tenantDisplay(to), deleted, updated, deployed, targetBranch, transportID)
 	ctx.Logger.Info("sync deliver completed", logging.F("to", to), logging.F("from", sourceBranch), logging.F("branch", targetBranch), logging.F("transportId", transportID), logging.F("deletedArtifacts", deleted), logging.F("updatedArtifacts", updated), logging.F("deployedArtifacts", deployed))
+	return ctx.Render(SyncRunResult{
+		Command: "deliver", Tenant: to, Branch: targetBranch, TransportID: transportID, Changed: true,
+		Deleted: deleted, Updated: updated, Deployed: deployed, Steps: run.Steps,
+		Message: fmt.Sprintf("Sync deliver completed. Updated CPI %s: deleted %d, updated %d, deployed %d. Target branch: %s. Transport: %s", tenantDisplay(to), deleted, updated, deployed, targetBranch, transportID),
+	})
+}
+
+// ensureDeliverBranches makes sure the env branches a deliver to `to` reads from origin exist,
+// bootstrapping missing ones from their tenant.
+func ensureDeliverBranches(ctx *app.Context, repoRoot string, meta models.SyncMetadata, to, sourceBranch string) error {
+	// qas branch should exist when we either deliver to qas or use it as a source for prd.
+	if meta.CPITenantLevels == 3 && (to == "qas" || sourceBranch == "qas") {
+		if err := ensureEnvBranchOnRemote(ctx, repoRoot, meta, "qas"); err != nil {
+			return err
+		}
+	}
+	if to == "prd" {
+		return ensureEnvBranchOnRemote(ctx, repoRoot, meta, "prd")
+	}
 	return nil
 }
 
@@ -291,40 +538,44 @@ func compareTenantWithCurrentBranch(ctx *app.Context, repoRoot string, meta mode
 	}
 	defer os.RemoveAll(tmp)
 
-	// Resolve tenant and export.
-	profileID, source, err := ctx.Stores.ResolveProfileID(ctx.Flags.ProfileID)
+	tenantBase := filepath.Join(tmp, baseFolder)
+	if err := exportTenantPackage(ctx, meta, tenantEnv, tenantBase); err != nil {
+		return false, nil, err
+	}
+
+	diffs, err := CompareFolderTrees(baseFolder, tenantBase, branchBase, ign)
 	if err != nil {
 		return false, nil, err
 	}
+	return len(diffs) == 0, diffs, nil
+}
+
+// exportTenantPackage exports the package as it exists in the tenant of tenantEnv into destBase.
+func exportTenantPackage(ctx *app.Context, meta models.SyncMetadata, tenantEnv, destBase string) error {
+	profileID, source, err := ctx.Stores.ResolveProfileID(ctx.Flags.ProfileID)
+	if err != nil {
+		return err
+	}
 	_, err = ctx.Stores.Profiles.Read(profileID)
 	if err != nil {
-		return false, nil, err
+		return err
 	}
 	ctx.Logger.Info("resolved profile", logging.F("profile", profileID), logging.F("source", source))
 
 	tenantKey, err := ctx.Stores.Tenants.Read(profileID, tenantEnv)
 	if err != nil {
-		return false, nil, fmt.Errorf("%s tenant not found for profile %q; import it with `iflowkit tenant import --env %s --file <service-key.json>`: %w", tenantDisplay(tenantEnv), profileID, tenantEnv, err)
+		return fmt.Errorf("%s tenant not found for profile %q; import it with `iflowkit tenant import --env %s --file <service-key.json>`: %w", tenantDisplay(tenantEnv), profileID, tenantEnv, err)
 	}
 
-	c := cpix.NewClient(tenantKey, ctx.Logger)
+	c := newTenantClient(ctx, profileID, tenantEnv, tenantKey)
 	_, raw, err := c.ReadIntegrationPackage(meta.PackageID)
 	if err != nil {
-		return false, nil, err
-	}
-	tenantBase := filepath.Join(tmp, baseFolder)
-	if err := os.MkdirAll(tenantBase, 0o755); err != nil {
-		return false, nil, err
-	}
-	if err := c.ExportIntegrationPackageFromRaw(meta.PackageID, raw, tenantBase); err != nil {
-		return false, nil, err
+		return err
 	}
-
-	diffs, err := CompareFolderTrees(baseFolder, tenantBase, branchBase, ign)
-	if err != nil {
-		return false, nil, err
+	if err := os.MkdirAll(destBase, 0o755); err != nil {
+		return err
 	}
-	return len(diffs) == 0, diffs, nil
+	return c.ExportIntegrationPackageFromRaw(meta.PackageID, raw, destBase)
 }
 
 func samplePaths(in []string, max int) []string {
@@ -12,7 +12,6 @@ import (
 	"time"
 
 	"github.com/iflowkit/iflowkit-cli/internal/app"
-	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
 	"github.com/iflowkit/iflowkit-cli/internal/common/filex"
 	"github.com/iflowkit/iflowkit-cli/internal/common/gitx"
 	"github.com/iflowkit/iflowkit-cli/internal/git"
@@ -70,10 +69,16 @@ func runSyncInit(ctx *app.Context, args []string) (err error) {
 		return err
 	}
 	providerName := git.DetectProviderFromRemote(remote)
+	if providerName == git.ProviderUnknown {
+		// Self-hosted Gitea/Forgejo hosts rarely carry the product name; ask the API.
+		if h, herr := git.RemoteHost(remote); herr == nil && git.ProbeGitea(context.Background(), h) {
+			providerName = git.ProviderGitea
+		}
+	}
 	ctx.Logger.Info("git remote resolved", logging.F("remote", remote), logging.F("provider", providerName))
 
 	// Fetch package name (required).
-	c := cpix.NewClient(tenant, ctx.Logger)
+	c := newTenantClient(ctx, profileID, "dev", tenant)
 	pkg, raw, err := c.ReadIntegrationPackage(packageID)
 	if err != nil {
 		return err
@@ -113,7 +118,7 @@ func runSyncInit(ctx *app.Context, args []string) (err error) {
 
 	ctx.Logger.Info("sync init started", logging.F("packageId", packageID), logging.F("packageName", pkg.Name))
 
-	// Create remote repo (GitHub/GitLab). Unknown providers: best-effort push only.
+	// Create remote repo (GitHub/GitLab/Gitea). Unknown providers: best-effort push only.
 	ns, repoPath, err := git.SplitRemoteNamespaceAndRepo(remote)
 	if err != nil {
 		return err
@@ -188,7 +193,23 @@ func runSyncInit(ctx *app.Context, args []string) (err error) {
 	}
 
 	ctx.Logger.Info("sync init completed", logging.F("dir", absDir), logging.F("remote", remote), logging.F("branch", "dev"))
-	fmt.Fprintf(ctx.Stdout, "Initialized sync repo for %s (%s)\nRemote: %s\nBranch: dev\nDirectory: %s\n", packageID, pkg.Name, remote, absDir)
+	return ctx.Render(SyncInitResult{PackageID: packageID, PackageName: pkg.Name, Remote: remote, Branch: "dev", Directory: absDir, TransportID: transportID})
+}
+
+// SyncInitResult is the result of `sync init`.
+type SyncInitResult struct {
+	PackageID   string `json:"packageId"`
+	PackageName string `json:"packageName"`
+	Remote      string `json:"remote"`
+	Branch      string `json:"branch"`
+	Directory   string `json:"directory"`
+	TransportID string `json:"transportId"`
+}
+
+func (r SyncInitResult) ResultKind() string { return "SyncInit" }
+
+func (r SyncInitResult) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "Initialized sync repo for %s (%s)\nRemote: %s\nBranch: %s\nDirectory: %s\n", r.PackageID, r.PackageName, r.Remote, r.Branch, r.Directory)
 	return nil
 }
 
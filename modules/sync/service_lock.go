@@ -0,0 +1,203 @@
+package sync
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/validate"
+)
+
+func runSyncLock(ctx *app.Context, args []string) error {
+	if len(args) == 0 {
+		return runSyncLockStatus(ctx, nil)
+	}
+	switch args[0] {
+	case "status":
+		return runSyncLockStatus(ctx, args[1:])
+	case "release":
+		return runSyncLockRelease(ctx, args[1:])
+	default:
+		syncLockHelp(ctx)
+		return fmt.Errorf("unknown sync lock command: %s", args[0])
+	}
+}
+
+// LockStatusResult lists the local lock of this clone and the tenant leases on origin.
+type LockStatusResult struct {
+	Local   *LocalLockStatus `json:"local,omitempty"`
+	Tenants []TenantLockItem `json:"tenants"`
+}
+
+type LocalLockStatus struct {
+	SyncLock
+	Path  string `json:"path"`
+	State string `json:"state"` // held|stale|unreadable
+}
+
+type TenantLockItem struct {
+	Env   string       `json:"env"`
+	State string       `json:"state"` // free|held|expired|unreadable
+	Lease *TenantLease `json:"lease,omitempty"`
+}
+
+func (r LockStatusResult) ResultKind() string { return "SyncLockStatus" }
+
+func (r LockStatusResult) WriteTable(w io.Writer) error {
+	if r.Local == nil {
+		fmt.Fprintln(w, "Local lock: free")
+	} else {
+		fmt.Fprintf(w, "Local lock: %s (sync %s, pid %d on %s since %s)\n", r.Local.State, r.Local.Command, r.Local.PID, r.Local.Host, r.Local.AcquiredAt)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "%-5s %-10s %-18s %-32s %-16s %-20s %s\n", "ENV", "STATE", "COMMAND", "OWNER", "HOST", "ACQUIRED_AT", "EXPIRES_AT")
+	for _, t := range r.Tenants {
+		if t.Lease == nil {
+			fmt.Fprintf(w, "%-5s %-10s %-18s %-32s %-16s %-20s %s\n", t.Env, t.State, "-", "-", "-", "-", "-")
+			continue
+		}
+		l := t.Lease
+		fmt.Fprintf(w, "%-5s %-10s %-18s %-32s %-16s %-20s %s\n", t.Env, t.State, l.Command, orDash(l.Owner), orDash(l.Host), l.AcquiredAt, l.ExpiresAt)
+	}
+	return nil
+}
+
+func runSyncLockStatus(ctx *app.Context, args []string) error {
+	fs := flag.NewFlagSet("iflowkit sync lock status", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		syncLockHelp(ctx)
+		return err
+	}
+	cwd, _ := os.Getwd()
+	repoRoot, err := findSyncRepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	res := LockStatusResult{Tenants: []TenantLockItem{}}
+	path, err := syncLockPath(ctx, repoRoot)
+	if err != nil {
+		return err
+	}
+	if held, err := readSyncLock(path); err == nil {
+		host, _ := os.Hostname()
+		state := "held"
+		if held.Host == host && !processAlive(held.PID) {
+			state = "stale"
+		}
+		res.Local = &LocalLockStatus{SyncLock: held, Path: path, State: state}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		res.Local = &LocalLockStatus{Path: path, State: "unreadable"}
+	}
+
+	leases, err := listRemoteLeases(ctx, repoRoot)
+	if err != nil {
+		return fmt.Errorf("cannot list tenant locks on origin: %w", err)
+	}
+	now := time.Now().UTC()
+	for _, env := range transportEnvs {
+		item := TenantLockItem{Env: env, State: "free"}
+		if _, ok := leases[env]; ok {
+			_, lease, err := readRemoteLease(ctx, repoRoot, env)
+			if err != nil {
+				return err
+			}
+			item.Lease = lease
+			switch {
+			case lease == nil:
+				item.State = "unreadable"
+			case lease.expired(now):
+				item.State = "expired"
+			default:
+				item.State = "held"
+			}
+		}
+		res.Tenants = append(res.Tenants, item)
+	}
+	return ctx.Render(res)
+}
+
+// runSyncLockRelease removes a lock left behind by a crashed or interrupted run.
+// --force is required because releasing a lock that is still in use lets two runs interleave.
+func runSyncLockRelease(ctx *app.Context, args []string) error {
+	fs := flag.NewFlagSet("iflowkit sync lock release", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var env string
+	var local, force bool
+	fs.StringVar(&env, "env", "", "Release the team-wide lock of this tenant (dev|qas|prd)")
+	fs.BoolVar(&local, "local", false, "Release the lock of this clone")
+	fs.BoolVar(&force, "force", false, "Confirm the release (required)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		syncLockHelp(ctx)
+		return err
+	}
+	env = strings.ToLower(strings.TrimSpace(env))
+	if env == "" && !local {
+		syncLockHelp(ctx)
+		return fmt.Errorf("--env or --local is required")
+	}
+	if env != "" {
+		if err := validate.Env(env); err != nil {
+			return err
+		}
+	}
+	if !force {
+		return fmt.Errorf("refusing to release a lock without --force; check `iflowkit sync lock status` first, a run that still holds it would interleave with the next one")
+	}
+	cwd, _ := os.Getwd()
+	repoRoot, err := findSyncRepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	var released []string
+	if local {
+		path, err := syncLockPath(ctx, repoRoot)
+		if err != nil {
+			return err
+		}
+		if held, err := readSyncLock(path); err == nil {
+			ctx.Logger.Warn("releasing local sync lock", logging.F("command", held.Command), logging.F("pid", held.PID), logging.F("host", held.Host))
+		}
+		switch err := os.Remove(path); {
+		case err == nil:
+			released = append(released, "local lock")
+		case !errors.Is(err, os.ErrNotExist):
+			return err
+		}
+	}
+	if env != "" {
+		sha, lease, err := readRemoteLease(ctx, repoRoot, env)
+		if err != nil {
+			return fmt.Errorf("cannot read the %s tenant lock from origin: %w", tenantDisplay(env), err)
+		}
+		if sha != "" {
+			if lease != nil {
+				ctx.Logger.Warn("releasing tenant lock", logging.F("env", env), logging.F("holder", lease.Owner), logging.F("command", lease.Command), logging.F("expiresAt", lease.ExpiresAt))
+			}
+			if err := deleteRemoteLease(ctx, repoRoot, env, sha); err != nil {
+				return fmt.Errorf("cannot release the %s tenant lock: %w", tenantDisplay(env), err)
+			}
+			released = append(released, tenantDisplay(env)+" tenant lock")
+		}
+	}
+
+	msg := "No lock was held."
+	if len(released) > 0 {
+		msg = "Released " + strings.Join(released, " and ") + "."
+	}
+	return ctx.Render(SyncRunResult{Command: "lock release", Tenant: env, Changed: len(released) > 0, Message: msg})
+}
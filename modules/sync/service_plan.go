@@ -0,0 +1,345 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// planSyncPush computes what `sync push` would do on the current branch.
+// It may fetch from origin but never pushes, commits or writes to CPI.
+func planSyncPush(ctx *app.Context, repoRoot string, meta models.SyncMetadata, branch, tenant string, isEnvBranch bool, message string, sel *artifactSelector) (*SyncPlan, error) {
+	p := newSyncPlan("push", meta.PackageID, branch, tenant)
+	p.check("branch", planCheckOK, fmt.Sprintf("%s -> %s tenant", branch, tenantDisplay(tenant)))
+	contentPath := resolveContentFolder(meta)
+
+	store, err := NewTransportStore(repoRoot, tenant)
+	if err != nil {
+		return nil, err
+	}
+	pendingRec, _, hasPending, err := store.LoadLatestPendingTransport(meta.PackageID, branch, "push")
+	if err != nil {
+		return nil, err
+	}
+	transportLabel := "<new>"
+	var uploads, deletes []artifactKey
+	var pendingDeploy []deployTarget
+	if hasPending {
+		p.ResumeTransport = pendingRec.TransportID
+		transportLabel = pendingRec.TransportID
+		uploads = append(uploads, pendingRec.UploadRemaining...)
+		uploads = append(uploads, pendingRec.DeferredUpload...)
+		deletes = append(deletes, pendingRec.DeleteRemaining...)
+		deletes = append(deletes, pendingRec.DeferredDelete...)
+		pendingDeploy = append(pendingDeploy, pendingRec.DeployRemaining...)
+	}
+
+	_ = runGit(ctx, repoRoot, "fetch", "origin") // best-effort, read-only on the remote
+	upstreamRef, _ := gitUpstreamRef(ctx, repoRoot)
+	baseRef := upstreamRef
+	if baseRef == "" && gitRemoteBranchExists(ctx, repoRoot, branch) {
+		baseRef = "origin/" + branch
+	}
+	if baseRef != "" {
+		behind, _ := gitAheadBehind(ctx, repoRoot, baseRef, "HEAD")
+		if behind > 0 {
+			p.check("upstream", planCheckFail, fmt.Sprintf("branch is behind %s by %d commit(s); git push would be rejected", baseRef, behind))
+		} else {
+			p.check("upstream", planCheckOK, baseRef)
+		}
+	} else {
+		p.check("upstream", planCheckWarn, "no upstream; branch would be pushed with -u origin "+branch)
+	}
+
+	changedPaths, commits, err := gitPendingChanges(ctx, repoRoot, baseRef, branch)
+	if err != nil {
+		return nil, err
+	}
+	p.Commits = append(p.Commits, planCommitSummaries(ctx, repoRoot, commits)...)
+
+	contentDirty, err := gitHasChangesInPath(ctx, repoRoot, contentPath)
+	if err != nil {
+		return nil, err
+	}
+	if contentDirty {
+		prefix := filepath.ToSlash(contentPath) + "/"
+		n := 0
+		for _, path := range gitPorcelainPaths(ctx, repoRoot) {
+			if strings.HasPrefix(filepath.ToSlash(path), prefix) {
+				changedPaths = append(changedPaths, path)
+				n++
+			}
+		}
+		p.Commits = append(p.Commits, PlanCommit{Subject: buildTransportCommitMessage(transportLabel, "push", "contents", message), Planned: true})
+		p.note("%d uncommitted path(s) under %s would be committed", n, contentPath)
+	}
+
+	ign, err := LoadRepoIgnore(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	keys := detectChangedArtifacts(meta, ign.Filter(changedPaths))
+	keysToUpload, keysToDelete := partitionChangedKeys(repoRoot, meta, keys)
+	uploads = mergeUpload(uploads, keysToUpload)
+	deletes = mergeUpload(deletes, keysToDelete)
+	if sel.active() {
+		var deferUp, deferDel []artifactKey
+		uploads, deferUp = sel.partition(uploads)
+		deletes, deferDel = sel.partition(deletes)
+		for _, k := range sortArtifactKeys(append(deferUp, deferDel...)) {
+			p.Deferred = append(p.Deferred, PlanArtifact{Kind: k.Kind, ID: k.ID, Action: "defer", Reason: "not selected by --only/--exclude"})
+		}
+		if len(uploads) == 0 && len(deletes) == 0 && len(p.Deferred) > 0 {
+			p.check("selection", planCheckFail, "--only/--exclude selected none of the changed artifacts")
+		}
+	}
+
+	if len(uploads) > 0 || len(deletes) > 0 || len(pendingDeploy) > 0 {
+		client := planTenantClient(ctx, p, tenant)
+		planCPIActions(ctx, p, client, meta, uploads, deletes, pendingDeploy, func(k artifactKey) bool {
+			st, err := os.Stat(filepath.Join(repoRoot, meta.BaseFolder, k.Kind, k.ID))
+			return err == nil && st.IsDir()
+		})
+		p.Commits = append(p.Commits, PlanCommit{Subject: buildTransportCommitMessage(transportLabel, "push", "logs", message), Planned: true})
+		if isEnvBranch {
+			p.note("tag %s would be created on success", transportTagName(transportLabel, branch))
+		}
+	} else {
+		p.check("tenant", planCheckSkipped, "no CPI artifact changes")
+	}
+	return p, nil
+}
+
+// planSyncPull computes what `sync pull` would change in the repository.
+// The tenant is exported into a temporary folder; the working tree is not modified.
+func planSyncPull(ctx *app.Context, repoRoot string, meta models.SyncMetadata, branch, tenant string, message string) (*SyncPlan, error) {
+	p := newSyncPlan("pull", meta.PackageID, branch, tenant)
+	p.check("branch", planCheckOK, fmt.Sprintf("%s <- %s tenant", branch, tenantDisplay(tenant)))
+
+	_ = runGit(ctx, repoRoot, "fetch", "origin", branch) // best-effort, read-only on the remote
+	remoteRef := "origin/" + branch
+	if gitRemoteBranchExists(ctx, repoRoot, branch) {
+		behind, ahead := gitAheadBehind(ctx, repoRoot, remoteRef, "HEAD")
+		switch {
+		case behind > 0 && ahead > 0:
+			p.check("upstream", planCheckFail, fmt.Sprintf("local branch diverged from %s (ahead=%d, behind=%d)", remoteRef, ahead, behind))
+		case behind > 0:
+			p.check("upstream", planCheckOK, fmt.Sprintf("would fast-forward %d commit(s) from %s", behind, remoteRef))
+		default:
+			p.check("upstream", planCheckOK, remoteRef)
+		}
+	}
+
+	if local := filterNonTransportChanges(gitPorcelainPaths(ctx, repoRoot)); len(local) > 0 {
+		p.check("working tree", planCheckWarn, fmt.Sprintf("%d local path(s) would be stashed and merged back after the pull", len(local)))
+	} else {
+		p.check("working tree", planCheckOK, "clean")
+	}
+
+	client := planTenantClient(ctx, p, tenant)
+	if client == nil {
+		return p, nil
+	}
+
+	tmp, err := os.MkdirTemp("", "iflowkit-plan-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	baseFolder := resolveContentFolder(meta)
+	tenantBase := filepath.Join(tmp, baseFolder)
+	if err := os.MkdirAll(tenantBase, 0o755); err != nil {
+		return nil, err
+	}
+	_, raw, err := client.ReadIntegrationPackage(meta.PackageID)
+	if err != nil {
+		p.check("tenant export", planCheckFail, err.Error())
+		return p, nil
+	}
+	if err := client.ExportIntegrationPackageFromRaw(meta.PackageID, raw, tenantBase); err != nil {
+		p.check("tenant export", planCheckFail, err.Error())
+		return p, nil
+	}
+	p.check("tenant export", planCheckOK, "")
+
+	ign, err := LoadRepoIgnore(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := CompareFolderTrees(baseFolder, tenantBase, filepath.Join(repoRoot, baseFolder), ign)
+	if err != nil {
+		return nil, err
+	}
+	before, _ := listLocalArtifactKeys(repoRoot, meta)
+	after, _ := listLocalArtifactKeys(tmp, meta)
+	deleted := setDiff(before, after)
+	added := setDiff(after, before)
+	changed := setDiff(setDiff(detectChangedArtifacts(meta, diffs), deleted), added)
+
+	for _, k := range mapKeysToSortedSlice(added) {
+		p.RepoChanges = append(p.RepoChanges, PlanArtifact{Kind: k.Kind, ID: k.ID, Action: "add"})
+	}
+	for _, k := range mapKeysToSortedSlice(changed) {
+		p.RepoChanges = append(p.RepoChanges, PlanArtifact{Kind: k.Kind, ID: k.ID, Action: "update"})
+	}
+	for _, k := range mapKeysToSortedSlice(deleted) {
+		p.RepoChanges = append(p.RepoChanges, PlanArtifact{Kind: k.Kind, ID: k.ID, Action: "delete"})
+	}
+	if len(diffs) > 0 {
+		p.Commits = append(p.Commits,
+			PlanCommit{Subject: buildTransportCommitMessage("<new>", "pull", "contents", message), Planned: true},
+			PlanCommit{Subject: buildTransportCommitMessage("<new>", "pull", "logs", message), Planned: true},
+		)
+		p.note("%d file(s) under %s differ from the tenant", len(diffs), baseFolder)
+	}
+	return p, nil
+}
+
+// planSyncDeliver computes what `sync deliver` would merge and apply to the target tenant.
+// The merge is simulated with `git merge-tree` and the tenant preflight runs against a temporary worktree.
+func planSyncDeliver(ctx *app.Context, repoRoot string, meta models.SyncMetadata, to, sourceBranch, targetBranch, message string, sel *artifactSelector) (*SyncPlan, error) {
+	p := newSyncPlan("deliver", meta.PackageID, targetBranch, to)
+	p.SourceBranch = sourceBranch
+
+	if dirty := gitPorcelainPaths(ctx, repoRoot); len(dirty) > 0 {
+		p.check("working tree", planCheckFail, fmt.Sprintf("not clean (%d paths)", len(dirty)))
+	} else {
+		p.check("working tree", planCheckOK, "clean")
+	}
+
+	_ = runGit(ctx, repoRoot, "fetch", "origin") // best-effort, read-only on the remote
+	refFor := func(branch string) string {
+		if gitRemoteBranchExists(ctx, repoRoot, branch) {
+			return "origin/" + branch
+		}
+		if gitLocalBranchExists(ctx, repoRoot, branch) {
+			return branch
+		}
+		return ""
+	}
+	sourceRef := refFor(sourceBranch)
+	targetRef := refFor(targetBranch)
+	if sourceRef == "" {
+		p.check("source branch", planCheckFail, sourceBranch+" does not exist")
+		return p, nil
+	}
+	p.check("source branch", planCheckOK, sourceRef)
+	if targetRef == "" {
+		p.check("target branch", planCheckWarn, targetBranch+" does not exist; it would be bootstrapped from the "+tenantDisplay(to)+" tenant first")
+		p.check("tenant vs branch", planCheckSkipped, "target branch does not exist yet")
+		return p, nil
+	}
+	p.check("target branch", planCheckOK, targetRef)
+
+	store, err := NewTransportStore(repoRoot, to)
+	if err != nil {
+		return nil, err
+	}
+	pendingRec, _, hasPending, err := store.LoadLatestPendingTransport(meta.PackageID, targetBranch, "deliver")
+	if err != nil {
+		return nil, err
+	}
+	if hasPending {
+		p.ResumeTransport = pendingRec.TransportID
+		p.note("merge already done by the pending transport; only remaining CPI work would run")
+		client := planTenantClient(ctx, p, to)
+		planCPIActions(ctx, p, client, meta, pendingRec.UploadRemaining, pendingRec.DeleteRemaining, pendingRec.DeployRemaining, func(k artifactKey) bool {
+			return gitRefHasDir(ctx, repoRoot, targetRef, filepath.ToSlash(filepath.Join(meta.BaseFolder, k.Kind, k.ID)))
+		})
+		return p, nil
+	}
+
+	// Tenant-vs-branch preflight on a temporary checkout of the target.
+	ign, err := LoadRepoIgnore(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	planTenantMatchesRef(ctx, p, repoRoot, meta, to, targetRef, ign)
+
+	if sel.active() {
+		planSelectiveDeliver(ctx, p, repoRoot, meta, ign, sel, to, sourceRef, targetRef, targetBranch, message)
+		return p, nil
+	}
+
+	// Merge simulation.
+	if out, err := runGitOutput(ctx, repoRoot, "merge-tree", "--write-tree", "--name-only", targetRef, sourceRef); err != nil {
+		if strings.Contains(out, "CONFLICT") {
+			p.check("merge", planCheckFail, "conflicts: "+strings.Join(samplePaths(conflictPaths(out), 10), ", "))
+		} else {
+			p.check("merge", planCheckWarn, "cannot simulate merge (requires git >= 2.38)")
+		}
+	} else {
+		p.check("merge", planCheckOK, "no conflicts")
+	}
+
+	commitsOut, _ := runGitOutput(ctx, repoRoot, "rev-list", "--reverse", targetRef+".."+sourceRef)
+	p.Commits = append(p.Commits, planCommitSummaries(ctx, repoRoot, splitLines(commitsOut))...)
+	if len(p.Commits) == 0 {
+		p.note("%s has no commits that are not in %s", sourceRef, targetRef)
+		return p, nil
+	}
+	p.Commits = append(p.Commits, PlanCommit{Subject: buildTransportCommitMessage("<new>", "deliver", "contents", message), Planned: true})
+
+	baseFolder := resolveContentFolder(meta)
+	diffOut, _ := runGitOutput(ctx, repoRoot, "diff", "--name-only", targetRef+"..."+sourceRef, "--", baseFolder)
+	keys := detectChangedArtifacts(meta, ign.Filter(splitLines(diffOut)))
+	uploads, deletes := []artifactKey{}, []artifactKey{}
+	for _, k := range mapKeysToSortedSlice(keys) {
+		if gitRefHasDir(ctx, repoRoot, sourceRef, filepath.ToSlash(filepath.Join(baseFolder, k.Kind, k.ID))) {
+			uploads = append(uploads, k)
+		} else if isDeployableKind(k.Kind) {
+			deletes = append(deletes, k)
+		}
+	}
+	if len(uploads) > 0 || len(deletes) > 0 {
+		client := planTenantClient(ctx, p, to)
+		planCPIActions(ctx, p, client, meta, uploads, deletes, nil, func(k artifactKey) bool {
+			return gitRefHasDir(ctx, repoRoot, sourceRef, filepath.ToSlash(filepath.Join(meta.BaseFolder, k.Kind, k.ID)))
+		})
+	}
+	p.Commits = append(p.Commits, PlanCommit{Subject: buildTransportCommitMessage("<new>", "deliver", "logs", message), Planned: true})
+	p.note("tag %s would be created on success", transportTagName("<new>", targetBranch))
+	return p, nil
+}
+
+// planTenantMatchesRef adds the "tenant vs branch" check: the tenant must equal ref (after ign).
+func planTenantMatchesRef(ctx *app.Context, p *SyncPlan, repoRoot string, meta models.SyncMetadata, to, ref string, ign *RepoIgnore) {
+	if err := withRefWorktree(ctx, repoRoot, ref, func(dir string) error {
+		eq, diffPaths, err := compareTenantWithCurrentBranch(ctx, dir, meta, to, ign)
+		if err != nil {
+			p.check("tenant vs branch", planCheckFail, err.Error())
+			return nil
+		}
+		if !eq {
+			p.check("tenant vs branch", planCheckFail, fmt.Sprintf("%s tenant and %s differ: %s", tenantDisplay(to), ref, strings.Join(samplePaths(diffPaths, 10), ", ")))
+			return nil
+		}
+		p.check("tenant vs branch", planCheckOK, "equal")
+		return nil
+	}); err != nil {
+		p.check("tenant vs branch", planCheckFail, err.Error())
+	}
+}
+
+// gitRefHasDir reports whether path is a directory in the tree of ref.
+func gitRefHasDir(ctx *app.Context, dir, ref, path string) bool {
+	out, err := runGitOutput(ctx, dir, "ls-tree", "-d", "--name-only", ref, "--", strings.TrimSuffix(path, "/"))
+	return err == nil && strings.TrimSpace(out) != ""
+}
+
+// conflictPaths extracts file names from `git merge-tree --name-only` output (after the tree id line).
+func conflictPaths(out string) []string {
+	paths := []string{}
+	lines := splitLines(out)
+	for i, l := range lines {
+		if i == 0 || l == "" || strings.Contains(l, " ") {
+			continue
+		}
+		paths = append(paths, l)
+	}
+	return paths
+}
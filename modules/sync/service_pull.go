@@ -11,14 +11,15 @@ import (
 	"time"
 
 	"github.com/iflowkit/iflowkit-cli/internal/app"
-	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
 	"github.com/iflowkit/iflowkit-cli/internal/common/filex"
 	"github.com/iflowkit/iflowkit-cli/internal/logging"
 )
 
 // runSyncPull refreshes the local repo content from CPI (mapped tenant) and pushes the CPI state to origin/<branch>.
 // It must be executed within an existing sync repository (contains .iflowkit/package.json).
-// Only environment branches are allowed (dev, qas, prd).
+// On environment branches (dev, qas, prd) the whole package is refreshed and a transport record is written.
+// On feature/* and bugfix/* branches (or with --into) only the DEV artifacts that differ from the
+// branch are committed; see runWorkBranchPull.
 func runSyncPull(ctx *app.Context, args []string) (retErr error) {
 	fs := flag.NewFlagSet("iflowkit sync pull", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
@@ -26,6 +27,14 @@ func runSyncPull(ctx *app.Context, args []string) (retErr error) {
 	var to string
 	fs.StringVar(&message, "message", "", "Optional message appended to generated commit messages")
 	fs.StringVar(&to, "to", "", "Confirm target tenant (required for prd)")
+	var dryRun, yes bool
+	fs.BoolVar(&dryRun, "dry-run", false, "Print the plan without changing git remotes or CPI")
+	fs.BoolVar(&yes, "yes", false, "Confirm a PRD run without prompting (required when stdin is not a terminal)")
+	var into string
+	var only stringListFlag
+	fs.StringVar(&into, "into", "", "Commit DEV changes into this feature/* or bugfix/* branch instead of the current branch")
+	fs.Var(&only, "only", "Work-branch pull: take only matching artifacts (Kind/ID, glob, repeatable)")
+	lockWait := lockWaitFlag(fs)
 	if err := fs.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return nil
@@ -34,6 +43,11 @@ func runSyncPull(ctx *app.Context, args []string) (retErr error) {
 		return err
 	}
 	message = strings.TrimSpace(message)
+	into = strings.TrimSpace(into)
+	sel, err := newArtifactSelector(only, nil)
+	if err != nil {
+		return err
+	}
 
 	cwd, _ := os.Getwd()
 	repoRoot, err := findSyncRepoRoot(cwd)
@@ -54,6 +68,35 @@ func runSyncPull(ctx *app.Context, args []string) (retErr error) {
 	if err != nil {
 		return err
 	}
+	if into != "" || isWorkBranch(branch) {
+		if into == "" {
+			into = branch
+		}
+		if !isWorkBranch(into) {
+			return fmt.Errorf("--into must be a feature/* or bugfix/* branch (got %q); environment branches are refreshed with a plain sync pull", into)
+		}
+		if err := validateToFlag(to, "dev"); err != nil {
+			return err
+		}
+		if dryRun {
+			ref := into
+			if !gitLocalBranchExists(ctx, repoRoot, into) {
+				ref = "HEAD"
+				if gitRemoteBranchExists(ctx, repoRoot, into) {
+					ref = "origin/" + into
+				}
+			}
+			plan, err := planWorkBranchPull(ctx, repoRoot, meta, sel, into, ref, message)
+			if err != nil {
+				return err
+			}
+			return finishPlan(ctx, plan)
+		}
+		return runWorkBranchPull(ctx, repoRoot, meta, sel, into, message, *lockWait)
+	}
+	if sel.active() {
+		return fmt.Errorf("--only is only supported when pulling into a feature/* or bugfix/* branch")
+	}
 	tenant, isEnvBranch, err := resolveTargetTenant(meta, branch)
 	if err != nil {
 		return err
@@ -69,12 +112,72 @@ func runSyncPull(ctx *app.Context, args []string) (retErr error) {
 	if err := validateToFlag(to, tenant); err != nil {
 		return err
 	}
+	if dryRun {
+		plan, err := planSyncPull(ctx, repoRoot, meta, branch, tenant, message)
+		if err != nil {
+			return err
+		}
+		return finishPlan(ctx, plan)
+	}
+	var confirmation *TransportConfirmation
+	if tenant == "prd" {
+		plan, err := planSyncPull(ctx, repoRoot, meta, branch, tenant, message)
+		if err != nil {
+			return err
+		}
+		if confirmation, err = confirmPRD(ctx, repoRoot, plan, yes); err != nil {
+			return err
+		}
+	}
+
+	hooks, err := loadRepoHooks(repoRoot)
+	if err != nil {
+		return err
+	}
+	releaseLock, err := acquireSyncLock(ctx, repoRoot, "pull", tenant, *lockWait)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
 
 	ctx.Logger.Info("sync pull started", logging.F("repo", repoRoot), logging.F("branch", branch), logging.F("tenant", tenant), logging.F("packageId", meta.PackageID))
 
+	// Local edits are stashed before the export and merged back last, after the logs commit,
+	// so they never leak into the pushed commits. The result is rendered afterwards so it can
+	// report overlapping artifacts.
+	var res *SyncRunResult
+	stash, stashedPaths := "", 0
+	defer func() {
+		var local *LocalReapply
+		if stash != "" {
+			local = reapplyPullStash(ctx, repoRoot, meta, stash, stashedPaths, retErr != nil)
+			if local.Stash != "" {
+				ctx.Logger.Warn("local changes kept in stash", logging.F("stash", local.Stash), logging.F("conflicts", len(local.Conflicts)+len(local.ConflictPaths)))
+			}
+		}
+		if retErr != nil {
+			if local != nil {
+				for _, l := range local.summary() {
+					fmt.Fprintln(ctx.Stdout, l)
+				}
+			}
+			return
+		}
+		if res != nil {
+			res.LocalChanges = local
+			retErr = ctx.Render(*res)
+		}
+	}()
+
 	// Ensure .iflowkit (transport records, package.json, etc.) is pushed as well.
 	transportTouched := false
 	transportID := ""
+	var rec TransportRecord
+	defer func() {
+		if transportTouched {
+			hooks.runPostHooks(ctx, meta, hookPostPull, tenant, branch, rec, retErr)
+		}
+	}()
 	defer func() {
 		if !transportTouched {
 			return
@@ -111,14 +214,15 @@ func runSyncPull(ctx *app.Context, args []string) (retErr error) {
 	changed := gitPorcelainPaths(ctx, repoRoot)
 	changed = filterNonTransportChanges(changed)
 	if len(changed) > 0 {
-		// Safety: stash any local work so we can safely overwrite IntegrationPackage/.
+		// Stash local work so IntegrationPackage/ can be overwritten; it is merged back at the end.
 		_, createdAt := newTransportIDs(time.Now())
 		msg := fmt.Sprintf("iflowkit sync pull %s", createdAt)
 		ctx.Logger.Info("working tree has local changes; stashing", logging.F("paths", len(changed)), logging.F("message", msg))
-		if err := runGit(ctx, repoRoot, "stash", "push", "-u", "-m", msg); err != nil {
+		if stash, err = stashPullChanges(ctx, repoRoot, msg); err != nil {
 			return err
 		}
-		fmt.Fprintf(ctx.Stdout, "Stashed local changes (%d paths). You can restore with: git stash list / git stash pop\n", len(changed))
+		stashedPaths = len(changed)
+		fmt.Fprintf(ctx.Stdout, "Stashed local changes (%d paths); they are merged back after the pull.\n", len(changed))
 	}
 
 	// --- CPI phase (mapped tenant) ---
@@ -137,7 +241,7 @@ func runSyncPull(ctx *app.Context, args []string) (retErr error) {
 		return fmt.Errorf("%s tenant not found for profile %q; import it with `iflowkit tenant import --env %s --file <service-key.json>`: %w", tenantDisplay(tenant), profileID, tenant, err)
 	}
 
-	c := cpix.NewClient(tenantKey, ctx.Logger)
+	c := newTenantClient(ctx, profileID, tenant, tenantKey)
 	_, raw, err := c.ReadIntegrationPackage(meta.PackageID)
 	if err != nil {
 		return err
@@ -177,7 +281,7 @@ func runSyncPull(ctx *app.Context, args []string) (retErr error) {
 	objs := keysToObjects(keys)
 	deletedObjs := keysToObjects(deletedKeys)
 	if len(changedPaths) == 0 {
-		fmt.Fprintf(ctx.Stdout, "Already up to date with CPI %s; no changes to push.\n", tenantDisplay(tenant))
+		res = &SyncRunResult{Command: "pull", Tenant: tenant, Branch: branch, Message: fmt.Sprintf("Already up to date with CPI %s; no changes to push.", tenantDisplay(tenant))}
 		return nil
 	}
 
@@ -209,7 +313,7 @@ func runSyncPull(ctx *app.Context, args []string) (retErr error) {
 	}
 
 	gitUserName, gitUserEmail, _ := gitUserIdentity(ctx, repoRoot)
-	rec := TransportRecord{
+	rec = TransportRecord{
 		SchemaVersion:   1,
 		TransportID:     newID,
 		TransportType:   "pull",
@@ -224,6 +328,7 @@ func runSyncPull(ctx *app.Context, args []string) (retErr error) {
 		TransportStatus: "pending",
 		UploadRemaining: nil,
 		DeployRemaining: nil,
+		Confirmation:    confirmation,
 	}
 
 	store, err := NewTransportStore(repoRoot, tenant)
@@ -248,7 +353,11 @@ func runSyncPull(ctx *app.Context, args []string) (retErr error) {
 	rec.Error = ""
 	_, _ = store.PersistTransportRecord(rec)
 
-	fmt.Fprintf(ctx.Stdout, "Sync pull completed. CPI %s state exported and pushed to origin/%s. Deleted %d artifact(s), changed %d artifact(s). Transport record: %s\n", tenantDisplay(tenant), branch, len(deletedObjs), len(objs), filepath.ToSlash(strings.TrimPrefix(recPath, repoRoot+string(os.PathSeparator))))
 	ctx.Logger.Info("sync pull completed", logging.F("branch", branch), logging.F("tenant", tenant), logging.F("deletedObjects", len(deletedObjs)), logging.F("changedObjects", len(objs)))
+	res = &SyncRunResult{
+		Command: "pull", Tenant: tenant, Branch: branch, TransportID: rec.TransportID, Changed: true,
+		Deleted: len(deletedObjs), Updated: len(objs),
+		Message: fmt.Sprintf("Sync pull completed. CPI %s state exported and pushed to origin/%s. Deleted %d artifact(s), changed %d artifact(s). Transport record: %s", tenantDisplay(tenant), branch, len(deletedObjs), len(objs), filepath.ToSlash(strings.TrimPrefix(recPath, repoRoot+string(os.PathSeparator)))),
+	}
 	return nil
 }
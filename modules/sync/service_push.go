@@ -1,20 +1,16 @@
 package sync
 
 import (
-	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
 	"github.com/iflowkit/iflowkit-cli/internal/app"
-	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
-	"github.com/iflowkit/iflowkit-cli/internal/common/filex"
 	"github.com/iflowkit/iflowkit-cli/internal/logging"
 )
 
@@ -25,6 +21,14 @@ func runSyncPush(ctx *app.Context, args []string) (retErr error) {
 	var to string
 	fs.StringVar(&message, "message", "", "Optional message appended to generated commit messages")
 	fs.StringVar(&to, "to", "", "Confirm target tenant (required for prd)")
+	var dryRun, yes bool
+	fs.BoolVar(&dryRun, "dry-run", false, "Print the plan without changing git remotes or CPI")
+	var only, exclude stringListFlag
+	fs.Var(&only, "only", "Only send matching artifacts to CPI (Kind/ID, glob, repeatable)")
+	fs.Var(&exclude, "exclude", "Do not send matching artifacts to CPI (Kind/ID, glob, repeatable)")
+	fs.BoolVar(&yes, "yes", false, "Confirm a PRD run without prompting (required when stdin is not a terminal)")
+	lockWait := lockWaitFlag(fs)
+	noNotify := noNotifyFlag(fs)
 	if err := fs.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return nil
@@ -33,6 +37,10 @@ func runSyncPush(ctx *app.Context, args []string) (retErr error) {
 		return err
 	}
 	message = strings.TrimSpace(message)
+	sel, err := newArtifactSelector(only, exclude)
+	if err != nil {
+		return err
+	}
 
 	cwd, _ := os.Getwd()
 	repoRoot, err := findSyncRepoRoot(cwd)
@@ -69,15 +77,65 @@ func runSyncPush(ctx *app.Context, args []string) (retErr error) {
 	if err := validateToFlag(to, tenant); err != nil {
 		return err
 	}
+	if dryRun {
+		plan, err := planSyncPush(ctx, repoRoot, meta, branch, tenant, isEnvBranch, message, sel)
+		if err != nil {
+			return err
+		}
+		return finishPlan(ctx, plan)
+	}
+	hooks, err := loadRepoHooks(repoRoot)
+	if err != nil {
+		return err
+	}
+	var plan *SyncPlan
+	var confirmation *TransportConfirmation
+	if tenant == "prd" {
+		if plan, err = planSyncPush(ctx, repoRoot, meta, branch, tenant, isEnvBranch, message, sel); err != nil {
+			return err
+		}
+		if confirmation, err = confirmPRD(ctx, repoRoot, plan, yes); err != nil {
+			return err
+		}
+	}
+
+	releaseLock, err := acquireSyncLock(ctx, repoRoot, "push", tenant, *lockWait)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	// Transport id for a new record; a pending push record keeps its own.
+	transportID, plannedCreatedAt := newTransportIDs(time.Now())
+	if hooks.has(hookPrePush, tenant) {
+		if plan == nil {
+			if plan, err = planSyncPush(ctx, repoRoot, meta, branch, tenant, isEnvBranch, message, sel); err != nil {
+				return err
+			}
+		}
+		id := transportID
+		if plan.ResumeTransport != "" {
+			id = plan.ResumeTransport
+		}
+		planned := plannedTransportRecord(ctx, repoRoot, plan, "push", id, plannedCreatedAt)
+		if err := hooks.run(ctx, meta, hookRun{Event: hookPrePush, Env: tenant, Branch: branch, Record: planned}); err != nil {
+			return fmt.Errorf("%w; nothing was pushed", err)
+		}
+	}
 
 	ctx.Logger.Info("sync push started", logging.F("repo", repoRoot), logging.F("branch", branch), logging.F("tenant", tenant), logging.F("packageId", meta.PackageID))
 
 	// Ensure .iflowkit (transport records, package.json, etc.) is pushed as well.
 	// We commit/push .iflowkit at the end of the command to avoid creating a commit for every progress update.
 	transportTouched := false
-	transportID := ""      // selected transport id for this run
-	plannedCreatedAt := "" // only set when we generate a new transport id before creating the transport record
 	pushSucceeded := false
+	var rec TransportRecord
+	// Post-hooks run last, after the record and tag were pushed.
+	defer func() {
+		if transportTouched {
+			hooks.runPostHooks(ctx, meta, hookPostPush, tenant, branch, rec, retErr)
+		}
+	}()
 	defer func() {
 		if !transportTouched {
 			return
@@ -132,11 +190,6 @@ func runSyncPush(ctx *app.Context, args []string) (retErr error) {
 		return err
 	}
 	if contentDirty {
-		if transportID == "" {
-			id, createdAt := newTransportIDs(time.Now())
-			transportID = id
-			plannedCreatedAt = createdAt
-		}
 		if err := runGit(ctx, repoRoot, "add", "-A", "--", contentPath); err != nil {
 			return err
 		}
@@ -169,8 +222,24 @@ func runSyncPush(ctx *app.Context, args []string) (retErr error) {
 
 	// If there is nothing to push and nothing to delete and no pending retry, exit.
 	if len(keysToUpload) == 0 && len(keysToDelete) == 0 && len(commitsToPush) == 0 && !hasPending {
-		fmt.Fprintln(ctx.Stdout, "No changes detected; nothing to do.")
-		return nil
+		return ctx.Render(SyncRunResult{Command: "push", Tenant: tenant, Branch: branch, Message: "No changes detected; nothing to do."})
+	}
+
+	// Validate --only/--exclude against everything this push could send before touching the remote.
+	if sel.active() {
+		work := mergeUpload(nil, keysToUpload)
+		work = mergeUpload(work, keysToDelete)
+		if hasPending {
+			for _, list := range [][]artifactKey{pendingRec.UploadRemaining, pendingRec.DeleteRemaining, pendingRec.DeferredUpload, pendingRec.DeferredDelete} {
+				work = mergeUpload(work, keySet(list))
+			}
+		}
+		for _, p := range sel.unmatchedOnly(work) {
+			ctx.Logger.Warn("--only pattern matches no changed artifact", logging.F("pattern", p))
+		}
+		if selected, _ := sel.partition(work); len(selected) == 0 {
+			return fmt.Errorf("--only/--exclude selected none of the %d changed artifact(s); nothing would be sent to CPI", len(work))
+		}
 	}
 
 	// Push any pending commits (including those created by sync push).
@@ -188,7 +257,6 @@ func runSyncPush(ctx *app.Context, args []string) (retErr error) {
 	}
 
 	// --- CPI plan (stored as transport record, used for retry) ---
-	var rec TransportRecord
 	recPath := ""
 	if hasPending {
 		rec = *pendingRec
@@ -224,20 +292,15 @@ func runSyncPush(ctx *app.Context, args []string) (retErr error) {
 	} else {
 		if len(keysToUpload) == 0 && len(keysToDelete) == 0 {
 			// Git push completed (or nothing to push). No CPI-relevant changes.
-			fmt.Fprintln(ctx.Stdout, "Git push completed. No CPI artifact changes detected under IntegrationPackage/.")
-			return nil
-		}
-		newID, createdAt := transportID, plannedCreatedAt
-		if newID == "" {
-			newID, createdAt = newTransportIDs(time.Now())
+			return ctx.Render(SyncRunResult{Command: "push", Tenant: tenant, Branch: branch, Changed: len(commitsToPush) > 0, Message: "Git push completed. No CPI artifact changes detected under IntegrationPackage/."})
 		}
 		rec = TransportRecord{
 			SchemaVersion:   1,
-			TransportID:     newID,
+			TransportID:     transportID,
 			TransportType:   "push",
 			PackageID:       meta.PackageID,
 			Branch:          branch,
-			CreatedAt:       createdAt,
+			CreatedAt:       plannedCreatedAt,
 			GitCommits:      commitsToPush,
 			GitUserName:     gitUserName,
 			GitUserEmail:    gitUserEmail,
@@ -251,6 +314,13 @@ func runSyncPush(ctx *app.Context, args []string) (retErr error) {
 		transportTouched = true
 		transportID = rec.TransportID
 	}
+	if confirmation != nil {
+		rec.Confirmation = confirmation
+	}
+	applyArtifactSelection(&rec, sel)
+	if n := len(rec.DeferredUpload) + len(rec.DeferredDelete); n > 0 {
+		ctx.Logger.Info("artifacts deferred by selection", logging.F("deferred", n), logging.F("upload", len(rec.UploadRemaining)), logging.F("delete", len(rec.DeleteRemaining)))
+	}
 	// Persist the (possibly merged) plan before CPI operations.
 	recPath, err = store.PersistTransportRecord(rec)
 	if err != nil {
@@ -258,186 +328,27 @@ func runSyncPush(ctx *app.Context, args []string) (retErr error) {
 	}
 
 	// --- CPI phase (mapped tenant) ---
-	profileID, source, err := ctx.Stores.ResolveProfileID(ctx.Flags.ProfileID)
-	if err != nil {
-		return err
-	}
-	_, err = ctx.Stores.Profiles.Read(profileID)
+	run, err := executeTransport(ctx, repoRoot, meta, tenant, &rec, store, !*noNotify)
 	if err != nil {
 		return err
 	}
-	ctx.Logger.Info("resolved profile", logging.F("profile", profileID), logging.F("source", source))
-
-	tenantKey, err := ctx.Stores.Tenants.Read(profileID, tenant)
-	if err != nil {
-		return fmt.Errorf("%s tenant not found for profile %q; import it with `iflowkit tenant import --env %s --file <service-key.json>`: %w", tenantDisplay(tenant), profileID, tenant, err)
+	deleted, updated, deployed := run.Deleted, run.Updated, run.Deployed
+
+	if deferred := len(rec.DeferredUpload) + len(rec.DeferredDelete); deferred > 0 {
+		// Selected artifacts are done; the record stays pending until the deferred ones are pushed.
+		ctx.Logger.Info("sync push completed with deferred artifacts", logging.F("branch", branch), logging.F("tenant", tenant), logging.F("deferredArtifacts", deferred))
+		return ctx.Render(SyncRunResult{
+			Command: "push", Tenant: tenant, Branch: branch, TransportID: rec.TransportID, Changed: true,
+			Deleted: deleted, Updated: updated, Deployed: deployed, Deferred: deferred, Steps: run.Steps,
+			Message: fmt.Sprintf("Sync push completed for the selected artifacts on branch %s. CPI %s deleted %d artifact(s), updated %d artifact(s) and deployed %d artifact(s). %d artifact(s) remain queued in transport %s; run `iflowkit sync push` to send them.", branch, tenantDisplay(tenant), deleted, updated, deployed, deferred, rec.TransportID),
+		})
 	}
-
-	client := cpix.NewClient(tenantKey, ctx.Logger)
-	csrf, cookies, err := client.FetchCSRFToken(context.Background())
-	if err != nil {
-		return err
-	}
-
-	deleted := 0
-	updated := 0
-	deployed := 0
-
-	// First, delete artifacts that were removed from the repo.
-	orderedDelete := append([]artifactKey{}, rec.DeleteRemaining...)
-	sort.Slice(orderedDelete, func(i, j int) bool {
-		if orderedDelete[i].Kind == orderedDelete[j].Kind {
-			return orderedDelete[i].ID < orderedDelete[j].ID
-		}
-		return orderedDelete[i].Kind < orderedDelete[j].Kind
-	})
-
-	for _, k := range orderedDelete {
-		ctx.Logger.Info("deleting artifact from CPI", logging.F("kind", k.Kind), logging.F("id", k.ID), logging.F("version", "active"))
-		if err := deleteArtifactInCPI(context.Background(), client, k.Kind, k.ID, csrf, cookies); err != nil {
-			rec.TransportStatus = "pending"
-			rec.Error = err.Error()
-			_, _ = store.PersistTransportRecord(rec)
-			return err
-		}
-		deleted++
-		rec.DeleteRemaining = removeUpload(rec.DeleteRemaining, k)
-		_, _ = store.PersistTransportRecord(rec)
-	}
-
-	// Group by kind so we can fetch CPI lists once per kind.
-	byKind := make(map[string][]string)
-	for _, k := range rec.UploadRemaining {
-		byKind[k.Kind] = append(byKind[k.Kind], k.ID)
-	}
-	for kind := range byKind {
-		sort.Strings(byKind[kind])
-	}
-
-	artsByKind := make(map[string]map[string]cpix.ArtifactInfo)
-	for kind := range byKind {
-		endpoint, ok := listEndpointForKind(meta.PackageID, kind)
-		if !ok {
-			ctx.Logger.Warn("unknown artifact kind; skipping", logging.F("kind", kind))
-			continue
-		}
-		m, err := client.ListArtifacts(context.Background(), endpoint)
-		if err != nil {
-			rec.TransportStatus = "pending"
-			rec.Error = err.Error()
-			_, _ = store.PersistTransportRecord(rec)
-			return err
-		}
-		artsByKind[kind] = m
-	}
-
-	// Upload each artifact folder.
-	orderedUpload := make([]artifactKey, 0, len(rec.UploadRemaining))
-	orderedUpload = append(orderedUpload, rec.UploadRemaining...)
-	sort.Slice(orderedUpload, func(i, j int) bool {
-		if orderedUpload[i].Kind == orderedUpload[j].Kind {
-			return orderedUpload[i].ID < orderedUpload[j].ID
-		}
-		return orderedUpload[i].Kind < orderedUpload[j].Kind
-	})
-
-	for _, k := range orderedUpload {
-		arts := artsByKind[k.Kind]
-		art, ok := arts[k.ID]
-		if !ok {
-			ctx.Logger.Warn("artifact not found in CPI list; skipping", logging.F("kind", k.Kind), logging.F("id", k.ID))
-			rec.UploadRemaining = removeUpload(rec.UploadRemaining, k)
-			_, _ = store.PersistTransportRecord(rec)
-			continue
-		}
-
-		artifactDir := filepath.Join(repoRoot, meta.BaseFolder, k.Kind, k.ID)
-		st, err := os.Stat(artifactDir)
-		if err != nil || !st.IsDir() {
-			ctx.Logger.Warn("artifact directory missing; skipping", logging.F("dir", artifactDir), logging.F("kind", k.Kind), logging.F("id", k.ID))
-			rec.UploadRemaining = removeUpload(rec.UploadRemaining, k)
-			_, _ = store.PersistTransportRecord(rec)
-			continue
-		}
-
-		zipBytes, err := filex.ZipDirToBytes(artifactDir)
-		if err != nil {
-			rec.TransportStatus = "pending"
-			rec.Error = err.Error()
-			_, _ = store.PersistTransportRecord(rec)
-			return err
-		}
-
-		ctx.Logger.Info("uploading artifact to CPI", logging.F("kind", k.Kind), logging.F("id", k.ID))
-		entitySet := kindToEntitySet(k.Kind)
-		if entitySet == "" {
-			ctx.Logger.Warn("artifact kind is not supported for CPI updates; skipping", logging.F("kind", k.Kind), logging.F("id", k.ID))
-			rec.UploadRemaining = removeUpload(rec.UploadRemaining, k)
-			_, _ = store.PersistTransportRecord(rec)
-			continue
-		}
-		if err := client.UpdateArtifact(context.Background(), entitySet, art, zipBytes, csrf, cookies); err != nil {
-			rec.TransportStatus = "pending"
-			rec.Error = err.Error()
-			_, _ = store.PersistTransportRecord(rec)
-			return err
-		}
-		updated++
-		rec.UploadRemaining = removeUpload(rec.UploadRemaining, k)
-		// Deploy is triggered per kind via dedicated CPI endpoints.
-		switch k.Kind {
-		case "iFlows", "Scripts", "ValueMappings", "MessageMappings":
-			rec.DeployRemaining = mergeDeployRemaining(rec.DeployRemaining, []deployTarget{{Kind: k.Kind, ID: k.ID}})
-		}
-		_, _ = store.PersistTransportRecord(rec)
-	}
-
-	// Deploy iFlows that were updated (or previously pending).
-	orderedDeploy := make([]deployTarget, 0, len(rec.DeployRemaining))
-	orderedDeploy = append(orderedDeploy, rec.DeployRemaining...)
-	sort.Slice(orderedDeploy, func(i, j int) bool {
-		if orderedDeploy[i].Kind == orderedDeploy[j].Kind {
-			return orderedDeploy[i].ID < orderedDeploy[j].ID
-		}
-		return orderedDeploy[i].Kind < orderedDeploy[j].Kind
-	})
-
-	for _, d := range orderedDeploy {
-		ctx.Logger.Info("deploying artifact", logging.F("kind", d.Kind), logging.F("id", d.ID), logging.F("version", "active"))
-		var derr error
-		switch d.Kind {
-		case "iFlows":
-			derr = client.DeployIntegrationDesigntimeArtifact(context.Background(), d.ID, "active", csrf, cookies)
-		case "Scripts":
-			derr = client.DeployScriptCollectionDesigntimeArtifact(context.Background(), d.ID, "active", csrf, cookies)
-		case "ValueMappings":
-			derr = client.DeployValueMappingDesigntimeArtifact(context.Background(), d.ID, "active", csrf, cookies)
-		case "MessageMappings":
-			derr = client.DeployMessageMappingDesigntimeArtifact(context.Background(), d.ID, "active", csrf, cookies)
-		default:
-			ctx.Logger.Warn("deploy kind not supported; skipping", logging.F("kind", d.Kind), logging.F("id", d.ID))
-			rec.DeployRemaining = removeDeployTarget(rec.DeployRemaining, d)
-			_, _ = store.PersistTransportRecord(rec)
-			continue
-		}
-		if derr != nil {
-			rec.TransportStatus = "pending"
-			rec.Error = derr.Error()
-			_, _ = store.PersistTransportRecord(rec)
-			return derr
-		}
-		deployed++
-		rec.DeployRemaining = removeDeployTarget(rec.DeployRemaining, d)
-		_, _ = store.PersistTransportRecord(rec)
-		ctx.Logger.Info("artifact deployed", logging.F("kind", d.Kind), logging.F("id", d.ID), logging.F("version", "active"))
-	}
-
-	rec.TransportStatus = "completed"
-	rec.Error = ""
-	_, _ = store.PersistTransportRecord(rec)
 	pushSucceeded = true
 
-	fmt.Fprintf(ctx.Stdout, "Sync push completed on branch %s. Git pushed (if needed). CPI %s deleted %d artifact(s), updated %d artifact(s) and deployed %d artifact(s). Transport record: %s\n", branch, tenantDisplay(tenant), deleted, updated, deployed, filepath.ToSlash(strings.TrimPrefix(recPath, repoRoot+string(os.PathSeparator))))
 	ctx.Logger.Info("sync push completed", logging.F("branch", branch), logging.F("tenant", tenant), logging.F("deletedArtifacts", deleted), logging.F("updatedArtifacts", updated), logging.F("deployedArtifacts", deployed))
-	return nil
+	return ctx.Render(SyncRunResult{
+		Command: "push", Tenant: tenant, Branch: branch, TransportID: rec.TransportID, Changed: true,
+		Deleted: deleted, Updated: updated, Deployed: deployed, Steps: run.Steps,
+		Message: fmt.Sprintf("Sync push completed on branch %s. Git pushed (if needed). CPI %s deleted %d artifact(s), updated %d artifact(s) and deployed %d artifact(s). Transport record: %s", branch, tenantDisplay(tenant), deleted, updated, deployed, filepath.ToSlash(strings.TrimPrefix(recPath, repoRoot+string(os.PathSeparator)))),
+	})
 }
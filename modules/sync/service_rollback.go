@@ -0,0 +1,324 @@
+package sync
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+	"github.com/iflowkit/iflowkit-cli/internal/validate"
+)
+
+// runSyncRollback restores an environment branch and its tenant to the state of a previous transport tag.
+//
+// It commits the artifact folders from <transportId>_<env> on top of the branch (a forward "revert" commit),
+// then applies the affected artifacts to the tenant as a transport of type rollback.
+// A pending rollback record is resumed instead of creating a new commit.
+func runSyncRollback(ctx *app.Context, args []string) (retErr error) {
+	fs := flag.NewFlagSet("iflowkit sync rollback", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var env, toID, message string
+	var dryRun, yes bool
+	fs.StringVar(&env, "env", "", "Environment to roll back (dev|qas|prd)")
+	fs.StringVar(&toID, "to", "", "Transport ID whose tag is the rollback target")
+	fs.StringVar(&message, "message", "", "Optional message appended to generated commit messages")
+	fs.BoolVar(&dryRun, "dry-run", false, "Print the plan without committing or changing CPI")
+	fs.BoolVar(&yes, "yes", false, "Confirm a PRD run without prompting (required when stdin is not a terminal)")
+	lockWait := lockWaitFlag(fs)
+	noNotify := noNotifyFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		syncRollbackHelp(ctx)
+		return err
+	}
+	env = strings.ToLower(strings.TrimSpace(env))
+	toID = strings.TrimSpace(toID)
+	message = strings.TrimSpace(message)
+	if env == "" || toID == "" {
+		syncRollbackHelp(ctx)
+		return fmt.Errorf("--env and --to are required")
+	}
+	if err := validate.Env(env); err != nil {
+		return err
+	}
+
+	cwd, _ := os.Getwd()
+	repoRoot, err := findSyncRepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	meta, err := loadPackageMetadata(repoRoot)
+	if err != nil {
+		return err
+	}
+	if err := meta.ValidateRequired(); err != nil {
+		return err
+	}
+	if env == "qas" && meta.CPITenantLevels != 3 {
+		return fmt.Errorf("qas is not enabled: cpiTenantLevels=%d (expected 3)", meta.CPITenantLevels)
+	}
+	branch := env
+
+	_ = runGit(ctx, repoRoot, "fetch", "origin", "--tags") // best-effort
+	tag := transportTagName(toID, branch)
+	tagRef := "refs/tags/" + tag
+	if _, err := runGitOutput(ctx, repoRoot, "rev-parse", "--verify", "--quiet", tagRef+"^{commit}"); err != nil {
+		return fmt.Errorf("tag %s not found; only completed push/deliver transports on %s are tagged (see `git tag -l '*_%s'`)", tag, branch, branch)
+	}
+
+	if dryRun {
+		plan, err := planSyncRollback(ctx, repoRoot, meta, env, toID, tagRef, message)
+		if err != nil {
+			return err
+		}
+		return finishPlan(ctx, plan)
+	}
+	var confirmation *TransportConfirmation
+	if env == "prd" {
+		plan, err := planSyncRollback(ctx, repoRoot, meta, env, toID, tagRef, message)
+		if err != nil {
+			return err
+		}
+		if confirmation, err = confirmPRD(ctx, repoRoot, plan, yes); err != nil {
+			return err
+		}
+	}
+
+	releaseLock, err := acquireSyncLock(ctx, repoRoot, "rollback", env, *lockWait)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	ctx.Logger.Info("sync rollback started", logging.F("repo", repoRoot), logging.F("env", env), logging.F("to", toID), logging.F("packageId", meta.PackageID))
+
+	if dirty := gitPorcelainPaths(ctx, repoRoot); len(dirty) > 0 {
+		return fmt.Errorf("working tree is not clean (%d paths). commit/stash changes before running rollback", len(dirty))
+	}
+	originalBranch, _ := gitCurrentBranch(ctx, repoRoot)
+	defer func() {
+		if originalBranch == "" {
+			return
+		}
+		_ = runGit(ctx, repoRoot, "checkout", originalBranch)
+	}()
+	if err := ensureBranchFetchedAndCheckedOut(ctx, repoRoot, branch); err != nil {
+		return err
+	}
+
+	transportTouched := false
+	transportID := ""
+	rollbackSucceeded := false
+	defer func() {
+		if !transportTouched {
+			return
+		}
+		msg := buildTransportCommitMessage(transportID, "rollback", "logs", message)
+		if err := gitCommitAndPushLogs(ctx, repoRoot, branch, msg); err != nil {
+			if retErr == nil {
+				retErr = err
+				return
+			}
+			ctx.Logger.Warn("failed to push .iflowkit metadata", logging.F("error", err.Error()))
+			return
+		}
+		if retErr != nil || !rollbackSucceeded {
+			return
+		}
+		if err := NewGitTagger(repoRoot).TagBranchWithTransportID(ctx, branch, transportID); err != nil {
+			if retErr == nil {
+				retErr = err
+				return
+			}
+			ctx.Logger.Warn("failed to create/push git tag", logging.F("tag", transportTagName(transportID, branch)), logging.F("error", err.Error()))
+		}
+	}()
+
+	store, err := NewTransportStore(repoRoot, env)
+	if err != nil {
+		return err
+	}
+	pendingRec, pendingPath, hasPending, err := store.LoadLatestPendingTransport(meta.PackageID, branch, "rollback")
+	if err != nil {
+		return err
+	}
+	var rec TransportRecord
+	if hasPending {
+		if pendingRec.RollbackTo != toID {
+			return fmt.Errorf("a pending rollback to %s exists on %s; rerun with --to %s to resume it", pendingRec.RollbackTo, branch, pendingRec.RollbackTo)
+		}
+		rec = *pendingRec
+		if confirmation != nil {
+			rec.Confirmation = confirmation
+		}
+		transportTouched = true
+		transportID = rec.TransportID
+		ctx.Logger.Info("resuming pending rollback transport", logging.F("transportId", rec.TransportID), logging.F("record", filepath.ToSlash(strings.TrimPrefix(pendingPath, repoRoot+string(os.PathSeparator)))))
+	} else {
+		ign, err := LoadRepoIgnore(repoRoot)
+		if err != nil {
+			return err
+		}
+		keys, err := rollbackArtifactKeys(ctx, repoRoot, meta, ign, tagRef)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return ctx.Render(SyncRunResult{Command: "rollback", Tenant: env, Branch: branch, Message: fmt.Sprintf("%s already matches %s; nothing to roll back.", branch, tag)})
+		}
+
+		id, createdAt := newTransportIDs(time.Now())
+		transportID = id
+		ctx.Logger.Info("restoring artifacts from tag", logging.F("tag", tag), logging.F("artifacts", len(keys)), logging.F("transportId", transportID))
+		if err := checkoutSelectedArtifacts(ctx, repoRoot, meta, tagRef, keys); err != nil {
+			_ = runGit(ctx, repoRoot, "reset", "-q", "--hard", "HEAD")
+			return err
+		}
+		if err := runGit(ctx, repoRoot, "commit", "-m", buildTransportCommitMessage(transportID, "rollback", "contents", message)); err != nil {
+			_ = runGit(ctx, repoRoot, "reset", "-q", "--hard", "HEAD")
+			return err
+		}
+		head, _ := runGitOutput(ctx, repoRoot, "rev-parse", "HEAD")
+
+		toUpload, toDelete := partitionChangedKeys(repoRoot, meta, keySet(keys))
+		gitUserName, gitUserEmail, _ := gitUserIdentity(ctx, repoRoot)
+		rec = TransportRecord{
+			SchemaVersion:   1,
+			TransportID:     transportID,
+			TransportType:   "rollback",
+			PackageID:       meta.PackageID,
+			Branch:          branch,
+			CreatedAt:       createdAt,
+			GitCommits:      splitLines(head),
+			GitUserName:     gitUserName,
+			GitUserEmail:    gitUserEmail,
+			Objects:         keysToObjects(toUpload),
+			DeletedObjects:  keysToObjects(toDelete),
+			TransportStatus: "pending",
+			UploadRemaining: mapKeysToSortedSlice(toUpload),
+			DeleteRemaining: mapKeysToSortedSlice(toDelete),
+			DeployRemaining: nil,
+			RollbackTo:      toID,
+			Confirmation:    confirmation,
+		}
+		recPath, err := store.PersistTransportRecord(rec)
+		if err != nil {
+			return err
+		}
+		transportTouched = true
+		ctx.Logger.Info("rollback transport record created", logging.F("path", filepath.ToSlash(strings.TrimPrefix(recPath, repoRoot+string(os.PathSeparator)))), logging.F("upload", len(rec.UploadRemaining)), logging.F("delete", len(rec.DeleteRemaining)))
+
+		if err := runGit(ctx, repoRoot, "push", "origin", branch); err != nil {
+			rec.TransportStatus = "pending"
+			rec.Error = err.Error()
+			_, _ = store.PersistTransportRecord(rec)
+			return err
+		}
+	}
+
+	run, err := executeTransport(ctx, repoRoot, meta, env, &rec, store, !*noNotify)
+	if err != nil {
+		return err
+	}
+	deleted, updated, deployed := run.Deleted, run.Updated, run.Deployed
+	rollbackSucceeded = true
+	if _, err := store.PersistTransportRecord(rec); err != nil {
+		return err
+	}
+
+	ctx.Logger.Info("sync rollback completed", logging.F("env", env), logging.F("to", toID), logging.F("transportId", transportID), logging.F("deletedArtifacts", deleted), logging.F("updatedArtifacts", updated), logging.F("deployedArtifacts", deployed))
+	return ctx.Render(SyncRunResult{
+		Command: "rollback", Tenant: env, Branch: branch, TransportID: transportID, Changed: true,
+		Deleted: deleted, Updated: updated, Deployed: deployed, Steps: run.Steps,
+		Message: fmt.Sprintf("Sync rollback completed. %s restored to %s; CPI %s deleted %d, updated %d, deployed %d. Transport: %s", branch, tag, tenantDisplay(env), deleted, updated, deployed, transportID),
+	})
+}
+
+// rollbackArtifactKeys lists artifacts whose folders differ between tagRef and HEAD (after .iflowkit/ignore).
+func rollbackArtifactKeys(ctx *app.Context, repoRoot string, meta models.SyncMetadata, ign *RepoIgnore, tagRef string) ([]artifactKey, error) {
+	out, err := runGitOutput(ctx, repoRoot, "diff", "--name-only", tagRef, "HEAD", "--", resolveContentFolder(meta))
+	if err != nil {
+		return nil, err
+	}
+	return mapKeysToSortedSlice(detectChangedArtifacts(meta, ign.Filter(splitLines(out)))), nil
+}
+
+// planSyncRollback computes what `sync rollback` would commit and apply, without checking out the branch.
+func planSyncRollback(ctx *app.Context, repoRoot string, meta models.SyncMetadata, env, toID, tagRef, message string) (*SyncPlan, error) {
+	branch := env
+	p := newSyncPlan("rollback", meta.PackageID, branch, env)
+	p.SourceBranch = strings.TrimPrefix(tagRef, "refs/tags/")
+
+	if dirty := gitPorcelainPaths(ctx, repoRoot); len(dirty) > 0 {
+		p.check("working tree", planCheckFail, fmt.Sprintf("not clean (%d paths)", len(dirty)))
+	} else {
+		p.check("working tree", planCheckOK, "clean")
+	}
+	branchRef := "origin/" + branch
+	if !gitRemoteBranchExists(ctx, repoRoot, branch) {
+		p.check("branch", planCheckFail, branchRef+" does not exist")
+		return p, nil
+	}
+	p.check("branch", planCheckOK, branchRef)
+
+	store, err := NewTransportStore(repoRoot, env)
+	if err != nil {
+		return nil, err
+	}
+	pendingRec, _, hasPending, err := store.LoadLatestPendingTransport(meta.PackageID, branch, "rollback")
+	if err != nil {
+		return nil, err
+	}
+	if hasPending {
+		if pendingRec.RollbackTo != toID {
+			p.check("pending rollback", planCheckFail, fmt.Sprintf("a pending rollback to %s exists; use --to %s to resume it", pendingRec.RollbackTo, pendingRec.RollbackTo))
+			return p, nil
+		}
+		p.ResumeTransport = pendingRec.TransportID
+		p.note("revert commit already done by the pending transport; only remaining CPI work would run")
+		client := planTenantClient(ctx, p, env)
+		planCPIActions(ctx, p, client, meta, pendingRec.UploadRemaining, pendingRec.DeleteRemaining, pendingRec.DeployRemaining, func(k artifactKey) bool {
+			return gitRefHasDir(ctx, repoRoot, tagRef, artifactRepoDir(meta, k))
+		})
+		return p, nil
+	}
+
+	ign, err := LoadRepoIgnore(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGitOutput(ctx, repoRoot, "diff", "--name-only", tagRef, branchRef, "--", resolveContentFolder(meta))
+	if err != nil {
+		return nil, err
+	}
+	keys := mapKeysToSortedSlice(detectChangedArtifacts(meta, ign.Filter(splitLines(out))))
+	if len(keys) == 0 {
+		p.note("%s already matches %s", branchRef, p.SourceBranch)
+		return p, nil
+	}
+	p.Commits = append(p.Commits, PlanCommit{Subject: buildTransportCommitMessage("<new>", "rollback", "contents", message), Planned: true})
+	uploads, deletes := []artifactKey{}, []artifactKey{}
+	for _, k := range keys {
+		if gitRefHasDir(ctx, repoRoot, tagRef, artifactRepoDir(meta, k)) {
+			uploads = append(uploads, k)
+		} else if isDeployableKind(k.Kind) {
+			deletes = append(deletes, k)
+		}
+	}
+	client := planTenantClient(ctx, p, env)
+	planCPIActions(ctx, p, client, meta, uploads, deletes, nil, func(k artifactKey) bool {
+		return gitRefHasDir(ctx, repoRoot, tagRef, artifactRepoDir(meta, k))
+	})
+	p.Commits = append(p.Commits, PlanCommit{Subject: buildTransportCommitMessage("<new>", "rollback", "logs", message), Planned: true})
+	p.note("tag %s would be created on success", transportTagName("<new>", branch))
+	return p, nil
+}
@@ -0,0 +1,270 @@
+package sync
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+	"github.com/iflowkit/iflowkit-cli/internal/validate"
+)
+
+// Drift states reported by `sync status`.
+const (
+	driftChangedInTenant = "changed-in-tenant"
+	driftMissingInTenant = "missing-in-tenant"
+	driftMissingInBranch = "missing-in-branch"
+)
+
+// StatusReport is the result of `sync status`.
+type StatusReport struct {
+	PackageID    string      `json:"packageId"`
+	Branch       string      `json:"branch"`
+	DirtyPaths   int         `json:"dirtyPaths"`
+	Environments []EnvStatus `json:"environments"`
+}
+
+// EnvStatus describes one environment: tenant vs origin/<env>, pending transports and local branch state.
+type EnvStatus struct {
+	Env          string            `json:"env"`
+	Branch       string            `json:"branch"`
+	BranchExists bool              `json:"branchExists"`
+	LocalAhead   int               `json:"localAhead"`
+	LocalBehind  int               `json:"localBehind"`
+	Drift        []ArtifactDrift   `json:"drift"`
+	PackageDrift []string          `json:"packageDrift,omitempty"`
+	Pending      []PendingOverview `json:"pending"`
+	Error        string            `json:"error,omitempty"`
+}
+
+type ArtifactDrift struct {
+	Kind  string `json:"kind"`
+	ID    string `json:"id"`
+	State string `json:"state"`
+}
+
+type PendingOverview struct {
+	TransportID string `json:"transportId"`
+	Type        string `json:"transportType"`
+	Branch      string `json:"branch"`
+	CreatedAt   string `json:"createdAt"`
+	Remaining   string `json:"remaining"`
+}
+
+func (e EnvStatus) drifted() bool {
+	return len(e.Drift) > 0 || len(e.PackageDrift) > 0
+}
+
+// runSyncStatus reports drift between each enabled tenant and its environment branch.
+// It is read-only: tenants are exported to temp folders and branches are read from a temporary worktree.
+func runSyncStatus(ctx *app.Context, args []string) error {
+	fs := flag.NewFlagSet("iflowkit sync status", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var env string
+	fs.StringVar(&env, "env", "", "Only check this environment (dev|qas|prd)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		syncStatusHelp(ctx)
+		return err
+	}
+	env = strings.ToLower(strings.TrimSpace(env))
+
+	cwd, _ := os.Getwd()
+	repoRoot, err := findSyncRepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	meta, err := loadPackageMetadata(repoRoot)
+	if err != nil {
+		return err
+	}
+	if err := meta.ValidateRequired(); err != nil {
+		return err
+	}
+	envs := []string{"dev", "prd"}
+	if meta.CPITenantLevels == 3 {
+		envs = []string{"dev", "qas", "prd"}
+	}
+	if env != "" {
+		if err := validate.Env(env); err != nil {
+			return err
+		}
+		if env == "qas" && meta.CPITenantLevels != 3 {
+			return fmt.Errorf("qas is not enabled: cpiTenantLevels=%d (expected 3)", meta.CPITenantLevels)
+		}
+		envs = []string{env}
+	}
+	ign, err := LoadRepoIgnore(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	_ = runGit(ctx, repoRoot, "fetch", "origin") // best-effort
+	branch, _ := gitCurrentBranch(ctx, repoRoot)
+	report := StatusReport{PackageID: meta.PackageID, Branch: branch, DirtyPaths: len(gitPorcelainPaths(ctx, repoRoot))}
+	for _, e := range envs {
+		ctx.Logger.Info("checking environment", logging.F("env", e))
+		report.Environments = append(report.Environments, envStatus(ctx, repoRoot, meta, ign, e))
+	}
+
+	if err := ctx.Render(report); err != nil {
+		return err
+	}
+
+	drifted, failed := 0, 0
+	for _, e := range report.Environments {
+		if e.Error != "" {
+			failed++
+		} else if e.drifted() {
+			drifted++
+		}
+	}
+	switch {
+	case failed > 0:
+		return fmt.Errorf("status check failed for %d environment(s)", failed)
+	case drifted > 0:
+		return fmt.Errorf("drift detected in %d environment(s)", drifted)
+	}
+	return nil
+}
+
+func envStatus(ctx *app.Context, repoRoot string, meta models.SyncMetadata, ign *RepoIgnore, env string) EnvStatus {
+	st := EnvStatus{Env: env, Branch: env, Drift: []ArtifactDrift{}, Pending: []PendingOverview{}}
+	remoteRef := "origin/" + env
+	st.BranchExists = gitRemoteBranchExists(ctx, repoRoot, env)
+	if st.BranchExists && gitLocalBranchExists(ctx, repoRoot, env) {
+		st.LocalBehind, st.LocalAhead = gitAheadBehind(ctx, repoRoot, remoteRef, env)
+	}
+
+	tmp, err := os.MkdirTemp("", "iflowkit-status-*")
+	if err != nil {
+		st.Error = err.Error()
+		return st
+	}
+	defer os.RemoveAll(tmp)
+
+	baseFolder := resolveContentFolder(meta)
+	branchBase := filepath.Join(tmp, "branch", baseFolder)
+	if st.BranchExists {
+		wt := filepath.Join(tmp, "branch")
+		if err := runGit(ctx, repoRoot, "worktree", "add", "--detach", "--quiet", wt, remoteRef); err != nil {
+			st.Error = err.Error()
+			return st
+		}
+		defer func() {
+			_ = runGit(ctx, repoRoot, "worktree", "remove", "--force", wt)
+		}()
+		st.Pending = pendingOverview(repoRoot, wt, env)
+	} else {
+		st.Pending = pendingOverview(repoRoot, "", env)
+	}
+
+	tenantBase := filepath.Join(tmp, "tenant", baseFolder)
+	if err := exportTenantPackage(ctx, meta, env, tenantBase); err != nil {
+		st.Error = err.Error()
+		return st
+	}
+	diffs, err := CompareFolderTrees(baseFolder, tenantBase, branchBase, ign)
+	if err != nil {
+		st.Error = err.Error()
+		return st
+	}
+	keys := detectChangedArtifacts(meta, diffs)
+	for _, k := range mapKeysToSortedSlice(keys) {
+		inTenant := dirExists(filepath.Join(tenantBase, k.Kind, k.ID))
+		inBranch := dirExists(filepath.Join(branchBase, k.Kind, k.ID))
+		state := driftChangedInTenant
+		switch {
+		case inBranch && !inTenant:
+			state = driftMissingInTenant
+		case inTenant && !inBranch:
+			state = driftMissingInBranch
+		}
+		st.Drift = append(st.Drift, ArtifactDrift{Kind: k.Kind, ID: k.ID, State: state})
+	}
+	for _, p := range diffs {
+		if len(detectChangedArtifacts(meta, []string{p})) == 0 {
+			st.PackageDrift = append(st.PackageDrift, p)
+		}
+	}
+	return st
+}
+
+// pendingOverview lists pending records of env found on origin/<env> (worktree) and in the current checkout.
+func pendingOverview(repoRoot, worktree, env string) []PendingOverview {
+	out := []PendingOverview{}
+	seen := map[string]bool{}
+	for _, root := range []string{worktree, repoRoot} {
+		if root == "" {
+			continue
+		}
+		store, err := NewTransportStore(root, env)
+		if err != nil {
+			continue
+		}
+		recs, err := store.ListRecords()
+		if err != nil {
+			continue
+		}
+		for _, r := range recs {
+			if r.TransportStatus != "pending" || seen[r.TransportID] {
+				continue
+			}
+			seen[r.TransportID] = true
+			out = append(out, PendingOverview{TransportID: r.TransportID, Type: r.TransportType, Branch: r.Branch, CreatedAt: r.CreatedAt, Remaining: remainingSummary(r)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt < out[j].CreatedAt })
+	return out
+}
+
+func dirExists(p string) bool {
+	st, err := os.Stat(p)
+	return err == nil && st.IsDir()
+}
+
+func (r StatusReport) ResultKind() string { return "SyncStatus" }
+
+func (r StatusReport) WriteTable(out io.Writer) error {
+	fmt.Fprintf(out, "Package: %s\n", r.PackageID)
+	fmt.Fprintf(out, "Branch:  %s", orDash(r.Branch))
+	if r.DirtyPaths > 0 {
+		fmt.Fprintf(out, " (%d uncommitted paths)", r.DirtyPaths)
+	}
+	fmt.Fprintln(out, "")
+	for _, e := range r.Environments {
+		fmt.Fprintln(out, "")
+		state := "in sync"
+		switch {
+		case e.Error != "":
+			state = "ERROR: " + e.Error
+		case e.drifted():
+			state = fmt.Sprintf("DRIFT (%d artifact(s))", len(e.Drift))
+		}
+		fmt.Fprintf(out, "%s: %s\n", tenantDisplay(e.Env), state)
+		if !e.BranchExists {
+			fmt.Fprintf(out, "  origin/%s does not exist\n", e.Branch)
+		} else if e.LocalAhead > 0 || e.LocalBehind > 0 {
+			fmt.Fprintf(out, "  local %s: %d ahead, %d behind origin/%s\n", e.Branch, e.LocalAhead, e.LocalBehind, e.Branch)
+		}
+		for _, d := range e.Drift {
+			fmt.Fprintf(out, "  %-18s %-16s %s\n", d.State, d.Kind, d.ID)
+		}
+		for _, p := range e.PackageDrift {
+			fmt.Fprintf(out, "  %-18s %s\n", "package-file", p)
+		}
+		for _, p := range e.Pending {
+			fmt.Fprintf(out, "  pending %s %s on %s (remaining %s)\n", p.Type, p.TransportID, p.Branch, p.Remaining)
+		}
+	}
+	return nil
+}
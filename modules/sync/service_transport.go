@@ -0,0 +1,675 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/validate"
+)
+
+// runSyncTransport handles `iflowkit sync transport ...`.
+// Records are read from .iflowkit/transports/<tenant>/ of the current checkout.
+func runSyncTransport(ctx *app.Context, args []string) error {
+	if len(args) == 0 {
+		syncTransportHelp(ctx)
+		return nil
+	}
+	switch args[0] {
+	case "list":
+		return runSyncTransportList(ctx, args[1:])
+	case "show":
+		return runSyncTransportShow(ctx, args[1:])
+	case "retry":
+		return runSyncTransportRetry(ctx, args[1:])
+	case "abandon":
+		return runSyncTransportAbandon(ctx, args[1:])
+	case "reindex":
+		return runSyncTransportReindex(ctx, args[1:])
+	default:
+		syncTransportHelp(ctx)
+		return fmt.Errorf("unknown sync transport command: %s", args[0])
+	}
+}
+
+var transportEnvs = []string{"dev", "qas", "prd"}
+
+// splitIDArg takes a leading positional transport id so flags may follow it (`show <id> --env dev`).
+func splitIDArg(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return strings.TrimSpace(args[0]), args[1:]
+	}
+	return "", args
+}
+
+var errTransportNotFound = errors.New("transport record not found")
+
+// locateTransportRecord finds a record in the current checkout and, failing that, on the
+// environment branches (origin/<env> preferred), so retry/abandon work from any branch.
+func locateTransportRecord(ctx *app.Context, repoRoot, env, id string) (*TransportStore, TransportRecord, error) {
+	store, r, err := findTransportRecord(repoRoot, env, id)
+	if !errors.Is(err, errTransportNotFound) {
+		return store, r, err
+	}
+	envs := transportEnvs
+	if env != "" {
+		envs = []string{env}
+	}
+	_ = runGit(ctx, repoRoot, "fetch", "origin") // best-effort
+	for _, e := range envs {
+		st, err := NewTransportStore(repoRoot, e)
+		if err != nil {
+			return nil, TransportRecord{}, err
+		}
+		rel := filepath.ToSlash(strings.TrimPrefix(st.recordPath(id), repoRoot+string(os.PathSeparator)))
+		for _, branch := range transportEnvs {
+			ref := branch
+			if gitRemoteBranchExists(ctx, repoRoot, branch) {
+				ref = "origin/" + branch
+			}
+			out, err := runGitOutput(ctx, repoRoot, "show", ref+":"+rel)
+			if err != nil {
+				continue
+			}
+			var rec TransportRecord
+			if err := json.Unmarshal([]byte(out), &rec); err != nil {
+				continue
+			}
+			rec.TransportStatus = normalizeTransportStatus(rec.TransportStatus)
+			rec.TransportType = normalizeTransportType(rec.TransportType)
+			return st, rec, nil
+		}
+	}
+	return nil, TransportRecord{}, fmt.Errorf("transport %s not found in the current checkout or on the environment branches", id)
+}
+
+// findTransportRecord locates a record by id in the current checkout, in env or (when env is empty) in every tenant folder.
+func findTransportRecord(repoRoot, env, id string) (*TransportStore, TransportRecord, error) {
+	envs := transportEnvs
+	if env != "" {
+		if err := validate.Env(env); err != nil {
+			return nil, TransportRecord{}, err
+		}
+		envs = []string{env}
+	}
+	var foundStore *TransportStore
+	var found TransportRecord
+	var foundIn []string
+	for _, e := range envs {
+		store, err := NewTransportStore(repoRoot, e)
+		if err != nil {
+			return nil, TransportRecord{}, err
+		}
+		r, err := store.LoadRecord(id)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, TransportRecord{}, err
+		}
+		foundStore, found = store, r
+		foundIn = append(foundIn, e)
+	}
+	switch len(foundIn) {
+	case 0:
+		return nil, TransportRecord{}, errTransportNotFound
+	case 1:
+		return foundStore, found, nil
+	default:
+		return nil, TransportRecord{}, fmt.Errorf("transport %s exists for several tenants (%s); pass --env", id, strings.Join(foundIn, ", "))
+	}
+}
+
+func runSyncTransportList(ctx *app.Context, args []string) error {
+	fs := flag.NewFlagSet("iflowkit sync transport list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var env, typ, status, branch, user, since, until string
+	fs.StringVar(&env, "env", "", "Tenant environment (dev|qas|prd); default: all")
+	fs.StringVar(&typ, "type", "", "Transport type (init|pull|push|deliver|rollback|deploy)")
+	fs.StringVar(&status, "status", "", "Transport status (pending|completed|cancelled)")
+	fs.StringVar(&branch, "branch", "", "Branch name")
+	fs.StringVar(&user, "user", "", "Git user name or email (substring)")
+	fs.StringVar(&since, "since", "", "Created at or after (YYYY-MM-DD or RFC3339)")
+	fs.StringVar(&until, "until", "", "Created before (YYYY-MM-DD or RFC3339)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		syncTransportHelp(ctx)
+		return err
+	}
+	env = strings.ToLower(strings.TrimSpace(env))
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	status = strings.ToLower(strings.TrimSpace(status))
+	user = strings.ToLower(strings.TrimSpace(user))
+	sinceT, err := parseDateFlag("--since", since)
+	if err != nil {
+		return err
+	}
+	untilT, err := parseDateFlag("--until", until)
+	if err != nil {
+		return err
+	}
+
+	cwd, _ := os.Getwd()
+	repoRoot, err := findSyncRepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	envs := transportEnvs
+	if env != "" {
+		if err := validate.Env(env); err != nil {
+			return err
+		}
+		envs = []string{env}
+	}
+
+	res := TransportListResult{Transports: []TransportListItem{}}
+	for _, e := range envs {
+		store, err := NewTransportStore(repoRoot, e)
+		if err != nil {
+			return err
+		}
+		recs, err := store.ListRecords()
+		if err != nil {
+			return err
+		}
+		for _, r := range recs {
+			if typ != "" && r.TransportType != typ {
+				continue
+			}
+			if status != "" && r.TransportStatus != status {
+				continue
+			}
+			if branch != "" && r.Branch != branch {
+				continue
+			}
+			if user != "" && !strings.Contains(strings.ToLower(r.GitUserName), user) && !strings.Contains(strings.ToLower(r.GitUserEmail), user) {
+				continue
+			}
+			created := parseCreatedAtOrZero(r.CreatedAt)
+			if !sinceT.IsZero() && created.Before(sinceT) {
+				continue
+			}
+			if !untilT.IsZero() && !created.Before(untilT) {
+				continue
+			}
+			res.Transports = append(res.Transports, TransportListItem{Env: e, TransportRecord: r})
+		}
+	}
+	return ctx.Render(res)
+}
+
+// TransportListResult is the result of `sync transport list`; each item is the stored record plus its tenant.
+type TransportListResult struct {
+	Transports []TransportListItem `json:"transports"`
+}
+
+type TransportListItem struct {
+	Env string `json:"env"`
+	TransportRecord
+}
+
+func (r TransportListResult) ResultKind() string { return "SyncTransportList" }
+
+func (r TransportListResult) WriteTable(w io.Writer) error {
+	if len(r.Transports) == 0 {
+		fmt.Fprintln(w, "No transport records found.")
+		return nil
+	}
+	fmt.Fprintf(w, "%-4s %-20s %-9s %-10s %-18s %-20s %-21s %s\n", "ENV", "TRANSPORT", "TYPE", "STATUS", "BRANCH", "USER", "CREATED", "REMAINING(U/D/P)")
+	for _, t := range r.Transports {
+		fmt.Fprintf(w, "%-4s %-20s %-9s %-10s %-18s %-20s %-21s %s\n", t.Env, t.TransportID, t.TransportType, t.TransportStatus, t.Branch, orDash(t.GitUserName), t.CreatedAt, remainingSummary(t.TransportRecord))
+	}
+	return nil
+}
+
+func runSyncTransportShow(ctx *app.Context, args []string) error {
+	id, rest := splitIDArg(args)
+	fs := flag.NewFlagSet("iflowkit sync transport show", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var env string
+	fs.StringVar(&env, "env", "", "Tenant environment (dev|qas|prd)")
+	if err := fs.Parse(rest); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		syncTransportHelp(ctx)
+		return err
+	}
+	if id == "" {
+		id = strings.TrimSpace(fs.Arg(0))
+	}
+	if id == "" {
+		return fmt.Errorf("usage: iflowkit sync transport show <transportId> [--env dev|qas|prd]")
+	}
+
+	cwd, _ := os.Getwd()
+	repoRoot, err := findSyncRepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	store, r, err := locateTransportRecord(ctx, repoRoot, strings.ToLower(strings.TrimSpace(env)), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Render(TransportShowResult{Env: store.tenant, TransportRecord: r})
+}
+
+// TransportShowResult is the result of `sync transport show`.
+type TransportShowResult struct {
+	Env string `json:"env"`
+	TransportRecord
+}
+
+func (t TransportShowResult) ResultKind() string { return "SyncTransport" }
+
+func (t TransportShowResult) WriteTable(out io.Writer) error {
+	r := t.TransportRecord
+	fmt.Fprintf(out, "Transport: %s\n", r.TransportID)
+	fmt.Fprintf(out, "  Tenant:  %s\n", tenantDisplay(t.Env))
+	fmt.Fprintf(out, "  Type:    %s\n", r.TransportType)
+	fmt.Fprintf(out, "  Status:  %s\n", r.TransportStatus)
+	fmt.Fprintf(out, "  Package: %s\n", r.PackageID)
+	fmt.Fprintf(out, "  Branch:  %s\n", r.Branch)
+	fmt.Fprintf(out, "  Created: %s\n", r.CreatedAt)
+	if r.GitUserName != "" || r.GitUserEmail != "" {
+		fmt.Fprintf(out, "  User:    %s <%s>\n", r.GitUserName, r.GitUserEmail)
+	}
+	if r.RollbackTo != "" {
+		fmt.Fprintf(out, "  Rollback to: %s\n", r.RollbackTo)
+	}
+	if r.Error != "" {
+		fmt.Fprintf(out, "  Error:   %s\n", r.Error)
+	}
+	if r.TransportStatus == "cancelled" {
+		fmt.Fprintf(out, "  Cancelled: %s by %s (%s)\n", r.CancelledAt, orDash(r.CancelledBy), r.CancelReason)
+	}
+	if c := r.Confirmation; c != nil {
+		fmt.Fprintf(out, "  PRD confirmation: %s by %s via %s, plan %s\n", c.ConfirmedAt, orDash(c.ConfirmedBy), c.Method, c.PlanHash)
+	}
+
+	fmt.Fprintf(out, "\nCommits (%d):\n", len(r.GitCommits))
+	for _, c := range r.GitCommits {
+		fmt.Fprintf(out, "  %s\n", c)
+	}
+	printTransportObjects(out, "Objects", r.Objects)
+	printTransportObjects(out, "Deleted objects", r.DeletedObjects)
+	printTransportKeys(out, "Upload remaining", r.UploadRemaining)
+	printTransportKeys(out, "Delete remaining", r.DeleteRemaining)
+	fmt.Fprintf(out, "\nDeploy remaining (%d):\n", len(r.DeployRemaining))
+	for _, d := range r.DeployRemaining {
+		fmt.Fprintf(out, "  %-16s %s\n", d.Kind, d.ID)
+	}
+	if len(r.DeferredUpload)+len(r.DeferredDelete) > 0 {
+		printTransportKeys(out, "Deferred upload", r.DeferredUpload)
+		printTransportKeys(out, "Deferred delete", r.DeferredDelete)
+	}
+	if len(r.Steps) > 0 {
+		fmt.Fprintf(out, "\nSteps (%d, %d attempt(s)):\n", len(r.Steps), r.Attempts)
+		for _, s := range r.Steps {
+			detail := s.Reason
+			if s.Error != "" {
+				detail = s.Error
+			}
+			fmt.Fprintln(out, strings.TrimRight(fmt.Sprintf("  #%-3d %-7s %-16s %-40s %-10s %s", s.Attempt, s.Phase, s.Kind, s.ID, s.Status, detail), " "))
+		}
+	}
+	if len(r.SourceArtifacts) > 0 {
+		fmt.Fprintf(out, "\nSource artifacts (%d):\n", len(r.SourceArtifacts))
+		for _, s := range r.SourceArtifacts {
+			fmt.Fprintf(out, "  %-16s %-40s %s@%s\n", s.Kind, s.ID, s.Branch, s.Commit)
+		}
+	}
+	return nil
+}
+
+// runSyncTransportRetry resumes the CPI work of one pending push/deliver/rollback/deploy record.
+// The record's branch is checked out so uploads use its content and the updated record is committed there.
+func runSyncTransportRetry(ctx *app.Context, args []string) (retErr error) {
+	id, rest := splitIDArg(args)
+	fs := flag.NewFlagSet("iflowkit sync transport retry", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var env string
+	var yes bool
+	fs.StringVar(&env, "env", "", "Tenant environment (dev|qas|prd)")
+	fs.BoolVar(&yes, "yes", false, "Confirm a PRD run without prompting (required when stdin is not a terminal)")
+	lockWait := lockWaitFlag(fs)
+	noNotify := noNotifyFlag(fs)
+	if err := fs.Parse(rest); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		syncTransportHelp(ctx)
+		return err
+	}
+	if id == "" {
+		id = strings.TrimSpace(fs.Arg(0))
+	}
+	if id == "" {
+		return fmt.Errorf("usage: iflowkit sync transport retry <transportId> [--env dev|qas|prd] [--yes]")
+	}
+
+	cwd, _ := os.Getwd()
+	repoRoot, err := findSyncRepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	meta, err := loadPackageMetadata(repoRoot)
+	if err != nil {
+		return err
+	}
+	store, r, err := locateTransportRecord(ctx, repoRoot, strings.ToLower(strings.TrimSpace(env)), id)
+	if err != nil {
+		return err
+	}
+	if r.TransportStatus != "pending" {
+		return fmt.Errorf("transport %s is %s; only pending records can be retried", id, r.TransportStatus)
+	}
+	switch r.TransportType {
+	case "push", "deliver", "rollback", "deploy":
+	default:
+		return fmt.Errorf("transport %s has type %s; retry supports push, deliver, rollback and deploy records (run `iflowkit sync %s` again instead)", id, r.TransportType, r.TransportType)
+	}
+
+	var confirmation *TransportConfirmation
+	if store.tenant == "prd" {
+		plan := newSyncPlan("retry", meta.PackageID, r.Branch, store.tenant)
+		plan.ResumeTransport = r.TransportID
+		client := planTenantClient(ctx, plan, store.tenant)
+		planCPIActions(ctx, plan, client, meta, append(r.UploadRemaining, r.DeferredUpload...), append(r.DeleteRemaining, r.DeferredDelete...), r.DeployRemaining, func(k artifactKey) bool {
+			return gitRefHasDir(ctx, repoRoot, r.Branch, artifactRepoDir(meta, k))
+		})
+		if confirmation, err = confirmPRD(ctx, repoRoot, plan, yes); err != nil {
+			return err
+		}
+	}
+
+	releaseLock, err := acquireSyncLock(ctx, repoRoot, "transport retry", store.tenant, *lockWait)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	rec, restore, err := checkoutTransportBranch(ctx, repoRoot, store, r)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	succeeded := false
+	defer func() {
+		msg := buildTransportCommitMessage(rec.TransportID, rec.TransportType, "logs", "retry")
+		if err := gitCommitAndPushLogs(ctx, repoRoot, rec.Branch, msg); err != nil {
+			if retErr == nil {
+				retErr = err
+				return
+			}
+			ctx.Logger.Warn("failed to push .iflowkit metadata", logging.F("error", err.Error()))
+			return
+		}
+		if retErr != nil || !succeeded || rec.TransportType == "deploy" || validate.Env(rec.Branch) != nil {
+			return
+		}
+		if err := NewGitTagger(repoRoot).TagBranchWithTransportID(ctx, rec.Branch, rec.TransportID); err != nil {
+			ctx.Logger.Warn("failed to create/push git tag", logging.F("tag", transportTagName(rec.TransportID, rec.Branch)), logging.F("error", err.Error()))
+		}
+	}()
+
+	if confirmation != nil {
+		rec.Confirmation = confirmation
+	}
+	// A retry processes everything, including artifacts deferred by push --only/--exclude.
+	applyArtifactSelection(&rec, nil)
+	ctx.Logger.Info("retrying transport", logging.F("transportId", rec.TransportID), logging.F("type", rec.TransportType), logging.F("tenant", store.tenant), logging.F("branch", rec.Branch))
+	run, err := executeTransport(ctx, repoRoot, meta, store.tenant, &rec, store, !*noNotify)
+	if err != nil {
+		return err
+	}
+	deleted, updated, deployed := run.Deleted, run.Updated, run.Deployed
+	succeeded = true
+	if _, err := store.PersistTransportRecord(rec); err != nil {
+		return err
+	}
+	return ctx.Render(SyncRunResult{
+		Command: "transport retry", Tenant: store.tenant, Branch: rec.Branch, TransportID: rec.TransportID, Changed: true,
+		Deleted: deleted, Updated: updated, Deployed: deployed, Steps: run.Steps,
+		Message: fmt.Sprintf("Transport %s completed. CPI %s deleted %d, updated %d, deployed %d.", rec.TransportID, tenantDisplay(store.tenant), deleted, updated, deployed),
+	})
+}
+
+// runSyncTransportAbandon marks a pending record as cancelled so push/deliver/rollback stop resuming it.
+func runSyncTransportAbandon(ctx *app.Context, args []string) (retErr error) {
+	id, rest := splitIDArg(args)
+	fs := flag.NewFlagSet("iflowkit sync transport abandon", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var env, reason string
+	fs.StringVar(&env, "env", "", "Tenant environment (dev|qas|prd)")
+	fs.StringVar(&reason, "reason", "", "Why the transport is abandoned (required)")
+	lockWait := lockWaitFlag(fs)
+	if err := fs.Parse(rest); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		syncTransportHelp(ctx)
+		return err
+	}
+	if id == "" {
+		id = strings.TrimSpace(fs.Arg(0))
+	}
+	reason = strings.TrimSpace(reason)
+	if id == "" || reason == "" {
+		return fmt.Errorf("usage: iflowkit sync transport abandon <transportId> --reason <text> [--env dev|qas|prd]")
+	}
+
+	cwd, _ := os.Getwd()
+	repoRoot, err := findSyncRepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	store, r, err := locateTransportRecord(ctx, repoRoot, strings.ToLower(strings.TrimSpace(env)), id)
+	if err != nil {
+		return err
+	}
+	if r.TransportStatus != "pending" {
+		return fmt.Errorf("transport %s is %s; only pending records can be abandoned", id, r.TransportStatus)
+	}
+
+	releaseLock, err := acquireSyncLock(ctx, repoRoot, "transport abandon", store.tenant, *lockWait)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	rec, restore, err := checkoutTransportBranch(ctx, repoRoot, store, r)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	name, email, _ := gitUserIdentity(ctx, repoRoot)
+	rec.TransportStatus = "cancelled"
+	rec.CancelledAt = time.Now().UTC().Format(time.RFC3339)
+	rec.CancelledBy = name
+	if rec.CancelledBy == "" {
+		rec.CancelledBy = email
+	}
+	rec.CancelReason = reason
+	if _, err := store.PersistTransportRecord(rec); err != nil {
+		return err
+	}
+	msg := buildTransportCommitMessage(rec.TransportID, rec.TransportType, "logs", "abandoned")
+	if err := gitCommitAndPushLogs(ctx, repoRoot, rec.Branch, msg); err != nil {
+		return err
+	}
+	ctx.Logger.Info("transport abandoned", logging.F("transportId", rec.TransportID), logging.F("tenant", store.tenant), logging.F("reason", reason))
+	return ctx.Render(SyncRunResult{
+		Command: "transport abandon", Tenant: store.tenant, Branch: rec.Branch, TransportID: rec.TransportID, Changed: true,
+		Message: fmt.Sprintf("Transport %s marked as cancelled on %s. Remaining CPI work was not applied.", rec.TransportID, rec.Branch),
+	})
+}
+
+func runSyncTransportReindex(ctx *app.Context, args []string) error {
+	fs := flag.NewFlagSet("iflowkit sync transport reindex", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var env string
+	fs.StringVar(&env, "env", "", "Tenant environment (dev|qas|prd); default: all")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		syncTransportHelp(ctx)
+		return err
+	}
+	cwd, _ := os.Getwd()
+	repoRoot, err := findSyncRepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	envs := transportEnvs
+	if env = strings.ToLower(strings.TrimSpace(env)); env != "" {
+		if err := validate.Env(env); err != nil {
+			return err
+		}
+		envs = []string{env}
+	}
+	res := TransportReindexResult{Indexes: []TransportReindexItem{}}
+	for _, e := range envs {
+		store, err := NewTransportStore(repoRoot, e)
+		if err != nil {
+			return err
+		}
+		stats, err := store.Reindex()
+		if err != nil {
+			return err
+		}
+		for _, f := range stats.Skipped {
+			ctx.Logger.Warn("unreadable transport record left out of the index", logging.F("env", e), logging.F("file", f))
+		}
+		if !stats.Written && len(stats.Skipped) == 0 {
+			continue
+		}
+		it := TransportReindexItem{Env: e, Records: stats.Indexed, Skipped: stats.Skipped}
+		if stats.Written {
+			it.Index = filepath.ToSlash(strings.TrimPrefix(store.indexPath(), repoRoot+string(os.PathSeparator)))
+		}
+		res.Indexes = append(res.Indexes, it)
+	}
+	return ctx.Render(res)
+}
+
+// TransportReindexResult is the result of `sync transport reindex`; envs without records or
+// index file are left out.
+type TransportReindexResult struct {
+	Indexes []TransportReindexItem `json:"indexes"`
+}
+
+type TransportReindexItem struct {
+	Env     string `json:"env"`
+	Records int    `json:"records"`
+	// Index is the rewritten index file; empty when nothing was written.
+	Index string `json:"index,omitempty"`
+	// Skipped lists unreadable record files ("<file>: <error>") left out of the index.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+func (r TransportReindexResult) ResultKind() string { return "SyncTransportReindex" }
+
+func (r TransportReindexResult) WriteTable(w io.Writer) error {
+	written := false
+	for _, it := range r.Indexes {
+		if it.Index != "" {
+			written = true
+			fmt.Fprintf(w, "%s: indexed %d record(s) -> %s\n", tenantDisplay(it.Env), it.Records, it.Index)
+		}
+		for _, f := range it.Skipped {
+			fmt.Fprintf(w, "%s: skipped unreadable record %s\n", tenantDisplay(it.Env), f)
+		}
+	}
+	if !written {
+		fmt.Fprintln(w, "No transport records or index found; nothing to reindex.")
+		return nil
+	}
+	fmt.Fprintln(w, "Index rebuilt in the working tree; commit .iflowkit/ to share it.")
+	return nil
+}
+
+// checkoutTransportBranch checks out the record's branch (clean working tree required) and reloads the
+// record from there. The returned func restores the previously checked out branch.
+func checkoutTransportBranch(ctx *app.Context, repoRoot string, store *TransportStore, r TransportRecord) (TransportRecord, func(), error) {
+	if dirty := gitPorcelainPaths(ctx, repoRoot); len(dirty) > 0 {
+		return r, func() {}, fmt.Errorf("working tree is not clean (%d paths). commit/stash changes first", len(dirty))
+	}
+	original, _ := gitCurrentBranch(ctx, repoRoot)
+	restore := func() {
+		if original != "" && original != r.Branch {
+			_ = runGit(ctx, repoRoot, "checkout", original)
+		}
+	}
+	if err := ensureBranchFetchedAndCheckedOut(ctx, repoRoot, r.Branch); err != nil {
+		restore()
+		return r, func() {}, err
+	}
+	rec, err := store.LoadRecord(r.TransportID)
+	if err != nil {
+		restore()
+		return r, func() {}, fmt.Errorf("transport %s not found on branch %s: %w", r.TransportID, r.Branch, err)
+	}
+	if rec.TransportStatus != "pending" {
+		restore()
+		return rec, func() {}, fmt.Errorf("transport %s is %s on branch %s", rec.TransportID, rec.TransportStatus, r.Branch)
+	}
+	return rec, restore, nil
+}
+
+func parseDateFlag(name, v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s %q (expected YYYY-MM-DD or RFC3339)", name, v)
+}
+
+func remainingSummary(r TransportRecord) string {
+	s := fmt.Sprintf("%d/%d/%d", len(r.UploadRemaining), len(r.DeleteRemaining), len(r.DeployRemaining))
+	if n := len(r.DeferredUpload) + len(r.DeferredDelete); n > 0 {
+		s += fmt.Sprintf(" +%d deferred", n)
+	}
+	return s
+}
+
+func printTransportObjects(out io.Writer, title string, objs []SyncObject) {
+	fmt.Fprintf(out, "\n%s (%d):\n", title, len(objs))
+	for _, o := range objs {
+		fmt.Fprintf(out, "  %-16s %s\n", o.Kind, o.ID)
+	}
+}
+
+func printTransportKeys(out io.Writer, title string, keys []artifactKey) {
+	fmt.Fprintf(out, "\n%s (%d):\n", title, len(keys))
+	for _, k := range keys {
+		fmt.Fprintf(out, "  %-16s %s\n", k.Kind, k.ID)
+	}
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
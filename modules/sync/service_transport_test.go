@@ -0,0 +1,98 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iflowkit/iflowkit-cli/internal/output"
+)
+
+// chdir changes the working directory for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	old, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(old) })
+}
+
+func TestSyncTransportReindex(t *testing.T) {
+	repo := t.TempDir()
+	store, err := NewTransportStore(repo, "qas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"T1", "T2"} {
+		if _, err := store.PersistTransportRecord(TransportRecord{TransportID: id, TransportType: "push", Branch: "qas", CreatedAt: "2024-05-01T10:00:00Z"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(store.recordPath("T3"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, repo)
+	index, err := filepath.Rel(repo, store.indexPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := newTestContext(t)
+	out := &bytes.Buffer{}
+	ctx.Stdout = out
+	reindex := func(format output.Format, args ...string) {
+		t.Helper()
+		out.Reset()
+		ctx.Flags.Output = format
+		if err := runSyncTransportReindex(ctx, args); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reindex(output.JSON)
+	var doc struct {
+		Kind string                 `json:"kind"`
+		Data TransportReindexResult `json:"data"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	items := doc.Data.Indexes
+	if doc.Kind != "SyncTransportReindex" || len(items) != 1 || items[0].Env != "qas" || items[0].Records != 2 || items[0].Index != filepath.ToSlash(index) {
+		t.Fatalf("document = %+v", doc)
+	}
+	if len(items[0].Skipped) != 1 || !strings.HasPrefix(items[0].Skipped[0], "T3"+transportRecordExt+": ") {
+		t.Errorf("skipped = %v, want the corrupt T3 record", items[0].Skipped)
+	}
+
+	// Without records the index is emptied instead of keeping deleted records.
+	for _, id := range []string{"T1", "T2", "T3"} {
+		if err := os.Remove(store.recordPath(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reindex(output.Table, "--env", "qas")
+	if want := "QAS: indexed 0 record(s) -> " + filepath.ToSlash(index) + "\nIndex rebuilt in the working tree; commit .iflowkit/ to share it.\n"; out.String() != want {
+		t.Errorf("table output = %q, want %q", out, want)
+	}
+	b, err := os.ReadFile(store.indexPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var idx TransportIndex
+	if err := json.Unmarshal(b, &idx); err != nil || len(idx.Items) != 0 {
+		t.Errorf("index after reindex = %s (%v)", b, err)
+	}
+
+	reindex(output.Table, "--env", "dev")
+	if out.String() != "No transport records or index found; nothing to reindex.\n" {
+		t.Errorf("table output = %q", out)
+	}
+}
@@ -0,0 +1,217 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+	"github.com/iflowkit/iflowkit-cli/internal/validate"
+)
+
+const minWatchInterval = 10 * time.Second
+
+// watchedKinds are the artifact kinds whose list endpoints are polled by `sync watch`.
+var watchedKinds = []string{"iFlows", "Scripts", "ValueMappings", "MessageMappings"}
+
+// runSyncWatch polls the tenant's artifact versions and modification timestamps and runs
+// `sync pull` (or only prints a notice) when they change. It runs until interrupted.
+func runSyncWatch(ctx *app.Context, args []string) error {
+	fs := flag.NewFlagSet("iflowkit sync watch", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var env, quiet, message string
+	var interval time.Duration
+	var notifyOnly bool
+	fs.StringVar(&env, "env", "dev", "Tenant environment to watch (dev|qas|prd)")
+	fs.DurationVar(&interval, "interval", time.Minute, "Polling interval (minimum 10s)")
+	fs.StringVar(&quiet, "quiet-hours", "", "Local time window without polling, e.g. 20:00-07:00")
+	fs.BoolVar(&notifyOnly, "notify-only", false, "Only report tenant changes; do not pull")
+	fs.StringVar(&message, "message", "watch", "Message appended to the commits of automatic pulls")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		syncWatchHelp(ctx)
+		return err
+	}
+	env = strings.ToLower(strings.TrimSpace(env))
+	if err := validate.Env(env); err != nil {
+		return err
+	}
+	if interval < minWatchInterval {
+		return fmt.Errorf("--interval must be at least %s", minWatchInterval)
+	}
+	qh, err := parseQuietHours(quiet)
+	if err != nil {
+		return err
+	}
+	if env == "prd" && !notifyOnly {
+		return fmt.Errorf("automatic pulls from PRD are not supported: use --notify-only")
+	}
+
+	cwd, _ := os.Getwd()
+	repoRoot, err := findSyncRepoRoot(cwd)
+	if err != nil {
+		return err
+	}
+	meta, err := loadPackageMetadata(repoRoot)
+	if err != nil {
+		return err
+	}
+	if err := meta.ValidateRequired(); err != nil {
+		return err
+	}
+	if env == "qas" && meta.CPITenantLevels != 3 {
+		return fmt.Errorf("qas is not enabled: cpiTenantLevels=%d (expected 3)", meta.CPITenantLevels)
+	}
+	if !notifyOnly {
+		if branch, _ := gitCurrentBranch(ctx, repoRoot); branch != env {
+			return fmt.Errorf("sync watch pulls into the current branch: check out %s first (current=%s) or use --notify-only", env, branch)
+		}
+	}
+
+	client, err := tenantClientForEnv(ctx, env)
+	if err != nil {
+		return err
+	}
+	baseline, err := tenantArtifactSnapshot(client, meta)
+	if err != nil {
+		return err
+	}
+
+	mode := "pull"
+	if notifyOnly {
+		mode = "notify"
+	}
+	fmt.Fprintf(ctx.Stdout, "Watching CPI %s for %s every %s (mode: %s, %d artifacts). Press Ctrl+C to stop.\n", tenantDisplay(env), meta.PackageID, interval, mode, len(baseline))
+	ctx.Logger.Info("sync watch started", logging.F("tenant", env), logging.F("interval", interval.String()), logging.F("mode", mode), logging.F("quietHours", quiet))
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sigCtx.Done():
+			fmt.Fprintln(ctx.Stdout, "Watch stopped.")
+			return nil
+		case <-ticker.C:
+		}
+		now := time.Now()
+		if qh.contains(now) {
+			ctx.Logger.Debug("quiet hours; skipping poll", logging.F("quietHours", quiet))
+			continue
+		}
+		snap, err := tenantArtifactSnapshot(client, meta)
+		if err != nil {
+			ctx.Logger.Warn("tenant poll failed", logging.F("error", err.Error()))
+			continue
+		}
+		changes := diffArtifactSnapshots(baseline, snap)
+		if len(changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(ctx.Stdout, "%s CPI %s changed (%d artifact(s)):\n", now.Format(time.RFC3339), tenantDisplay(env), len(changes))
+		for _, c := range changes {
+			fmt.Fprintf(ctx.Stdout, "  %s\n", c)
+		}
+		if notifyOnly {
+			baseline = snap
+			continue
+		}
+		if branch, _ := gitCurrentBranch(ctx, repoRoot); branch != env {
+			ctx.Logger.Warn("current branch changed; skipping pull until it is checked out again", logging.F("expected", env), logging.F("current", branch))
+			continue
+		}
+		// Keep the old baseline on failure so the pull is retried on the next poll.
+		if err := runSyncPull(ctx, []string{"--message", message}); err != nil {
+			ctx.Logger.Warn("automatic pull failed; retrying on next poll", logging.F("error", err.Error()))
+			continue
+		}
+		baseline = snap
+	}
+}
+
+// tenantArtifactSnapshot maps Kind/ID to version and modification time for the package's artifacts.
+func tenantArtifactSnapshot(client *cpix.Client, meta models.SyncMetadata) (map[string]string, error) {
+	snap := map[string]string{}
+	for _, kind := range watchedKinds {
+		endpoint, ok := listEndpointForKind(meta.PackageID, kind)
+		if !ok {
+			continue
+		}
+		arts, err := client.ListArtifacts(context.Background(), endpoint)
+		if err != nil {
+			return nil, err
+		}
+		for id, a := range arts {
+			snap[kind+"/"+id] = a.Version + "|" + a.ModifiedAt
+		}
+	}
+	return snap, nil
+}
+
+func diffArtifactSnapshots(old, cur map[string]string) []string {
+	var out []string
+	for k, v := range cur {
+		prev, ok := old[k]
+		switch {
+		case !ok:
+			out = append(out, "added    "+k)
+		case prev != v:
+			out = append(out, "changed  "+k)
+		}
+	}
+	for k := range old {
+		if _, ok := cur[k]; !ok {
+			out = append(out, "removed  "+k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i][9:] < out[j][9:] })
+	return out
+}
+
+// quietHours is a daily local-time window [start, end); it may wrap midnight. A zero value never matches.
+type quietHours struct {
+	start, end int // minutes since midnight
+	set        bool
+}
+
+func parseQuietHours(s string) (quietHours, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return quietHours{}, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return quietHours{}, fmt.Errorf("invalid --quiet-hours %q: expected HH:MM-HH:MM", s)
+	}
+	start, err1 := time.Parse("15:04", strings.TrimSpace(from))
+	end, err2 := time.Parse("15:04", strings.TrimSpace(to))
+	if err1 != nil || err2 != nil {
+		return quietHours{}, fmt.Errorf("invalid --quiet-hours %q: expected HH:MM-HH:MM", s)
+	}
+	return quietHours{start: start.Hour()*60 + start.Minute(), end: end.Hour()*60 + end.Minute(), set: true}, nil
+}
+
+func (q quietHours) contains(t time.Time) bool {
+	if !q.set || q.start == q.end {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if q.start < q.end {
+		return m >= q.start && m < q.end
+	}
+	return m >= q.start || m < q.end
+}
@@ -32,6 +32,16 @@ func runSync(ctx *app.Context, args []string) error {
 		return runSyncDeliver(ctx, args[1:])
 	case "compare":
 		return runSyncCompare(ctx, args[1:])
+	case "rollback":
+		return runSyncRollback(ctx, args[1:])
+	case "transport":
+		return runSyncTransport(ctx, args[1:])
+	case "status":
+		return runSyncStatus(ctx, args[1:])
+	case "watch":
+		return runSyncWatch(ctx, args[1:])
+	case "lock":
+		return runSyncLock(ctx, args[1:])
 	default:
 		syncHelp(ctx, args)
 		return fmt.Errorf("unknown sync command: %s", args[0])
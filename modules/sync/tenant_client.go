@@ -0,0 +1,60 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/common/cpix"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// newTenantClient creates a CPI client for profileID/env.
+// It warns when the service key is older than the configured age and records successful auth times.
+func newTenantClient(ctx *app.Context, profileID, env string, key models.TenantServiceKey) *cpix.Client {
+	warnTenantKeyAge(ctx, profileID, env, key)
+	c := cpix.NewClient(key, ctx.Logger)
+	c.OnAuth(func(at time.Time) {
+		if err := ctx.Stores.Tenants.RecordAuth(profileID, env, at); err != nil {
+			ctx.Logger.Debug("cannot record tenant auth time", logging.F("env", env), logging.F("error", err.Error()))
+		}
+	})
+	return c
+}
+
+// keyAgeWarned avoids repeating the key age warning when a command creates several clients.
+var keyAgeWarned = map[string]bool{}
+
+func warnTenantKeyAge(ctx *app.Context, profileID, env string, key models.TenantServiceKey) {
+	if keyAgeWarned[profileID+"/"+env] {
+		return
+	}
+	cfg := models.Config{}
+	if c, err := ctx.Stores.Config.ReadOptional(); err == nil && c != nil {
+		cfg = *c
+	}
+	maxAge := cfg.TenantKeyMaxAge()
+	if maxAge <= 0 {
+		return
+	}
+	created, ok := key.CreatedAt()
+	if !ok {
+		keyAgeWarned[profileID+"/"+env] = true
+		ctx.Logger.Info("tenant service key age unknown (no oauth.createdate); cannot check it against tenantKeyMaxAgeDays",
+			logging.F("profile", profileID),
+			logging.F("env", env),
+		)
+		return
+	}
+	age := time.Since(created)
+	if age <= maxAge {
+		return
+	}
+	keyAgeWarned[profileID+"/"+env] = true
+	ctx.Logger.Warn("tenant service key is older than the configured maximum age; rotate it with `iflowkit tenant rotate`",
+		logging.F("profile", profileID),
+		logging.F("env", env),
+		logging.F("age_days", int(age.Hours()/24)),
+		logging.F("max_age_days", int(maxAge.Hours()/24)),
+	)
+}
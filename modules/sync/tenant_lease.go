@@ -0,0 +1,233 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	stdsync "sync"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+)
+
+// Tenant leases serialize commands that write to a tenant (and its transport index) across the
+// whole team. A lease is a commit on origin under refs/iflowkit/locks/<env> whose message is the
+// TenantLease JSON. Creating the ref is atomic on the server (a second push is rejected as
+// non-fast-forward); takeover, renewal and release use --force-with-lease against the sha that
+// was read, so two clients can never both win.
+const (
+	tenantLeaseRefPrefix = "refs/iflowkit/locks/"
+	// tenantLeaseTTL is how long a lease stays valid without renewal; the holder renews it every
+	// tenantLeaseTTL/3, so only crashed or disconnected runs leave a lease behind.
+	tenantLeaseTTL     = 15 * time.Minute
+	leasePollEvery     = 5 * time.Second
+	leaseCommitSubject = "iflowkit tenant lease"
+)
+
+// TenantLease is the content of a team-wide tenant lock.
+type TenantLease struct {
+	Env        string `json:"env"`
+	Owner      string `json:"owner"`
+	Host       string `json:"host"`
+	PID        int    `json:"pid"`
+	Command    string `json:"command"`
+	AcquiredAt string `json:"acquiredAt"`
+	ExpiresAt  string `json:"expiresAt"`
+}
+
+func (l TenantLease) expired(now time.Time) bool {
+	t, err := time.Parse(time.RFC3339, l.ExpiresAt)
+	return err != nil || !now.Before(t)
+}
+
+func (l TenantLease) describe() string {
+	return fmt.Sprintf("sync %s by %s on %s (pid %d) since %s, expires %s", l.Command, orDash(l.Owner), l.Host, l.PID, l.AcquiredAt, l.ExpiresAt)
+}
+
+func tenantLeaseRef(env string) string { return tenantLeaseRefPrefix + env }
+
+// acquireTenantLease takes the lease for env, waiting up to wait while another run holds it.
+// Expired leases are taken over. The returned release stops the renewal and deletes the ref.
+func acquireTenantLease(ctx *app.Context, repoRoot, env, command string, wait time.Duration) (release func(), err error) {
+	name, email, _ := gitUserIdentity(ctx, repoRoot)
+	owner := strings.TrimSpace(fmt.Sprintf("%s <%s>", name, email))
+	if email == "" {
+		owner = name
+	}
+	host, _ := os.Hostname()
+	deadline := time.Now().UTC().Add(wait)
+	waiting := false
+
+	for {
+		now := time.Now().UTC()
+		lease := TenantLease{Env: env, Owner: owner, Host: host, PID: os.Getpid(), Command: command, AcquiredAt: now.Format(time.RFC3339), ExpiresAt: now.Add(tenantLeaseTTL).Format(time.RFC3339)}
+		curSHA, cur, err := readRemoteLease(ctx, repoRoot, env)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the %s tenant lock from origin: %w", tenantDisplay(env), err)
+		}
+		if curSHA != "" && cur != nil && !cur.expired(now) {
+			if now.After(deadline) {
+				return nil, fmt.Errorf("CPI %s is locked: %s; retry later, wait with --lock-wait <duration>, or remove a stale lock with `iflowkit sync lock release --env %s --force`", tenantDisplay(env), cur.describe(), env)
+			}
+			if !waiting {
+				fmt.Fprintf(ctx.Stdout, "Waiting for the CPI %s lock (%s)...\n", tenantDisplay(env), cur.describe())
+				waiting = true
+			}
+			time.Sleep(min(leasePollEvery, time.Until(deadline)+time.Millisecond))
+			continue
+		}
+		if curSHA != "" {
+			if cur != nil {
+				ctx.Logger.Warn("taking over expired tenant lock", logging.F("env", env), logging.F("holder", cur.Owner), logging.F("command", cur.Command), logging.F("expiresAt", cur.ExpiresAt))
+			} else {
+				ctx.Logger.Warn("replacing unreadable tenant lock", logging.F("env", env), logging.F("sha", curSHA))
+			}
+		}
+		sha, err := writeLeaseCommit(ctx, repoRoot, lease)
+		if err != nil {
+			return nil, err
+		}
+		if err := pushLease(ctx, repoRoot, env, sha, curSHA); err != nil {
+			if leaseRejected(err) {
+				continue // lost a race; read the new holder
+			}
+			return nil, fmt.Errorf("cannot take the %s tenant lock: %w", tenantDisplay(env), err)
+		}
+		ctx.Logger.Info("tenant lock acquired", logging.F("env", env), logging.F("ref", tenantLeaseRef(env)), logging.F("expiresAt", lease.ExpiresAt))
+		return renewTenantLease(ctx, repoRoot, lease, sha), nil
+	}
+}
+
+// renewTenantLease keeps the lease alive in the background and returns its release function.
+func renewTenantLease(ctx *app.Context, repoRoot string, lease TenantLease, sha string) func() {
+	var mu stdsync.Mutex
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(tenantLeaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			next := lease
+			next.ExpiresAt = time.Now().UTC().Add(tenantLeaseTTL).Format(time.RFC3339)
+			mu.Lock()
+			newSHA, err := writeLeaseCommit(ctx, repoRoot, next)
+			if err == nil {
+				err = pushLease(ctx, repoRoot, lease.Env, newSHA, sha)
+			}
+			if err == nil {
+				sha = newSHA
+			}
+			mu.Unlock()
+			if err != nil {
+				ctx.Logger.Warn("failed to renew tenant lock", logging.F("env", lease.Env), logging.F("error", err.Error()))
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+		mu.Lock()
+		defer mu.Unlock()
+		if err := deleteRemoteLease(ctx, repoRoot, lease.Env, sha); err != nil {
+			ctx.Logger.Warn("failed to release tenant lock", logging.F("env", lease.Env), logging.F("error", err.Error()))
+			return
+		}
+		ctx.Logger.Info("tenant lock released", logging.F("env", lease.Env))
+	}
+}
+
+// readRemoteLease returns the sha and content of the lease for env on origin ("" when unlocked).
+// The lease is nil when the ref exists but its content cannot be parsed.
+func readRemoteLease(ctx *app.Context, repoRoot, env string) (string, *TenantLease, error) {
+	ref := tenantLeaseRef(env)
+	out, err := runGitOutput(ctx, repoRoot, "ls-remote", "origin", ref)
+	if err != nil {
+		return "", nil, err
+	}
+	sha := ""
+	for _, l := range splitLines(out) {
+		if f := strings.Fields(l); len(f) == 2 && f[1] == ref {
+			sha = f[0]
+		}
+	}
+	if sha == "" {
+		return "", nil, nil
+	}
+	if err := runGit(ctx, repoRoot, "fetch", "-q", "origin", "+"+ref+":"+ref); err != nil {
+		return "", nil, err
+	}
+	msg, err := runGitOutput(ctx, repoRoot, "log", "-1", "--format=%b", sha)
+	if err != nil {
+		return sha, nil, nil
+	}
+	var l TenantLease
+	if json.Unmarshal([]byte(msg), &l) != nil {
+		return sha, nil, nil
+	}
+	return sha, &l, nil
+}
+
+// listRemoteLeases returns the lease sha of every locked env on origin.
+func listRemoteLeases(ctx *app.Context, repoRoot string) (map[string]string, error) {
+	out, err := runGitOutput(ctx, repoRoot, "ls-remote", "origin", tenantLeaseRefPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]string{}
+	for _, l := range splitLines(out) {
+		if f := strings.Fields(l); len(f) == 2 && strings.HasPrefix(f[1], tenantLeaseRefPrefix) {
+			m[strings.TrimPrefix(f[1], tenantLeaseRefPrefix)] = f[0]
+		}
+	}
+	return m, nil
+}
+
+// writeLeaseCommit stores lease as a parentless commit with an empty tree and returns its sha.
+func writeLeaseCommit(ctx *app.Context, repoRoot string, lease TenantLease) (string, error) {
+	b, _ := json.Marshal(lease)
+	empty, err := os.CreateTemp("", "iflowkit-lease-*")
+	if err != nil {
+		return "", err
+	}
+	empty.Close()
+	defer os.Remove(empty.Name())
+	tree, err := runGitOutput(ctx, repoRoot, "hash-object", "-t", "tree", "-w", empty.Name())
+	if err != nil {
+		return "", err
+	}
+	return runGitOutput(ctx, repoRoot, "commit-tree", strings.TrimSpace(tree), "-m", leaseCommitSubject+" "+lease.Env, "-m", string(b))
+}
+
+// pushLease points the lease ref of env at sha. With expect set the push only succeeds while
+// origin still has expect; without it the ref must not exist yet.
+func pushLease(ctx *app.Context, repoRoot, env, sha, expect string) error {
+	ref := tenantLeaseRef(env)
+	if expect == "" {
+		return runGit(ctx, repoRoot, "push", "-q", "origin", sha+":"+ref)
+	}
+	return runGit(ctx, repoRoot, "push", "-q", "--force-with-lease="+ref+":"+expect, "origin", "+"+sha+":"+ref)
+}
+
+// deleteRemoteLease removes the lease ref of env while it still points at expect.
+func deleteRemoteLease(ctx *app.Context, repoRoot, env, expect string) error {
+	ref := tenantLeaseRef(env)
+	if err := runGit(ctx, repoRoot, "push", "-q", "--force-with-lease="+ref+":"+expect, "origin", ":"+ref); err != nil {
+		return err
+	}
+	_ = runGit(ctx, repoRoot, "update-ref", "-d", ref)
+	return nil
+}
+
+// leaseRejected reports whether a push failed because the ref changed on origin meanwhile.
+func leaseRejected(err error) bool {
+	s := err.Error()
+	return strings.Contains(s, "rejected") || strings.Contains(s, "stale info")
+}
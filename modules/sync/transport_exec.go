@@ -0,0 +1,301 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// Transport step phases, in execution order.
+const (
+	stepPhaseDelete = "delete"
+	stepPhaseUpload = "upload"
+	stepPhaseDeploy = "deploy"
+)
+
+// Transport step statuses. Every step emits started and then exactly one of the others.
+const (
+	stepStarted   = "started"
+	stepSucceeded = "succeeded"
+	stepFailed    = "failed"
+	stepSkipped   = "skipped"
+)
+
+// TransportStep is one tenant operation of a transport. The record keeps the latest state of
+// every step; Attempt is the executor run (see TransportRecord.Attempts) that produced it.
+type TransportStep struct {
+	Phase      string `json:"phase"`
+	Kind       string `json:"kind"`
+	ID         string `json:"id"`
+	Status     string `json:"status"`
+	Attempt    int    `json:"attempt"`
+	StartedAt  string `json:"startedAt"`
+	FinishedAt string `json:"finishedAt,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// TransportStepEvent is emitted whenever a step changes status.
+type TransportStepEvent struct {
+	TransportID   string `json:"transportId"`
+	TransportType string `json:"transportType"`
+	Tenant        string `json:"tenant"`
+	TransportStep
+}
+
+// transportTarget performs the operations of a transport on one tenant. An operation that cannot
+// apply to an artifact returns a skipStep error: the step is recorded as skipped and the artifact
+// leaves the work queue. Any other error fails the step and stops the run.
+type transportTarget interface {
+	Delete(k artifactKey) error
+	// Upload sends the artifact folder dir.
+	Upload(k artifactKey, dir string) error
+	Deploy(d deployTarget) error
+}
+
+type stepSkipError struct{ reason string }
+
+func (e *stepSkipError) Error() string { return e.reason }
+
+func skipStep(reason string) error { return &stepSkipError{reason: reason} }
+
+// transportRun counts what one executor run did.
+type transportRun struct {
+	Deleted  int
+	Updated  int
+	Deployed int
+	Skipped  int
+	// Steps are the finished steps of this run, in execution order.
+	Steps []TransportStep
+}
+
+// transportExecutor runs the delete, upload and deploy phases of a TransportRecord against a
+// target. The record is the retry state: it is persisted after every step, so a failed run
+// resumes from the first unfinished step.
+type transportExecutor struct {
+	ctx      *app.Context
+	repoRoot string
+	meta     models.SyncMetadata
+	tenant   string
+	store    *TransportStore
+	target   transportTarget
+	// onStep receives every step event after it has been logged and recorded.
+	onStep []func(TransportStepEvent)
+}
+
+// executeTransport runs rec against the CPI tenant of env. With notify set the configured
+// webhooks hear when a pending record is resumed and when the run completes or fails.
+func executeTransport(ctx *app.Context, repoRoot string, meta models.SyncMetadata, env string, rec *TransportRecord, store *TransportStore, notify bool) (*transportRun, error) {
+	if rec == nil {
+		return &transportRun{}, fmt.Errorf("transport record is nil")
+	}
+	n := newTransportNotifier(ctx, repoRoot, env, notify)
+	target, err := newCPITransportTarget(ctx, env, meta.PackageID)
+	if err != nil {
+		n.send(meta, notifyFailed, *rec, err)
+		return &transportRun{}, err
+	}
+	e := &transportExecutor{ctx: ctx, repoRoot: repoRoot, meta: meta, tenant: env, store: store, target: target}
+	e.onStep = append(e.onStep, stepProgress(ctx))
+	return e.execute(rec, n)
+}
+
+// execute runs rec and tells n about it: resumed before a rerun of a pending record, then failed,
+// or completed once the record is completed. A run that leaves deferred artifacts (selective
+// push/deliver) sends no completed event.
+func (e *transportExecutor) execute(rec *TransportRecord, n *transportNotifier) (*transportRun, error) {
+	if rec.Attempts > 0 || rec.Error != "" {
+		n.send(e.meta, notifyResumed, *rec, nil)
+	}
+	run, err := e.run(rec)
+	if err != nil {
+		n.send(e.meta, notifyFailed, *rec, err)
+		return run, err
+	}
+	if rec.TransportStatus == "completed" {
+		n.send(e.meta, notifyCompleted, *rec, nil)
+	}
+	return run, nil
+}
+
+// stepProgress prints every finished step to the console while the transport runs.
+func stepProgress(ctx *app.Context) func(TransportStepEvent) {
+	return func(ev TransportStepEvent) {
+		line := fmt.Sprintf("  %-6s %s/%s: %s", ev.Phase, ev.Kind, ev.ID, ev.Status)
+		switch ev.Status {
+		case stepStarted:
+			return
+		case stepSkipped:
+			line += " (" + ev.Reason + ")"
+		case stepFailed:
+			line += " (" + logging.Redact(ev.Error) + ")"
+		}
+		fmt.Fprintln(ctx.Stdout, line)
+	}
+}
+
+// run executes the remaining work of rec. Deletes go first so a renamed artifact does not clash
+// with its old id; every uploaded deployable artifact is queued for deploy. The record ends
+// completed, or pending while it has deferred artifacts or a step failed.
+func (e *transportExecutor) run(rec *TransportRecord) (*transportRun, error) {
+	rec.Attempts++
+	run := &transportRun{}
+
+	for _, k := range sortArtifactKeys(rec.DeleteRemaining) {
+		status, err := e.step(rec, run, stepPhaseDelete, k.Kind, k.ID, func() error {
+			return e.target.Delete(k)
+		})
+		if err != nil {
+			return run, err
+		}
+		if status == stepSucceeded {
+			run.Deleted++
+		}
+		rec.DeleteRemaining = removeUpload(rec.DeleteRemaining, k)
+		e.persist(rec)
+	}
+
+	for _, k := range sortArtifactKeys(rec.UploadRemaining) {
+		dir := filepath.Join(e.repoRoot, e.meta.BaseFolder, k.Kind, k.ID)
+		status, err := e.step(rec, run, stepPhaseUpload, k.Kind, k.ID, func() error {
+			if st, err := os.Stat(dir); err != nil || !st.IsDir() {
+				return skipStep("artifact directory missing")
+			}
+			return e.target.Upload(k, dir)
+		})
+		if err != nil {
+			return run, err
+		}
+		if status == stepSucceeded {
+			run.Updated++
+			if isDeployableKind(k.Kind) {
+				rec.DeployRemaining = mergeDeployRemaining(rec.DeployRemaining, []deployTarget{{Kind: k.Kind, ID: k.ID}})
+			}
+		}
+		rec.UploadRemaining = removeUpload(rec.UploadRemaining, k)
+		e.persist(rec)
+	}
+
+	for _, d := range sortDeployTargets(rec.DeployRemaining) {
+		status, err := e.step(rec, run, stepPhaseDeploy, d.Kind, d.ID, func() error {
+			return e.target.Deploy(d)
+		})
+		if err != nil {
+			return run, err
+		}
+		if status == stepSucceeded {
+			run.Deployed++
+		}
+		rec.DeployRemaining = removeDeployTarget(rec.DeployRemaining, d)
+		e.persist(rec)
+	}
+
+	if rec.TransportType == "rollback" {
+		if err := keepUnrestoredArtifacts(rec, run, e.tenant); err != nil {
+			e.persist(rec)
+			return run, err
+		}
+	}
+
+	rec.Error = ""
+	rec.TransportStatus = "completed"
+	if len(rec.DeferredUpload)+len(rec.DeferredDelete) > 0 {
+		// Selected artifacts are done; the record stays pending until the deferred ones are sent.
+		rec.TransportStatus = "pending"
+	}
+	e.persist(rec)
+	return run, nil
+}
+
+// keepUnrestoredArtifacts fails a rollback run whose uploads were skipped (e.g. an artifact deleted
+// in CPI since the tag cannot be created): the tenant would not match the branch. The artifacts go
+// back to UploadRemaining so a retry restores them once they exist in CPI again.
+func keepUnrestoredArtifacts(rec *TransportRecord, run *transportRun, tenant string) error {
+	var names []string
+	for _, s := range run.Steps {
+		if s.Phase != stepPhaseUpload || s.Status != stepSkipped {
+			continue
+		}
+		k := artifactKey{Kind: s.Kind, ID: s.ID}
+		rec.UploadRemaining = append(removeUpload(rec.UploadRemaining, k), k)
+		names = append(names, fmt.Sprintf("%s/%s (%s)", s.Kind, s.ID, s.Reason))
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	rec.TransportStatus = "pending"
+	rec.Error = fmt.Sprintf("not restored on CPI %s: %s", tenantDisplay(tenant), strings.Join(names, ", "))
+	return fmt.Errorf("rollback %s is incomplete: CPI %s does not match %s; %d artifact(s) were not restored: %s. Create them in CPI, then run `iflowkit sync transport retry %s`, or give up with `iflowkit sync transport abandon %s --reason <text>`", rec.TransportID, tenantDisplay(tenant), rec.Branch, len(names), strings.Join(names, ", "), rec.TransportID, rec.TransportID)
+}
+
+// step runs op as one step and records its outcome. A failure marks the record pending with
+// the error and is returned; a skip is not an error.
+func (e *transportExecutor) step(rec *TransportRecord, run *transportRun, phase, kind, id string, op func() error) (string, error) {
+	s := TransportStep{Phase: phase, Kind: kind, ID: id, Status: stepStarted, Attempt: rec.Attempts, StartedAt: time.Now().UTC().Format(time.RFC3339)}
+	e.record(rec, s)
+	e.persist(rec)
+
+	err := op()
+	s.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+	var skip *stepSkipError
+	switch {
+	case err == nil:
+		s.Status = stepSucceeded
+	case errors.As(err, &skip):
+		s.Status, s.Reason = stepSkipped, skip.reason
+		run.Skipped++
+		err = nil
+	default:
+		s.Status, s.Error = stepFailed, err.Error()
+		rec.TransportStatus = "pending"
+		rec.Error = err.Error()
+	}
+	e.record(rec, s)
+	run.Steps = append(run.Steps, s)
+	if err != nil {
+		e.persist(rec)
+	}
+	return s.Status, err
+}
+
+// record stores s in rec (replacing the previous state of the same step) and emits its event.
+func (e *transportExecutor) record(rec *TransportRecord, s TransportStep) {
+	replaced := false
+	for i, old := range rec.Steps {
+		if old.Phase == s.Phase && old.Kind == s.Kind && old.ID == s.ID {
+			rec.Steps[i] = s
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		rec.Steps = append(rec.Steps, s)
+	}
+
+	fields := []logging.Field{logging.F("transportId", rec.TransportID), logging.F("phase", s.Phase), logging.F("kind", s.Kind), logging.F("id", s.ID), logging.F("status", s.Status)}
+	switch s.Status {
+	case stepSkipped:
+		e.ctx.Logger.Warn("transport step skipped", append(fields, logging.F("reason", s.Reason))...)
+	case stepFailed:
+		e.ctx.Logger.Error("transport step failed", append(fields, logging.F("error", s.Error))...)
+	default:
+		e.ctx.Logger.Info("transport step "+s.Status, fields...)
+	}
+	ev := TransportStepEvent{TransportID: rec.TransportID, TransportType: rec.TransportType, Tenant: e.tenant, TransportStep: s}
+	for _, fn := range e.onStep {
+		fn(ev)
+	}
+}
+
+func (e *transportExecutor) persist(rec *TransportRecord) {
+	if _, err := e.store.PersistTransportRecord(*rec); err != nil {
+		e.ctx.Logger.Warn("failed to persist transport record", logging.F("transportId", rec.TransportID), logging.F("error", err.Error()))
+	}
+}
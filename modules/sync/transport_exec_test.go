@@ -0,0 +1,217 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// fakeTarget records the operations of a transport. missing lists artifacts it skips on upload
+// the way CPI does for artifacts that do not exist in the tenant; fail fails the named operation.
+type fakeTarget struct {
+	ops     []string
+	missing map[artifactKey]bool
+	fail    string
+}
+
+func (f *fakeTarget) do(op string) error {
+	f.ops = append(f.ops, op)
+	if op == f.fail {
+		return errors.New("HTTP 500")
+	}
+	return nil
+}
+
+func (f *fakeTarget) Delete(k artifactKey) error { return f.do("delete " + k.Kind + "/" + k.ID) }
+
+func (f *fakeTarget) Upload(k artifactKey, dir string) error {
+	if f.missing[k] {
+		return skipStep("not found in tenant; artifact creation is not supported")
+	}
+	return f.do("upload " + k.Kind + "/" + k.ID)
+}
+
+func (f *fakeTarget) Deploy(d deployTarget) error { return f.do("deploy " + d.Kind + "/" + d.ID) }
+
+// newTestExecutor returns an executor on a repository with the artifact folders of keys.
+func newTestExecutor(t *testing.T, target transportTarget, keys ...artifactKey) (*transportExecutor, *bytes.Buffer) {
+	t.Helper()
+	ctx := newTestContext(t)
+	progress := &bytes.Buffer{}
+	ctx.Stdout = progress
+	repo := t.TempDir()
+	meta := models.SyncMetadata{PackageID: "com.acme.pkg", BaseFolder: "IntegrationPackage"}
+	for _, k := range keys {
+		dir := filepath.Join(repo, meta.BaseFolder, k.Kind, k.ID)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store, err := NewTransportStore(repo, "qas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &transportExecutor{ctx: ctx, repoRoot: repo, meta: meta, tenant: "qas", store: store, target: target}
+	e.onStep = append(e.onStep, stepProgress(ctx))
+	return e, progress
+}
+
+func TestTransportExecutorStepEvents(t *testing.T) {
+	flow, script, gone := artifactKey{Kind: "iFlows", ID: "Flow1"}, artifactKey{Kind: "Scripts", ID: "S1"}, artifactKey{Kind: "iFlows", ID: "Old"}
+	target := &fakeTarget{}
+	e, progress := newTestExecutor(t, target, flow, script)
+	var events []string
+	e.onStep = append(e.onStep, func(ev TransportStepEvent) {
+		if ev.TransportID != "T1" || ev.Tenant != "qas" || ev.TransportType != "push" {
+			t.Errorf("event = %+v", ev)
+		}
+		events = append(events, ev.Phase+" "+ev.Kind+"/"+ev.ID+" "+ev.Status)
+	})
+	missingDir := artifactKey{Kind: "iFlows", ID: "NoDir"}
+	rec := &TransportRecord{
+		TransportID: "T1", TransportType: "push", Branch: "qas", TransportStatus: "pending",
+		UploadRemaining: []artifactKey{script, flow, missingDir},
+		DeleteRemaining: []artifactKey{gone},
+	}
+
+	run, err := e.run(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOps := []string{"delete iFlows/Old", "upload Scripts/S1", "upload iFlows/Flow1", "deploy Scripts/S1", "deploy iFlows/Flow1"}
+	if !reflect.DeepEqual(target.ops, wantOps) {
+		t.Errorf("ops = %v", target.ops)
+	}
+	if run.Deleted != 1 || run.Updated != 2 || run.Deployed != 2 || run.Skipped != 1 {
+		t.Errorf("run = %+v", run)
+	}
+	if rec.TransportStatus != "completed" || len(rec.UploadRemaining)+len(rec.DeleteRemaining)+len(rec.DeployRemaining) != 0 {
+		t.Errorf("record = %+v", rec)
+	}
+	if len(events) != 12 || events[0] != "delete iFlows/Old started" || events[1] != "delete iFlows/Old succeeded" {
+		t.Errorf("events = %v", events)
+	}
+	if !strings.Contains(strings.Join(events, "\n"), "upload iFlows/NoDir skipped") {
+		t.Errorf("no skipped event: %v", events)
+	}
+	for _, s := range rec.Steps {
+		if s.Attempt != 1 || s.Status == stepStarted {
+			t.Errorf("recorded step = %+v", s)
+		}
+	}
+
+	out := progress.String()
+	for _, l := range []string{"delete iFlows/Old: succeeded", "upload iFlows/NoDir: skipped (artifact directory missing)", "deploy Scripts/S1: succeeded"} {
+		if !strings.Contains(out, l) {
+			t.Errorf("progress misses %q:\n%s", l, out)
+		}
+	}
+	if strings.Contains(out, "started") {
+		t.Errorf("progress prints started steps:\n%s", out)
+	}
+}
+
+func TestTransportExecutorFailedStepStaysPending(t *testing.T) {
+	flow := artifactKey{Kind: "iFlows", ID: "Flow1"}
+	target := &fakeTarget{fail: "deploy iFlows/Flow1"}
+	e, progress := newTestExecutor(t, target, flow)
+	rec := &TransportRecord{TransportID: "T1", TransportType: "push", Branch: "qas", UploadRemaining: []artifactKey{flow}}
+
+	if _, err := e.run(rec); err == nil {
+		t.Fatal("want error")
+	}
+	if rec.TransportStatus != "pending" || rec.Error != "HTTP 500" || len(rec.UploadRemaining) != 0 || len(rec.DeployRemaining) != 1 {
+		t.Errorf("record = %+v", rec)
+	}
+	if !strings.Contains(progress.String(), "deploy iFlows/Flow1: failed (HTTP 500)") {
+		t.Errorf("progress = %s", progress.String())
+	}
+
+	// The retry resumes at the failed deploy.
+	target.fail, target.ops = "", nil
+	if _, err := e.run(rec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(target.ops, []string{"deploy iFlows/Flow1"}) || rec.TransportStatus != "completed" || rec.Attempts != 2 {
+		t.Errorf("retry ops = %v, record = %+v", target.ops, rec)
+	}
+}
+
+func TestTransportExecutorNotifications(t *testing.T) {
+	srv := newWebhookStandIn(t)
+	flow := artifactKey{Kind: "iFlows", ID: "Flow1"}
+	cases := []struct {
+		name  string
+		rec   TransportRecord
+		fail  string
+		event []string
+	}{
+		{"completed", TransportRecord{UploadRemaining: []artifactKey{flow}}, "", []string{notifyCompleted}},
+		{"deferred artifacts stay pending", TransportRecord{UploadRemaining: []artifactKey{flow}, DeferredUpload: []artifactKey{{Kind: "iFlows", ID: "Later"}}}, "", nil},
+		{"failed", TransportRecord{UploadRemaining: []artifactKey{flow}}, "upload iFlows/Flow1", []string{notifyFailed}},
+		{"resumed", TransportRecord{Attempts: 1, Error: "HTTP 500", DeployRemaining: []deployTarget{{Kind: "iFlows", ID: "Flow1"}}}, "", []string{notifyResumed, notifyCompleted}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv.reset()
+			e, _ := newTestExecutor(t, &fakeTarget{fail: tc.fail}, flow)
+			n := &transportNotifier{ctx: e.ctx, env: "qas", client: srv.Client(), webhooks: []webhook{{WebhookSpec: WebhookSpec{Name: "x", Format: "json"}, url: srv.URL}}}
+			rec := tc.rec
+			rec.TransportID, rec.TransportType, rec.Branch = "T1", "push", "qas"
+			_, _ = e.execute(&rec, n)
+
+			var got []string
+			for _, r := range srv.requests {
+				var msg TransportNotification
+				if err := json.Unmarshal(r.Body, &msg); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, msg.Event)
+			}
+			if !reflect.DeepEqual(got, tc.event) {
+				t.Errorf("events = %v, want %v (status %s)", got, tc.event, rec.TransportStatus)
+			}
+		})
+	}
+}
+
+func TestTransportExecutorRollbackKeepsUnrestoredArtifacts(t *testing.T) {
+	flow, deleted := artifactKey{Kind: "iFlows", ID: "Flow1"}, artifactKey{Kind: "iFlows", ID: "Gone"}
+	target := &fakeTarget{missing: map[artifactKey]bool{deleted: true}}
+	e, _ := newTestExecutor(t, target, flow, deleted)
+	rec := &TransportRecord{TransportID: "T9", TransportType: "rollback", Branch: "qas", UploadRemaining: []artifactKey{flow, deleted}}
+
+	_, err := e.run(rec)
+	if err == nil || !strings.Contains(err.Error(), "iFlows/Gone (not found in tenant") || !strings.Contains(err.Error(), "sync transport retry T9") {
+		t.Fatalf("err = %v", err)
+	}
+	if rec.TransportStatus != "pending" || !reflect.DeepEqual(rec.UploadRemaining, []artifactKey{deleted}) || !strings.Contains(rec.Error, "iFlows/Gone") {
+		t.Errorf("record = %+v", rec)
+	}
+	if !reflect.DeepEqual(target.ops, []string{"upload iFlows/Flow1", "deploy iFlows/Flow1"}) {
+		t.Errorf("ops = %v", target.ops)
+	}
+
+	// Once the artifact exists in CPI again, the retry restores and deploys it.
+	target.missing, target.ops = nil, nil
+	if _, err := e.run(rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.TransportStatus != "completed" || rec.Error != "" || !reflect.DeepEqual(target.ops, []string{"upload iFlows/Gone", "deploy iFlows/Gone"}) {
+		t.Errorf("retry ops = %v, record = %+v", target.ops, rec)
+	}
+
+	// Other transport types keep recording such skips without failing.
+	push := &TransportRecord{TransportID: "T10", TransportType: "push", Branch: "qas", UploadRemaining: []artifactKey{deleted}}
+	target.missing = map[artifactKey]bool{deleted: true}
+	if _, err := e.run(push); err != nil || push.TransportStatus != "completed" {
+		t.Errorf("push: err = %v, status %s", err, push.TransportStatus)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/iflowkit/iflowkit-cli/internal/common/filex"
+	"github.com/iflowkit/iflowkit-cli/internal/common/logx"
 	"github.com/iflowkit/iflowkit-cli/internal/validate"
 )
 
@@ -24,7 +25,7 @@ const (
 type TransportRecord struct {
 	SchemaVersion int    `json:"schemaVersion"`
 	TransportID   string `json:"transportId"`
-	TransportType string `json:"transportType"` // init | pull | push | deliver
+	TransportType string `json:"transportType"` // init | pull | push | deliver | rollback | deploy
 	PackageID     string `json:"packageId"`
 	Branch        string `json:"branch"`
 	CreatedAt     string `json:"createdAt"`
@@ -41,12 +42,42 @@ type TransportRecord struct {
 	// For push: objects deleted in the repo and removed from CPI.
 	DeletedObjects []SyncObject `json:"deletedObjects,omitempty"`
 
-	TransportStatus string `json:"transportStatus"` // pending | completed
+	TransportStatus string `json:"transportStatus"` // pending | completed | cancelled
 	Error           string `json:"error,omitempty"`
 
+	// Set by `sync transport abandon`; cancelled records are never resumed.
+	CancelledAt  string `json:"cancelledAt,omitempty"`
+	CancelledBy  string `json:"cancelledBy,omitempty"`
+	CancelReason string `json:"cancelReason,omitempty"`
+
 	UploadRemaining []artifactKey  `json:"uploadRemaining"`
 	DeleteRemaining []artifactKey  `json:"deleteRemaining,omitempty"`
 	DeployRemaining []deployTarget `json:"deployRemaining"`
+
+	// Attempts counts the executor runs of this record; Steps holds the latest state of every
+	// delete/upload/deploy step (see transportExecutor).
+	Attempts int             `json:"attempts,omitempty"`
+	Steps    []TransportStep `json:"steps,omitempty"`
+
+	// DeferredUpload/DeferredDelete hold artifacts left out by `sync push --only/--exclude`.
+	// They keep the record pending; the next push returns them to the work queue.
+	DeferredUpload []artifactKey `json:"deferredUpload,omitempty"`
+	DeferredDelete []artifactKey `json:"deferredDelete,omitempty"`
+
+	// RollbackTo is the transport id whose tag a rollback restores.
+	RollbackTo string `json:"rollbackTo,omitempty"`
+
+	// SourceArtifacts is set by `sync deliver --only`: the source commit of each promoted artifact.
+	SourceArtifacts []ArtifactSource `json:"sourceArtifacts,omitempty"`
+
+	// Confirmation is set for PRD runs (typed confirmation or --yes).
+	Confirmation *TransportConfirmation `json:"confirmation,omitempty"`
+
+	// Conflict is set when a deliver merge conflicted: the artifacts involved and how they were resolved.
+	Conflict *DeliverConflict `json:"conflict,omitempty"`
+
+	// PullRequest is set by `sync deliver --complete`: the reviewed pull/merge request.
+	PullRequest *DeliverPullRequest `json:"pullRequest,omitempty"`
 }
 
 // TransportIndex is stored at:
@@ -132,7 +163,7 @@ func sanitizeTransportID(s string) string {
 func normalizeTransportType(t string) string {
 	t = strings.ToLower(strings.TrimSpace(t))
 	switch t {
-	case "init", "pull", "push", "deliver":
+	case "init", "pull", "push", "deliver", "rollback", "deploy":
 		return t
 	default:
 		return "push"
@@ -141,10 +172,12 @@ func normalizeTransportType(t string) string {
 
 func normalizeTransportStatus(s string) string {
 	s = strings.ToLower(strings.TrimSpace(s))
-	if s == "completed" {
-		return "completed"
+	switch s {
+	case "completed", "cancelled":
+		return s
+	default:
+		return "pending"
 	}
-	return "pending"
 }
 
 // PersistTransportRecord saves the record file and updates index.json.
@@ -161,6 +194,8 @@ func (s *TransportStore) PersistTransportRecord(rec TransportRecord) (string, er
 	}
 	rec.TransportType = normalizeTransportType(rec.TransportType)
 	rec.TransportStatus = normalizeTransportStatus(rec.TransportStatus)
+	// Records are committed to git; never persist secrets from error messages.
+	rec.Error = logx.Redact(rec.Error)
 	if rec.Objects == nil {
 		rec.Objects = []SyncObject{}
 	}
@@ -399,7 +434,7 @@ func (s *TransportStore) LoadLatestTransportRecord() (*TransportRecord, string,
 	return best, bestPath, true, nil
 }
 
-// LoadLatestPendingTransport finds the most recent transport record that is not completed.
+// LoadLatestPendingTransport finds the most recent transport record that is still pending (not completed or cancelled).
 // If transportType is provided, only that transportType (init|pull|push) is considered.
 func (s *TransportStore) LoadLatestPendingTransport(packageID, branch, transportType string) (*TransportRecord, string, bool, error) {
 	transportType = strings.TrimSpace(transportType)
@@ -412,7 +447,7 @@ func (s *TransportStore) LoadLatestPendingTransport(packageID, branch, transport
 	}
 	for i := len(idx.Items) - 1; i >= 0; i-- {
 		it := idx.Items[i]
-		if normalizeTransportStatus(it.TransportStatus) == "completed" {
+		if normalizeTransportStatus(it.TransportStatus) != "pending" {
 			continue
 		}
 		if transportType != "" && normalizeTransportType(it.TransportType) != normalizeTransportType(transportType) {
@@ -431,9 +466,88 @@ func (s *TransportStore) LoadLatestPendingTransport(packageID, branch, transport
 		if transportType != "" && normalizeTransportType(r.TransportType) != normalizeTransportType(transportType) {
 			continue
 		}
+		if r.TransportStatus != "pending" {
+			continue // index is stale
+		}
 		p := s.recordPath(r.TransportID)
 		cp := r
 		return &cp, p, true, nil
 	}
 	return nil, "", false, nil
 }
+
+// ListRecords loads every record file of this tenant, oldest first (createdAt, then transport id).
+// Unreadable files are skipped.
+func (s *TransportStore) ListRecords() ([]TransportRecord, error) {
+	recs, _, err := s.loadRecords()
+	return recs, err
+}
+
+// loadRecords is ListRecords that also returns the skipped files as "<file>: <error>".
+func (s *TransportStore) loadRecords() ([]TransportRecord, []string, error) {
+	paths, err := s.listRecordPaths()
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make([]TransportRecord, 0, len(paths))
+	var skipped []string
+	for _, p := range paths {
+		id := strings.TrimSuffix(filepath.Base(p), transportRecordExt)
+		r, err := s.LoadRecord(id)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", filepath.Base(p), err))
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ti, tj := parseCreatedAtOrZero(out[i].CreatedAt), parseCreatedAtOrZero(out[j].CreatedAt)
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return out[i].TransportID < out[j].TransportID
+	})
+	return out, skipped, nil
+}
+
+// reindexStats is the outcome of TransportStore.Reindex.
+type reindexStats struct {
+	Indexed int
+	// Skipped lists unreadable record files ("<file>: <error>") left out of the index.
+	Skipped []string
+	// Written is false when there is neither a record nor an index file.
+	Written bool
+}
+
+// Reindex rebuilds index.json from the record files. Without records an existing index is
+// emptied rather than left listing records that are gone.
+func (s *TransportStore) Reindex() (reindexStats, error) {
+	recs, skipped, err := s.loadRecords()
+	stats := reindexStats{Indexed: len(recs), Skipped: skipped}
+	if err != nil {
+		return stats, err
+	}
+	if len(recs) == 0 {
+		if _, err := os.Stat(s.indexPath()); err != nil {
+			if os.IsNotExist(err) {
+				return stats, nil
+			}
+			return stats, err
+		}
+	}
+	idx := TransportIndex{SchemaVersion: 1, Items: make([]TransportIndexItem, 0, len(recs))}
+	for i, r := range recs {
+		idx.Items = append(idx.Items, TransportIndexItem{
+			Seq:             i + 1,
+			TransportID:     r.TransportID,
+			TransportType:   r.TransportType,
+			TransportStatus: r.TransportStatus,
+			CreatedAt:       r.CreatedAt,
+		})
+	}
+	if err := s.saveIndex(idx); err != nil {
+		return stats, err
+	}
+	stats.Written = true
+	return stats, nil
+}
@@ -0,0 +1,311 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/app"
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+	"github.com/iflowkit/iflowkit-cli/internal/validate"
+)
+
+const (
+	webhooksFileName = "webhooks.json"
+	webhookTimeout   = 10 * time.Second
+	webhookAttempts  = 3
+)
+
+// webhookBackoff is the wait before the second attempt; it is doubled after every failed attempt.
+var webhookBackoff = time.Second
+
+// Transport notification events.
+const (
+	notifyCompleted = "completed"
+	notifyFailed    = "failed"
+	notifyResumed   = "resumed"
+)
+
+var notifyEvents = []string{notifyCompleted, notifyFailed, notifyResumed}
+
+// Webhook payload formats: the plain TransportNotification JSON or a chat incoming-webhook message.
+var webhookFormats = []string{"json", "slack", "teams", "googlechat"}
+
+// WebhooksFile is .iflowkit/webhooks.json in the repository or webhooks.json in the profile folder:
+//
+//	{"webhooks": [{"name": "team", "urlEnv": "TEAM_WEBHOOK_URL", "format": "slack", "envs": ["qas", "prd"]}]}
+type WebhooksFile struct {
+	Webhooks []WebhookSpec `json:"webhooks"`
+}
+
+type WebhookSpec struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+	// URLEnv names an environment variable holding the URL, so webhook secrets stay out of git.
+	URLEnv string `json:"urlEnv,omitempty"`
+	// Format is json (default), slack, teams or googlechat.
+	Format string `json:"format,omitempty"`
+	// Envs and Events limit the webhook; empty means all.
+	Envs    []string          `json:"envs,omitempty"`
+	Events  []string          `json:"events,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// TransportNotification is the payload of the json format.
+type TransportNotification struct {
+	SchemaVersion   int          `json:"schemaVersion"`
+	Event           string       `json:"event"`
+	TransportID     string       `json:"transportId"`
+	TransportType   string       `json:"transportType"`
+	TransportStatus string       `json:"transportStatus"`
+	PackageID       string       `json:"packageId"`
+	Env             string       `json:"env"`
+	Branch          string       `json:"branch"`
+	Objects         []SyncObject `json:"objects"`
+	DeletedObjects  []SyncObject `json:"deletedObjects"`
+	GitUserName     string       `json:"gitUserName,omitempty"`
+	GitUserEmail    string       `json:"gitUserEmail,omitempty"`
+	Error           string       `json:"error,omitempty"`
+	SentAt          string       `json:"sentAt"`
+}
+
+// noNotifyFlag registers --no-notify on a command that runs transports.
+func noNotifyFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("no-notify", false, "Do not send webhook notifications for this run")
+}
+
+type webhook struct {
+	WebhookSpec
+	url string
+}
+
+// transportNotifier sends transport events to the webhooks of the repository and the profile.
+// Notifications never change the outcome of a transport: problems are logged as warnings.
+type transportNotifier struct {
+	ctx      *app.Context
+	env      string
+	webhooks []webhook
+	client   *http.Client
+}
+
+// newTransportNotifier loads the webhooks for env. It returns an empty notifier when disabled.
+func newTransportNotifier(ctx *app.Context, repoRoot, env string, enabled bool) *transportNotifier {
+	n := &transportNotifier{ctx: ctx, env: env, client: &http.Client{Timeout: webhookTimeout}}
+	if !enabled {
+		return n
+	}
+	paths := []string{filepath.Join(repoRoot, ".iflowkit", webhooksFileName)}
+	if profileID, _, err := ctx.Stores.ResolveProfileID(ctx.Flags.ProfileID); err == nil {
+		paths = append(paths, filepath.Join(ctx.Stores.Profiles.ProfileDir(profileID), webhooksFileName))
+	}
+	for _, p := range paths {
+		hooks, err := loadWebhooks(p)
+		if err != nil {
+			ctx.Logger.Warn("webhook configuration ignored", logging.F("file", p), logging.F("error", err.Error()))
+			continue
+		}
+		for _, h := range hooks {
+			if len(h.Envs) == 0 || slices.Contains(h.Envs, env) {
+				n.webhooks = append(n.webhooks, h)
+			}
+		}
+	}
+	return n
+}
+
+func loadWebhooks(path string) ([]webhook, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f WebhooksFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	out := make([]webhook, 0, len(f.Webhooks))
+	for i, s := range f.Webhooks {
+		h := webhook{WebhookSpec: s}
+		if strings.TrimSpace(h.Name) == "" {
+			h.Name = fmt.Sprintf("webhook[%d]", i)
+		}
+		h.url = strings.TrimSpace(s.URL)
+		if s.URLEnv != "" {
+			h.url = strings.TrimSpace(os.Getenv(s.URLEnv))
+			if h.url == "" {
+				return nil, fmt.Errorf("%s: environment variable %s is not set", h.Name, s.URLEnv)
+			}
+		}
+		if h.url == "" {
+			return nil, fmt.Errorf("%s: url or urlEnv is required", h.Name)
+		}
+		h.Format = strings.ToLower(strings.TrimSpace(h.Format))
+		if h.Format == "" {
+			h.Format = "json"
+		}
+		if !slices.Contains(webhookFormats, h.Format) {
+			return nil, fmt.Errorf("%s: unknown format %q (expected one of %s)", h.Name, h.Format, strings.Join(webhookFormats, ", "))
+		}
+		for _, e := range h.Envs {
+			if err := validate.Env(e); err != nil {
+				return nil, fmt.Errorf("%s: %w", h.Name, err)
+			}
+		}
+		for _, e := range h.Events {
+			if !slices.Contains(notifyEvents, e) {
+				return nil, fmt.Errorf("%s: unknown event %q (expected one of %s)", h.Name, e, strings.Join(notifyEvents, ", "))
+			}
+		}
+		out = append(out, h)
+	}
+	return out, nil
+}
+
+// send notifies every webhook subscribed to event about rec.
+func (n *transportNotifier) send(meta models.SyncMetadata, event string, rec TransportRecord, cause error) {
+	if len(n.webhooks) == 0 {
+		return
+	}
+	msg := TransportNotification{
+		SchemaVersion:   1,
+		Event:           event,
+		TransportID:     rec.TransportID,
+		TransportType:   rec.TransportType,
+		TransportStatus: rec.TransportStatus,
+		PackageID:       meta.PackageID,
+		Env:             n.env,
+		Branch:          rec.Branch,
+		Objects:         append([]SyncObject{}, rec.Objects...),
+		DeletedObjects:  append([]SyncObject{}, rec.DeletedObjects...),
+		GitUserName:     rec.GitUserName,
+		GitUserEmail:    rec.GitUserEmail,
+		Error:           rec.Error,
+		SentAt:          time.Now().UTC().Format(time.RFC3339),
+	}
+	if cause != nil {
+		msg.Error = cause.Error()
+	}
+	// Errors may embed CPI response bodies; registered secrets must not reach a chat service.
+	msg.Error = logging.Redact(msg.Error)
+	for _, h := range n.webhooks {
+		if len(h.Events) > 0 && !slices.Contains(h.Events, event) {
+			continue
+		}
+		body, err := renderWebhookPayload(h.Format, msg)
+		if err == nil {
+			err = n.post(h, body)
+		}
+		if err != nil {
+			n.ctx.Logger.Warn("webhook notification failed", logging.F("webhook", h.Name), logging.F("event", event), logging.F("transportId", rec.TransportID), logging.F("error", err.Error()))
+			continue
+		}
+		n.ctx.Logger.Info("webhook notified", logging.F("webhook", h.Name), logging.F("event", event), logging.F("transportId", rec.TransportID))
+	}
+}
+
+// post delivers body, retrying network errors, 429 and 5xx responses with a doubling backoff.
+func (n *transportNotifier) post(h webhook, body []byte) error {
+	wait := webhookBackoff
+	var lastErr error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+		req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "iflowkit-cli")
+		for k, v := range h.Headers {
+			req.Header.Set(k, os.ExpandEnv(v))
+		}
+		resp, err := n.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("HTTP %s", resp.Status)
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return lastErr
+		}
+	}
+	return fmt.Errorf("%w (after %d attempts)", lastErr, webhookAttempts)
+}
+
+// renderWebhookPayload builds the request body of format for msg.
+func renderWebhookPayload(format string, msg TransportNotification) ([]byte, error) {
+	title, lines := notificationText(msg)
+	switch format {
+	case "slack", "googlechat":
+		// Both accept {"text": ...} with *bold* markup.
+		return json.Marshal(map[string]string{"text": "*" + title + "*\n" + strings.Join(lines, "\n")})
+	case "teams":
+		color := "2EB886"
+		switch msg.Event {
+		case notifyFailed:
+			color = "D00000"
+		case notifyResumed:
+			color = "DAA038"
+		}
+		return json.Marshal(map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    title,
+			"themeColor": color,
+			"title":      title,
+			"text":       strings.Join(lines, "<br>"),
+		})
+	default:
+		return json.Marshal(msg)
+	}
+}
+
+// notificationText is the human-readable form of msg used by the chat formats.
+func notificationText(msg TransportNotification) (string, []string) {
+	title := fmt.Sprintf("iFlowKit %s %s on CPI %s: %s", msg.TransportType, msg.TransportID, tenantDisplay(msg.Env), msg.Event)
+	user := msg.GitUserName
+	if msg.GitUserEmail != "" {
+		user = strings.TrimSpace(user + " <" + msg.GitUserEmail + ">")
+	}
+	lines := []string{fmt.Sprintf("Package %s, branch %s, by %s (status %s)", msg.PackageID, msg.Branch, orDash(user), msg.TransportStatus)}
+	if len(msg.Objects) > 0 {
+		lines = append(lines, "Changed: "+objectList(msg.Objects))
+	}
+	if len(msg.DeletedObjects) > 0 {
+		lines = append(lines, "Deleted: "+objectList(msg.DeletedObjects))
+	}
+	if msg.Error != "" {
+		lines = append(lines, "Error: "+msg.Error)
+	}
+	if msg.Event == notifyFailed && msg.TransportStatus == "pending" {
+		lines = append(lines, fmt.Sprintf("Resume with: iflowkit sync transport retry %s", msg.TransportID))
+	}
+	return title, lines
+}
+
+func objectList(objs []SyncObject) string {
+	names := make([]string, 0, len(objs))
+	for _, o := range objs {
+		names = append(names, o.Kind+"/"+o.ID)
+	}
+	return strings.Join(names, ", ")
+}
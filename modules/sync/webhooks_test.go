@@ -0,0 +1,282 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iflowkit/iflowkit-cli/internal/logging"
+	"github.com/iflowkit/iflowkit-cli/internal/models"
+)
+
+// webhookStandIn is a local HTTP receiver answering with the queued status codes (then 200).
+type webhookStandIn struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []webhookRequest
+}
+
+type webhookRequest struct {
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+func newWebhookStandIn(t *testing.T, statuses ...int) *webhookStandIn {
+	t.Helper()
+	s := &webhookStandIn{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, webhookRequest{Path: r.URL.Path, Header: r.Header.Clone(), Body: body})
+		code := http.StatusOK
+		if len(s.statuses) > 0 {
+			code, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *webhookStandIn) paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []string{}
+	for _, r := range s.requests {
+		out = append(out, r.Path)
+	}
+	slices.Sort(out)
+	return out
+}
+
+func (s *webhookStandIn) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+func fastWebhookBackoff(t *testing.T) {
+	old := webhookBackoff
+	webhookBackoff = time.Millisecond
+	t.Cleanup(func() { webhookBackoff = old })
+}
+
+func writeWebhooksFile(t *testing.T, path string, hooks ...WebhookSpec) {
+	t.Helper()
+	b, err := json.Marshal(WebhooksFile{Webhooks: hooks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testNotification(event string) TransportNotification {
+	return TransportNotification{
+		SchemaVersion: 1, Event: event, TransportID: "T1", TransportType: "deliver", TransportStatus: "pending",
+		PackageID: "com.acme.pkg", Env: "qas", Branch: "qas",
+		Objects:        []SyncObject{{Kind: "IntegrationFlow", ID: "Flow1"}},
+		DeletedObjects: []SyncObject{{Kind: "ValueMapping", ID: "VM1"}},
+		GitUserName:    "Dev", GitUserEmail: "dev@example.com", Error: "upload failed", SentAt: "2024-05-01T10:00:00Z",
+	}
+}
+
+func TestRenderWebhookPayload(t *testing.T) {
+	msg := testNotification(notifyFailed)
+
+	b, err := renderWebhookPayload("json", msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got TransportNotification
+	if err := json.Unmarshal(b, &got); err != nil || !reflect.DeepEqual(got, msg) {
+		t.Errorf("json payload = %s (%v)", b, err)
+	}
+
+	wantLines := []string{
+		"iFlowKit deliver T1 on CPI QAS: failed",
+		"Package com.acme.pkg, branch qas, by Dev <dev@example.com> (status pending)",
+		"Changed: IntegrationFlow/Flow1",
+		"Deleted: ValueMapping/VM1",
+		"Error: upload failed",
+		"Resume with: iflowkit sync transport retry T1",
+	}
+	for _, format := range []string{"slack", "googlechat"} {
+		b, err := renderWebhookPayload(format, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var chat map[string]string
+		if err := json.Unmarshal(b, &chat); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(chat) != 1 || !strings.HasPrefix(chat["text"], "*"+wantLines[0]+"*\n") {
+			t.Errorf("%s payload = %s", format, b)
+		}
+		for _, l := range wantLines[1:] {
+			if !strings.Contains(chat["text"], l) {
+				t.Errorf("%s text misses %q: %s", format, l, chat["text"])
+			}
+		}
+	}
+
+	for event, color := range map[string]string{notifyCompleted: "2EB886", notifyFailed: "D00000", notifyResumed: "DAA038"} {
+		b, err := renderWebhookPayload("teams", testNotification(event))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var card map[string]string
+		if err := json.Unmarshal(b, &card); err != nil {
+			t.Fatal(err)
+		}
+		if card["@type"] != "MessageCard" || card["themeColor"] != color || card["title"] != card["summary"] || !strings.Contains(card["text"], "<br>Changed: IntegrationFlow/Flow1") {
+			t.Errorf("teams %s card = %v", event, card)
+		}
+	}
+}
+
+func TestWebhookPostRetries(t *testing.T) {
+	fastWebhookBackoff(t)
+	cases := []struct {
+		name     string
+		statuses []int
+		attempts int
+		wantErr  bool
+	}{
+		{"ok", nil, 1, false},
+		{"5xx then ok", []int{502, 500}, 3, false},
+		{"429 then ok", []int{429}, 2, false},
+		{"5xx exhausted", []int{503, 503, 503}, 3, true},
+		{"4xx not retried", []int{400}, 1, true},
+		{"404 not retried", []int{404}, 1, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newWebhookStandIn(t, tc.statuses...)
+			n := &transportNotifier{ctx: newTestContext(t), client: srv.Client()}
+			err := n.post(webhook{WebhookSpec: WebhookSpec{Name: "x"}, url: srv.URL}, []byte(`{}`))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("post() = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got := len(srv.requests); got != tc.attempts {
+				t.Errorf("attempts = %d, want %d", got, tc.attempts)
+			}
+		})
+	}
+}
+
+func TestTransportNotifierFiltering(t *testing.T) {
+	srv := newWebhookStandIn(t)
+	ctx := newTestContext(t)
+	ctx.Flags.ProfileID = "acme"
+	repo := t.TempDir()
+	writeWebhooksFile(t, filepath.Join(repo, ".iflowkit", webhooksFileName),
+		WebhookSpec{Name: "all", URL: srv.URL + "/all"},
+		WebhookSpec{Name: "qas", URL: srv.URL + "/qas", Envs: []string{"qas"}},
+		WebhookSpec{Name: "prd", URL: srv.URL + "/prd", Envs: []string{"prd"}},
+		WebhookSpec{Name: "failures", URL: srv.URL + "/failed", Events: []string{notifyFailed}},
+	)
+	writeWebhooksFile(t, filepath.Join(ctx.Stores.Profiles.ProfileDir("acme"), webhooksFileName),
+		WebhookSpec{Name: "profile", URL: srv.URL + "/profile", Format: "slack"},
+	)
+	meta := models.SyncMetadata{PackageID: "com.acme.pkg"}
+	rec := TransportRecord{TransportID: "T1", TransportType: "push", TransportStatus: "completed", Branch: "qas"}
+
+	n := newTransportNotifier(ctx, repo, "qas", true)
+	n.send(meta, notifyCompleted, rec, nil)
+	if got, want := srv.paths(), []string{"/all", "/profile", "/qas"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("completed on qas: %v, want %v", got, want)
+	}
+
+	srv.reset()
+	n.send(meta, notifyFailed, rec, errors.New("boom"))
+	if got, want := srv.paths(), []string{"/all", "/failed", "/profile", "/qas"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("failed on qas: %v, want %v", got, want)
+	}
+
+	srv.reset()
+	newTransportNotifier(ctx, repo, "dev", true).send(meta, notifyResumed, rec, nil)
+	if got, want := srv.paths(), []string{"/all", "/profile"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed on dev: %v, want %v", got, want)
+	}
+}
+
+func TestTransportNotifierHeadersAndURLEnv(t *testing.T) {
+	srv := newWebhookStandIn(t)
+	ctx := newTestContext(t)
+	repo := t.TempDir()
+	t.Setenv("TEAM_WEBHOOK_URL", srv.URL+"/team")
+	t.Setenv("TEAM_WEBHOOK_TOKEN", "tok-123456")
+	writeWebhooksFile(t, filepath.Join(repo, ".iflowkit", webhooksFileName), WebhookSpec{
+		URLEnv:  "TEAM_WEBHOOK_URL",
+		Headers: map[string]string{"Authorization": "Bearer ${TEAM_WEBHOOK_TOKEN}", "X-Static": "v"},
+	})
+
+	newTransportNotifier(ctx, repo, "qas", true).send(models.SyncMetadata{PackageID: "p"}, notifyCompleted, TransportRecord{TransportID: "T1"}, nil)
+	if len(srv.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(srv.requests))
+	}
+	r := srv.requests[0]
+	if r.Path != "/team" || r.Header.Get("Authorization") != "Bearer tok-123456" || r.Header.Get("X-Static") != "v" || r.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("request = %s %v", r.Path, r.Header)
+	}
+
+	// An unset urlEnv disables the file's webhooks with a warning instead of failing the transport.
+	t.Setenv("TEAM_WEBHOOK_URL", "")
+	if n := newTransportNotifier(ctx, repo, "qas", true); len(n.webhooks) != 0 {
+		t.Errorf("webhooks with unset urlEnv = %d, want 0", len(n.webhooks))
+	}
+}
+
+func TestTransportNotifierNoNotify(t *testing.T) {
+	srv := newWebhookStandIn(t)
+	ctx := newTestContext(t)
+	repo := t.TempDir()
+	writeWebhooksFile(t, filepath.Join(repo, ".iflowkit", webhooksFileName), WebhookSpec{URL: srv.URL})
+
+	fs := flag.NewFlagSet("push", flag.ContinueOnError)
+	noNotify := noNotifyFlag(fs)
+	if err := fs.Parse([]string{"--no-notify"}); err != nil {
+		t.Fatal(err)
+	}
+	newTransportNotifier(ctx, repo, "qas", !*noNotify).send(models.SyncMetadata{}, notifyFailed, TransportRecord{}, errors.New("boom"))
+	if len(srv.requests) != 0 {
+		t.Errorf("--no-notify sent %d requests", len(srv.requests))
+	}
+}
+
+func TestTransportNotifierRedactsError(t *testing.T) {
+	srv := newWebhookStandIn(t)
+	ctx := newTestContext(t)
+	repo := t.TempDir()
+	writeWebhooksFile(t, filepath.Join(repo, ".iflowkit", webhooksFileName), WebhookSpec{URL: srv.URL})
+	const secret = "client-secret-9f8e7d6c"
+	logging.RegisterSecret(secret)
+
+	cause := errors.New(`CPI upload failed (401): {"error":"invalid_client","client_secret":"` + secret + `"}`)
+	newTransportNotifier(ctx, repo, "qas", true).send(models.SyncMetadata{}, notifyFailed, TransportRecord{TransportID: "T1"}, cause)
+	if len(srv.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(srv.requests))
+	}
+	if body := string(srv.requests[0].Body); strings.Contains(body, secret) || !strings.Contains(body, "invalid_client") {
+		t.Errorf("payload not redacted: %s", body)
+	}
+}